@@ -251,6 +251,9 @@ fn inspect_ast(ast: &DplyrNode) {
                     libdplyr::DplyrOperation::Rename { renames, .. } => {
                         println!("     {}. Rename: {} renames", i + 1, renames.len());
                     }
+                    libdplyr::DplyrOperation::RenameWith { .. } => {
+                        println!("     {}. RenameWith", i + 1);
+                    }
                     libdplyr::DplyrOperation::Arrange { columns, .. } => {
                         println!("     {}. Arrange: {} columns", i + 1, columns.len());
                     }
@@ -297,6 +300,44 @@ fn inspect_ast(ast: &DplyrNode) {
                             right_table
                         );
                     }
+                    libdplyr::DplyrOperation::BindRows { .. } => {
+                        println!("     {}. BindRows: UNION ALL", i + 1);
+                    }
+                    libdplyr::DplyrOperation::BindCols { .. } => {
+                        println!("     {}. BindCols: positional join", i + 1);
+                    }
+                    libdplyr::DplyrOperation::TopN { n, .. } => {
+                        println!("     {}. {}: {} rows", i + 1, op.operation_name(), n);
+                    }
+                    libdplyr::DplyrOperation::Relocate { columns, .. } => {
+                        println!("     {}. Relocate: {} columns", i + 1, columns.len());
+                    }
+                    libdplyr::DplyrOperation::Fill { columns, .. } => {
+                        println!("     {}. Fill: {}", i + 1, columns.join(", "));
+                    }
+                    libdplyr::DplyrOperation::Distinct { columns, .. } => {
+                        println!("     {}. Distinct: {}", i + 1, columns.join(", "));
+                    }
+                    libdplyr::DplyrOperation::Count { columns, .. } => {
+                        println!("     {}. Count: {}", i + 1, columns.join(", "));
+                    }
+                    libdplyr::DplyrOperation::Subquery { .. } => {
+                        println!("     {}. Subquery: nested pipeline", i + 1);
+                    }
+                    libdplyr::DplyrOperation::Tribble { columns, rows, .. } => {
+                        println!(
+                            "     {}. Tribble: {} columns, {} rows",
+                            i + 1,
+                            columns.len(),
+                            rows.len()
+                        );
+                    }
+                    libdplyr::DplyrOperation::SliceSample { size, .. } => {
+                        println!("     {}. SliceSample: {:?}", i + 1, size);
+                    }
+                    libdplyr::DplyrOperation::Slice { offset, limit, .. } => {
+                        println!("     {}. Slice: offset {offset}, limit {limit:?}", i + 1);
+                    }
                 }
             }
         }
@@ -37,6 +37,11 @@ pub fn run_cli() -> i32 {
                 return pipeline.handle_error(&error);
             }
 
+            // A directory run reports failing files and carries on
+            if pipeline.failed_files() > 0 {
+                return ExitCode::TRANSPILATION_ERROR;
+            }
+
             // Success
             ExitCode::SUCCESS
         }
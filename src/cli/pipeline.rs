@@ -10,11 +10,12 @@ use crate::cli::{
     StdinReader, TranspileMetadata, ValidateResult, ValidationConfig,
 };
 use crate::{
-    DuckDbDialect, MySqlDialect, PipeSyntax, PostgreSqlDialect, SqlDialect, SqliteDialect,
-    TranspileError, Transpiler,
+    dialect_pragma, DuckDbDialect, MySqlDialect, PipeSyntax, PostgreSqlDialect, SqlDialect,
+    SqlServerDialect, SqliteDialect, TranspileError, Transpiler,
 };
 use clap::{value_parser, Arg, ArgMatches, Command};
 use std::io::{self, Write};
+use std::path::{Path, PathBuf};
 
 const DIALECT_ENV_VAR: &str = "DPLYR_DIALECT";
 
@@ -23,7 +24,11 @@ const DIALECT_ENV_VAR: &str = "DPLYR_DIALECT";
 pub struct CliArgs {
     pub input_file: Option<String>,
     pub output_file: Option<String>,
+    pub input_dir: Option<String>,
     pub dialect: SqlDialectType,
+    /// Whether `dialect` came from `--dialect`; otherwise a `# dialect:`
+    /// pragma on the first line of the input takes precedence
+    pub dialect_explicit: bool,
     pub pretty_print: bool,
     pub input_text: Option<String>,
     pub validate_only: bool,
@@ -40,6 +45,7 @@ pub enum SqlDialectType {
     MySql,
     Sqlite,
     DuckDb,
+    SqlServer,
 }
 
 impl std::fmt::Display for SqlDialectType {
@@ -49,21 +55,39 @@ impl std::fmt::Display for SqlDialectType {
             Self::MySql => write!(f, "mysql"),
             Self::Sqlite => write!(f, "sqlite"),
             Self::DuckDb => write!(f, "duckdb"),
+            Self::SqlServer => write!(f, "sqlserver"),
         }
     }
 }
 
+/// Accepted dialect names (lowercase), including friendly aliases, for
+/// `--dialect`, `DPLYR_DIALECT` and the `# dialect:` pragma.
+const DIALECT_ALIASES: &[(&str, SqlDialectType)] = &[
+    ("postgresql", SqlDialectType::PostgreSql),
+    ("postgres", SqlDialectType::PostgreSql),
+    ("pg", SqlDialectType::PostgreSql),
+    ("mysql", SqlDialectType::MySql),
+    ("mariadb", SqlDialectType::MySql),
+    ("maria", SqlDialectType::MySql),
+    ("sqlite", SqlDialectType::Sqlite),
+    ("sqlite3", SqlDialectType::Sqlite),
+    ("duckdb", SqlDialectType::DuckDb),
+    ("duck", SqlDialectType::DuckDb),
+    ("sqlserver", SqlDialectType::SqlServer),
+    ("mssql", SqlDialectType::SqlServer),
+    ("tsql", SqlDialectType::SqlServer),
+];
+
 impl std::str::FromStr for SqlDialectType {
     type Err = String;
 
     fn from_str(s: &str) -> Result<Self, Self::Err> {
-        match s.to_lowercase().as_str() {
-            "postgresql" | "postgres" | "pg" => Ok(Self::PostgreSql),
-            "mysql" => Ok(Self::MySql),
-            "sqlite" => Ok(Self::Sqlite),
-            "duckdb" | "duck" => Ok(Self::DuckDb),
-            _ => Err(format!("Unsupported SQL dialect: {s}")),
-        }
+        let name = s.trim().to_lowercase();
+        DIALECT_ALIASES
+            .iter()
+            .find(|(alias, _)| *alias == name)
+            .map(|(_, dialect)| dialect.clone())
+            .ok_or_else(|| format!("Unsupported SQL dialect: {s}"))
     }
 }
 
@@ -74,10 +98,11 @@ pub fn parse_args() -> CliArgs {
         .author("libdplyr contributors")
         .about("A transpiler that converts R dplyr syntax to SQL")
         .long_about("libdplyr is a Rust-based transpiler that converts R dplyr syntax to SQL queries.\n\
-                     It supports multiple SQL dialects including PostgreSQL, MySQL, SQLite, and DuckDB.\n\n\
+                     It supports multiple SQL dialects including PostgreSQL, MySQL, SQLite, DuckDB and SQL Server.\n\n\
                      Examples:\n  \
                      libdplyr -t \"data %>% select(name, age) %>% filter(age > 18)\"\n  \
                      libdplyr -i input.R -o output.sql -d mysql -p\n  \
+                     libdplyr --dir queries/ -d duckdb\n  \
                      echo \"data %>% select(*)\" | libdplyr -d sqlite")
         .arg(
             Arg::new("input")
@@ -88,6 +113,16 @@ pub fn parse_args() -> CliArgs {
                 .long_help("Read dplyr code from the specified file. Cannot be used with -t/--text option.")
                 .conflicts_with("text"),
         )
+        .arg(
+            Arg::new("dir")
+                .long("dir")
+                .value_name("DIR")
+                .help("Transpile every .R/.dplyr file in a directory")
+                .long_help("Transpile every *.R and *.dplyr file under the directory (recursively) into a .sql file next to it, \
+                           or under the -o/--output directory. Failing files are reported and skipped; the exit code is \
+                           non-zero when any file failed.")
+                .conflicts_with_all(["input", "text"]),
+        )
         .arg(
             Arg::new("output")
                 .short('o')
@@ -101,14 +136,16 @@ pub fn parse_args() -> CliArgs {
                 .short('d')
                 .long("dialect")
                 .value_name("DIALECT")
-                .help("Target SQL dialect [possible values: postgresql, mysql, sqlite, duckdb]")
+                .help("Target SQL dialect [possible values: postgresql, mysql, sqlite, duckdb, sqlserver]")
                 .long_help("Specify the target SQL dialect for code generation.\n\
                            Supported dialects:\n  \
                            postgresql, postgres, pg - PostgreSQL\n  \
-                           mysql - MySQL\n  \
-                           sqlite - SQLite\n  \
-                           duckdb, duck - DuckDB\n\n\
-                           If omitted, the CLI reads DPLYR_DIALECT and falls back to postgresql.")
+                           mysql, mariadb, maria - MySQL\n  \
+                           sqlite, sqlite3 - SQLite\n  \
+                           duckdb, duck - DuckDB\n  \
+                           sqlserver, mssql, tsql - SQL Server (T-SQL)\n\n\
+                           If omitted, a `# dialect: <name>` comment on the first line of the input selects the dialect;\n\
+                           otherwise the CLI reads DPLYR_DIALECT and falls back to postgresql.")
                 .value_parser(value_parser!(SqlDialectType))
         )
         .arg(
@@ -177,10 +214,12 @@ fn parse_matches(matches: &ArgMatches) -> CliArgs {
     CliArgs {
         input_file: matches.get_one::<String>("input").cloned(),
         output_file: matches.get_one::<String>("output").cloned(),
+        input_dir: matches.get_one::<String>("dir").cloned(),
         dialect: matches
             .get_one::<SqlDialectType>("dialect")
             .cloned()
             .unwrap_or_else(dialect_from_env_or_default),
+        dialect_explicit: matches.get_one::<SqlDialectType>("dialect").is_some(),
         pretty_print: matches.get_flag("pretty"),
         input_text: matches.get_one::<String>("text").cloned(),
         validate_only: matches.get_flag("validate-only"),
@@ -212,6 +251,7 @@ fn create_dialect(dialect_type: &SqlDialectType) -> Box<dyn SqlDialect> {
         SqlDialectType::MySql => Box::new(MySqlDialect::new()),
         SqlDialectType::Sqlite => Box::new(SqliteDialect::new()),
         SqlDialectType::DuckDb => Box::new(DuckDbDialect::new()),
+        SqlDialectType::SqlServer => Box::new(SqlServerDialect::new()),
     }
 }
 
@@ -233,6 +273,12 @@ pub enum CliMode {
         validate_only: bool,
         streaming: bool,
     },
+    /// Batch mode over the dplyr files of a directory
+    DirectoryMode {
+        input_dir: String,
+        /// Directory receiving the .sql files (next to the inputs if unset)
+        output_dir: Option<String>,
+    },
 }
 
 /// CLI configuration derived from command-line arguments
@@ -240,6 +286,8 @@ pub enum CliMode {
 pub struct CliConfig {
     pub mode: CliMode,
     pub dialect: SqlDialectType,
+    /// Ignore `# dialect:` pragmas in the input (set by `--dialect`)
+    pub dialect_explicit: bool,
     pub pipe_syntax: PipeSyntax,
     pub output_format: OutputFormat,
     pub validation_only: bool,
@@ -256,6 +304,7 @@ impl CliConfig {
         Self {
             mode,
             dialect: args.dialect.clone(),
+            dialect_explicit: args.dialect_explicit,
             pipe_syntax: PipeSyntax::default(),
             output_format,
             validation_only: args.validate_only,
@@ -266,6 +315,12 @@ impl CliConfig {
 
     /// Determine the CLI mode based on arguments
     fn determine_mode(args: &CliArgs) -> CliMode {
+        if let Some(input_dir) = &args.input_dir {
+            return CliMode::DirectoryMode {
+                input_dir: input_dir.clone(),
+                output_dir: args.output_file.clone(),
+            };
+        }
         args.input_text.as_ref().map_or_else(
             || {
                 args.input_file.as_ref().map_or(
@@ -311,6 +366,7 @@ pub struct ProcessingPipeline {
     debug_logger: DebugLogger,
     signal_handler: Option<SignalHandler>,
     signal_processor: Option<SignalAwareProcessor>,
+    failed_files: usize,
 }
 
 impl ProcessingPipeline {
@@ -368,6 +424,7 @@ impl ProcessingPipeline {
             debug_logger,
             signal_handler,
             signal_processor,
+            failed_files: 0,
         })
     }
 
@@ -376,6 +433,18 @@ impl ProcessingPipeline {
         self.debug_logger.verbose("Starting processing pipeline");
         self.debug_logger.reset_step_timer();
 
+        if let CliMode::DirectoryMode {
+            input_dir,
+            output_dir,
+        } = &self.config.mode
+        {
+            let input_dir = PathBuf::from(input_dir);
+            let output_dir = output_dir.as_ref().map(PathBuf::from);
+            let result = self.process_directory(&input_dir, output_dir.as_deref());
+            self.debug_logger.total_time();
+            return result;
+        }
+
         let input = self.read_input()?;
         self.debug_logger.timing("Input reading");
 
@@ -449,9 +518,82 @@ impl ProcessingPipeline {
                     .debug(&format!("Read {} bytes from file", result.len()));
                 Ok(result)
             }
+            CliMode::DirectoryMode { input_dir, .. } => Err(TranspileError::ConfigurationError(
+                format!("'{input_dir}' is a directory; its files are read one by one"),
+            )),
         }
     }
 
+    /// Transpiles (or validates) every dplyr file under `input_dir` and
+    /// returns a per-file report. A failing file is reported and skipped;
+    /// `failed_files()` tells how many failed.
+    fn process_directory(
+        &mut self,
+        input_dir: &Path,
+        output_dir: Option<&Path>,
+    ) -> Result<String, TranspileError> {
+        let mut files = Vec::new();
+        collect_dplyr_files(input_dir, &mut files)?;
+        self.debug_logger.verbose(&format!(
+            "Found {} dplyr files in {}",
+            files.len(),
+            input_dir.display()
+        ));
+
+        let mut report = String::new();
+        for file in &files {
+            let target = sql_path_for(file, input_dir, output_dir);
+            match self.process_file(file, &target) {
+                Ok(()) if self.config.validation_only => {
+                    report.push_str(&format!("ok    {}\n", file.display()));
+                }
+                Ok(()) => {
+                    report.push_str(&format!(
+                        "ok    {} -> {}\n",
+                        file.display(),
+                        target.display()
+                    ));
+                }
+                Err(error) => {
+                    self.failed_files += 1;
+                    report.push_str(&format!("error {}: {error}\n", file.display()));
+                }
+            }
+        }
+        report.push_str(&format!(
+            "{} of {} files succeeded\n",
+            files.len() - self.failed_files,
+            files.len()
+        ));
+        Ok(report)
+    }
+
+    /// Processes one file of a directory run, writing its SQL to `target`.
+    fn process_file(&mut self, file: &Path, target: &Path) -> Result<(), TranspileError> {
+        let input = std::fs::read_to_string(file).map_err(|e| {
+            TranspileError::IoError(format!("Failed to read file '{}': {e}", file.display()))
+        })?;
+        if self.config.validation_only {
+            return self.validate_input(&input).map(|_| ());
+        }
+
+        let output = self.transpile_input(&input)?;
+        if let Some(parent) = target.parent() {
+            std::fs::create_dir_all(parent).map_err(|e| {
+                TranspileError::IoError(format!(
+                    "Failed to create directory '{}': {e}",
+                    parent.display()
+                ))
+            })?;
+        }
+        std::fs::write(target, output).map_err(|e| {
+            TranspileError::IoError(format!(
+                "Failed to write to file '{}': {e}",
+                target.display()
+            ))
+        })
+    }
+
     /// Validate input without transpilation
     fn validate_input(&self, input: &str) -> Result<String, TranspileError> {
         if let Some(ref validator) = self.validator {
@@ -507,18 +649,39 @@ impl ProcessingPipeline {
         }
     }
 
+    /// Returns the dialect for `input`: the one named by its `# dialect:`
+    /// pragma, unless `--dialect` was given, or the configured one.
+    fn input_dialect(&self, input: &str) -> Result<SqlDialectType, TranspileError> {
+        if self.config.dialect_explicit {
+            return Ok(self.config.dialect.clone());
+        }
+        match dialect_pragma(input) {
+            Some(name) => name.parse().map_err(|message| {
+                TranspileError::ConfigurationError(format!("Invalid dialect pragma: {message}"))
+            }),
+            None => Ok(self.config.dialect.clone()),
+        }
+    }
+
     /// Transpile input to SQL
     fn transpile_input(&mut self, input: &str) -> Result<String, TranspileError> {
-        self.debug_logger.verbose(&format!(
-            "Transpiling dplyr to SQL (dialect: {})...",
-            self.config.dialect
-        ));
+        let dialect = self.input_dialect(input)?;
+        let pragma_transpiler;
+        let transpiler = if dialect == self.config.dialect {
+            &self.transpiler
+        } else {
+            pragma_transpiler =
+                Transpiler::with_pipe_syntax(create_dialect(&dialect), self.config.pipe_syntax);
+            &pragma_transpiler
+        };
+        self.debug_logger
+            .verbose(&format!("Transpiling dplyr to SQL (dialect: {dialect})..."));
         self.debug_logger
             .debug(&format!("Input to transpile: {}", input.trim()));
 
         // Parse dplyr code to AST
         self.debug_logger.debug("Starting lexical analysis...");
-        let ast = self.transpiler.parse_dplyr(input)?;
+        let ast = transpiler.parse_dplyr(input)?;
         self.debug_logger.timing("Parsing");
 
         // Log AST structure if debug mode is enabled
@@ -526,18 +689,18 @@ impl ProcessingPipeline {
 
         // Generate SQL from AST
         self.debug_logger.debug("Starting SQL generation...");
-        let sql = self.transpiler.generate_sql(&ast)?;
+        let sql = transpiler.generate_sql(&ast)?;
         self.debug_logger.timing("SQL generation");
 
         self.debug_logger
-            .log_sql_generation(&sql, &self.config.dialect.to_string());
+            .log_sql_generation(&sql, &dialect.to_string());
         self.debug_logger
             .verbose("Transpilation completed successfully");
 
         match self.config.output_format {
             OutputFormat::Json => {
                 let metadata = TranspileMetadata::transpilation_success(
-                    &self.config.dialect,
+                    &dialect,
                     self.debug_logger.elapsed(),
                     input,
                     &sql,
@@ -672,6 +835,55 @@ impl ProcessingPipeline {
     pub const fn config(&self) -> &CliConfig {
         &self.config
     }
+
+    /// Number of files that failed in a directory run
+    pub const fn failed_files(&self) -> usize {
+        self.failed_files
+    }
+}
+
+/// Extensions of the files picked up by a directory run.
+const DPLYR_FILE_EXTENSIONS: &[&str] = &["R", "r", "dplyr"];
+
+/// Collects the dplyr files under `dir` recursively, in path order.
+/// Symlinked directories are not followed, so a link back to an enclosing
+/// directory cannot recurse forever.
+fn collect_dplyr_files(dir: &Path, files: &mut Vec<PathBuf>) -> Result<(), TranspileError> {
+    let entries = std::fs::read_dir(dir).map_err(|e| {
+        TranspileError::IoError(format!("Failed to read directory '{}': {e}", dir.display()))
+    })?;
+    let mut paths = entries
+        .map(|entry| entry.and_then(|entry| Ok((entry.path(), entry.file_type()?))))
+        .collect::<Result<Vec<_>, _>>()
+        .map_err(|e| {
+            TranspileError::IoError(format!("Failed to read directory '{}': {e}", dir.display()))
+        })?;
+    paths.sort_by(|(left, _), (right, _)| left.cmp(right));
+
+    for (path, file_type) in paths {
+        if file_type.is_dir() {
+            collect_dplyr_files(&path, files)?;
+        } else if file_type.is_symlink() && path.is_dir() {
+            continue;
+        } else if path
+            .extension()
+            .and_then(|extension| extension.to_str())
+            .is_some_and(|extension| DPLYR_FILE_EXTENSIONS.contains(&extension))
+        {
+            files.push(path);
+        }
+    }
+    Ok(())
+}
+
+/// Returns the .sql path for `file`: next to it, or at the same relative
+/// location under `output_dir`.
+fn sql_path_for(file: &Path, input_dir: &Path, output_dir: Option<&Path>) -> PathBuf {
+    let target = match output_dir {
+        Some(output_dir) => output_dir.join(file.strip_prefix(input_dir).unwrap_or(file)),
+        None => file.to_path_buf(),
+    };
+    target.with_extension("sql")
 }
 
 #[cfg(test)]
@@ -683,7 +895,9 @@ mod tests {
         CliArgs {
             input_file: None,
             output_file: None,
+            input_dir: None,
             dialect: SqlDialectType::PostgreSql,
+            dialect_explicit: false,
             pretty_print: false,
             input_text: None,
             validate_only: false,
@@ -782,4 +996,95 @@ mod tests {
         let pipeline = ProcessingPipeline::new(config).unwrap();
         assert!(pipeline.validator.is_some());
     }
+
+    #[test]
+    fn test_directory_mode_transpiles_each_file() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("adults.R"), "users %>% filter(age > 18)").unwrap();
+        std::fs::create_dir(dir.path().join("nested")).unwrap();
+        std::fs::write(dir.path().join("nested/broken.dplyr"), "users %>% filter(").unwrap();
+        std::fs::write(dir.path().join("notes.txt"), "not dplyr").unwrap();
+
+        let mut args = create_test_args();
+        args.input_dir = Some(dir.path().to_string_lossy().into_owned());
+        let config = CliConfig::from_args(&args);
+        assert!(matches!(config.mode, CliMode::DirectoryMode { .. }));
+
+        let mut pipeline = ProcessingPipeline::new(config).unwrap();
+        let report = pipeline.process().unwrap();
+
+        // 실패한 파일은 보고하고 나머지는 계속 처리
+        let sql = std::fs::read_to_string(dir.path().join("adults.sql")).unwrap();
+        assert!(sql.contains("WHERE (\"age\" > 18)"), "{sql}");
+        assert!(!dir.path().join("nested/broken.sql").exists());
+        assert!(!dir.path().join("notes.sql").exists());
+        assert_eq!(pipeline.failed_files(), 1);
+        assert!(report.contains("adults.R -> "), "{report}");
+        assert!(report.contains("error "), "{report}");
+        assert!(report.ends_with("1 of 2 files succeeded\n"), "{report}");
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn test_directory_mode_skips_symlinked_directories() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("adults.R"), "users %>% filter(age > 18)").unwrap();
+        std::fs::create_dir(dir.path().join("nested")).unwrap();
+        // 상위 디렉터리를 가리키는 링크가 무한 재귀를 만들지 않아야 함
+        std::os::unix::fs::symlink(dir.path(), dir.path().join("nested/loop")).unwrap();
+        // 파일을 가리키는 링크는 그대로 처리
+        std::os::unix::fs::symlink(dir.path().join("adults.R"), dir.path().join("linked.R"))
+            .unwrap();
+
+        let mut files = Vec::new();
+        collect_dplyr_files(dir.path(), &mut files).unwrap();
+        assert_eq!(
+            files,
+            [dir.path().join("adults.R"), dir.path().join("linked.R")]
+        );
+    }
+
+    #[test]
+    fn test_dialect_aliases_resolve_to_dialects() {
+        for (name, expected) in [
+            ("pg", SqlDialectType::PostgreSql),
+            ("PostgreSQL", SqlDialectType::PostgreSql),
+            ("maria", SqlDialectType::MySql),
+            ("MariaDB", SqlDialectType::MySql),
+            ("mssql", SqlDialectType::SqlServer),
+            ("sqlserver", SqlDialectType::SqlServer),
+            (" duck ", SqlDialectType::DuckDb),
+            ("sqlite3", SqlDialectType::Sqlite),
+        ] {
+            assert_eq!(name.parse::<SqlDialectType>(), Ok(expected), "{name}");
+        }
+        // 지원하지 않는 엔진은 별칭으로도 받지 않음
+        assert!("presto".parse::<SqlDialectType>().is_err());
+    }
+
+    #[test]
+    fn test_dialect_pragma_selects_postgres() {
+        let mut args = create_test_args();
+        args.dialect = SqlDialectType::MySql;
+        args.input_text = Some("# dialect: postgres\nusers %>% select(name)".to_string());
+
+        let mut pipeline = ProcessingPipeline::new(CliConfig::from_args(&args)).unwrap();
+        let sql = pipeline.process().unwrap();
+        assert!(sql.contains("SELECT \"name\""), "{sql}");
+
+        // --dialect로 지정한 방언이 pragma보다 우선
+        args.dialect_explicit = true;
+        let mut pipeline = ProcessingPipeline::new(CliConfig::from_args(&args)).unwrap();
+        let sql = pipeline.process().unwrap();
+        assert!(sql.contains("SELECT `name`"), "{sql}");
+
+        // 알 수 없는 방언 이름은 설정 오류
+        args.dialect_explicit = false;
+        args.input_text = Some("# dialect: oracle\nusers %>% select(name)".to_string());
+        let mut pipeline = ProcessingPipeline::new(CliConfig::from_args(&args)).unwrap();
+        assert!(matches!(
+            pipeline.process(),
+            Err(TranspileError::ConfigurationError(_))
+        ));
+    }
 }
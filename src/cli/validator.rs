@@ -316,6 +316,10 @@ impl DplyrValidator {
                 }
                 *complexity_score += 1;
             }
+            DplyrOperation::RenameWith { .. } => {
+                operations.push("rename_with".to_string());
+                *complexity_score += 1;
+            }
             DplyrOperation::Arrange { columns: cols, .. } => {
                 operations.push("arrange".to_string());
                 for col in cols {
@@ -331,9 +335,15 @@ impl DplyrValidator {
                 }
                 *complexity_score += 2;
             }
-            DplyrOperation::Summarise { aggregations, .. } => {
+            DplyrOperation::Summarise {
+                aggregations, by, ..
+            } => {
                 operations.push("summarise".to_string());
                 *has_aggregation = true;
+                if !by.is_empty() {
+                    *has_grouping = true;
+                    columns.extend(by.iter().cloned());
+                }
                 for agg in aggregations {
                     // Add the column being aggregated
                     columns.insert(agg.column.clone());
@@ -356,6 +366,69 @@ impl DplyrValidator {
                 });
                 *complexity_score += 2;
             }
+            DplyrOperation::BindRows { .. } => {
+                operations.push("bind_rows".to_string());
+                *complexity_score += 2;
+            }
+            DplyrOperation::BindCols { .. } => {
+                operations.push("bind_cols".to_string());
+                *complexity_score += 3;
+            }
+            DplyrOperation::TopN { order_by, .. } => {
+                operations.push(operation.operation_name().to_string());
+                for order in order_by {
+                    columns.insert(order.column.clone());
+                }
+                *complexity_score += 2;
+            }
+            DplyrOperation::Fill { columns: cols, .. } => {
+                operations.push("fill".to_string());
+                columns.extend(cols.iter().cloned());
+                *complexity_score += 3;
+            }
+            DplyrOperation::Distinct { columns: cols, .. } => {
+                operations.push("distinct".to_string());
+                columns.extend(cols.iter().cloned());
+                *complexity_score += 1;
+            }
+            DplyrOperation::Count {
+                columns: cols,
+                name,
+                ..
+            } => {
+                operations.push("count".to_string());
+                *has_grouping = true;
+                *has_aggregation = true;
+                columns.extend(cols.iter().cloned());
+                columns.insert(name.clone());
+                *complexity_score += 3;
+            }
+            DplyrOperation::Subquery { .. } => {
+                operations.push("subquery".to_string());
+                *complexity_score += 2;
+            }
+            DplyrOperation::Tribble { columns: cols, .. } => {
+                operations.push("tribble".to_string());
+                columns.extend(cols.iter().cloned());
+                *complexity_score += 1;
+            }
+            DplyrOperation::SliceSample { .. } => {
+                operations.push("slice_sample".to_string());
+                *complexity_score += 2;
+            }
+            DplyrOperation::Slice { .. } => {
+                operations.push("slice".to_string());
+                *complexity_score += 1;
+            }
+            DplyrOperation::Relocate { columns: cols, .. } => {
+                operations.push("relocate".to_string());
+                for col in cols {
+                    if let crate::parser::Expr::Identifier(name) = col {
+                        columns.insert(name.clone());
+                    }
+                }
+                *complexity_score += 1;
+            }
         }
     }
 
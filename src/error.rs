@@ -114,11 +114,23 @@ pub enum GenerationError {
     #[error("Maximum nesting depth exceeded: {depth} (max: {max_depth})")]
     MaxNestingDepthExceeded { depth: usize, max_depth: usize },
 
+    #[error("Pipeline too long: {steps} steps (max: {max_steps})")]
+    MaxPipelineDepthExceeded { steps: usize, max_steps: usize },
+
     #[error("Empty query: no SQL to generate")]
     EmptyQuery,
 
     #[error("Invalid identifier: '{identifier}' - {reason}")]
     InvalidIdentifier { identifier: String, reason: String },
+
+    #[error("Schema required for '{operation}': columns of table '{table}' are unknown")]
+    SchemaRequired { operation: String, table: String },
+
+    #[error("Column type required for '{operation}': type of column '{column}' is unknown")]
+    ColumnTypeRequired { operation: String, column: String },
+
+    #[error("Rejected in strict mode: '{operation}' {reason}")]
+    StrictModeViolation { operation: String, reason: String },
 }
 
 /// Unified error that can occur during the entire conversion process
@@ -27,6 +27,14 @@ lazy_static::lazy_static! {
         m.insert("intersect", Token::Intersect);
         m.insert("union", Token::Union);
         m.insert("setdiff", Token::SetDiff);
+        m.insert("bind_rows", Token::BindRows);
+        m.insert("bind_cols", Token::BindCols);
+        m.insert("slice_min", Token::SliceMin);
+        m.insert("slice_max", Token::SliceMax);
+        m.insert("relocate", Token::Relocate);
+        m.insert("top_n", Token::TopN);
+        m.insert("fill", Token::Fill);
+        m.insert("distinct", Token::Distinct);
         // R functions with dots (treated as identifiers)
         m.insert("is.na", Token::Identifier("is.na".to_string()));
         m.insert("as.numeric", Token::Identifier("as.numeric".to_string()));
@@ -45,6 +53,10 @@ lazy_static::lazy_static! {
         m.insert("NULL", Token::Null);
         m.insert("null", Token::Null);
         m.insert("NA", Token::Null);
+        // Typed missing values are all SQL NULL.
+        m.insert("NA_integer_", Token::Null);
+        m.insert("NA_real_", Token::Null);
+        m.insert("NA_character_", Token::Null);
         m
     };
 }
@@ -69,6 +81,14 @@ pub enum Token {
     Intersect,
     Union,
     SetDiff,
+    BindRows,
+    BindCols,
+    SliceMin,
+    SliceMax,
+    Relocate,
+    TopN,
+    Fill,
+    Distinct,
 
     // dplyr helper functions
     Desc, // desc()
@@ -81,6 +101,7 @@ pub enum Token {
     Assignment,         // =
     Equal,              // ==
     NotEqual,           // !=
+    Not,                // !
     LessThan,           // <
     LessThanOrEqual,    // <=
     GreaterThan,        // >
@@ -91,6 +112,14 @@ pub enum Token {
     Minus,              // -
     Multiply,           // *
     Divide,             // /
+    IntegerDivide,      // %/%
+    Modulo,             // %%
+    Caret,              // ^
+    Like,               // %like%
+    ILike,              // %ilike%
+    In,                 // %in%
+    Tilde,              // ~ (formula lambda)
+    Colon,              // : (integer range)
 
     // Literals
     Identifier(String),
@@ -133,6 +162,14 @@ impl std::fmt::Display for Token {
             Self::Intersect => write!(f, "intersect"),
             Self::Union => write!(f, "union"),
             Self::SetDiff => write!(f, "setdiff"),
+            Self::BindRows => write!(f, "bind_rows"),
+            Self::BindCols => write!(f, "bind_cols"),
+            Self::SliceMin => write!(f, "slice_min"),
+            Self::SliceMax => write!(f, "slice_max"),
+            Self::Relocate => write!(f, "relocate"),
+            Self::TopN => write!(f, "top_n"),
+            Self::Fill => write!(f, "fill"),
+            Self::Distinct => write!(f, "distinct"),
             Self::Desc => write!(f, "desc"),
             Self::Asc => write!(f, "asc"),
             Self::Pipe => write!(f, "%>%"),
@@ -141,6 +178,7 @@ impl std::fmt::Display for Token {
             Self::Assignment => write!(f, "="),
             Self::Equal => write!(f, "=="),
             Self::NotEqual => write!(f, "!="),
+            Self::Not => write!(f, "!"),
             Self::LessThan => write!(f, "<"),
             Self::LessThanOrEqual => write!(f, "<="),
             Self::GreaterThan => write!(f, ">"),
@@ -151,6 +189,14 @@ impl std::fmt::Display for Token {
             Self::Minus => write!(f, "-"),
             Self::Multiply => write!(f, "*"),
             Self::Divide => write!(f, "/"),
+            Self::IntegerDivide => write!(f, "%/%"),
+            Self::Modulo => write!(f, "%%"),
+            Self::Caret => write!(f, "^"),
+            Self::Like => write!(f, "%like%"),
+            Self::ILike => write!(f, "%ilike%"),
+            Self::In => write!(f, "%in%"),
+            Self::Tilde => write!(f, "~"),
+            Self::Colon => write!(f, ":"),
             Self::Identifier(name) => write!(f, "{name}"),
             Self::String(s) => write!(f, "\"{s}\""),
             Self::Number(n) => write!(f, "{n}"),
@@ -170,6 +216,44 @@ impl std::fmt::Display for Token {
     }
 }
 
+/// A `#` comment skipped while tokenizing.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SourceComment {
+    /// Comment text without the leading `#` and surrounding whitespace
+    pub text: String,
+    /// Line of the comment (1-based)
+    pub line: usize,
+    /// Character offset of the `#`
+    pub offset: usize,
+}
+
+impl SourceComment {
+    /// Returns the dialect named by a `# dialect: <name>` pragma, which only
+    /// counts on the first line of the input. The keyword is matched
+    /// case-insensitively (`# Dialect: postgres`).
+    pub fn dialect_pragma(&self) -> Option<&str> {
+        if self.line != 1 {
+            return None;
+        }
+        let (keyword, name) = self.text.split_once(':')?;
+        let name = name.trim();
+        (keyword.trim_end().eq_ignore_ascii_case("dialect") && !name.is_empty()).then_some(name)
+    }
+}
+
+/// Returns the dialect declared by a leading `# dialect: <name>` comment of
+/// `input`, e.g. `postgres` for `# dialect: postgres`.
+pub fn dialect_pragma(input: &str) -> Option<String> {
+    let mut lexer = Lexer::new(input.to_string());
+    // Lexing the first token records a comment on the first line.
+    let _ = lexer.next_token();
+    lexer
+        .comments()
+        .first()
+        .and_then(SourceComment::dialect_pragma)
+        .map(str::to_string)
+}
+
 /// Lexer struct
 ///
 /// Provides functionality to tokenize input strings.
@@ -178,6 +262,7 @@ pub struct Lexer {
     position: usize,
     current_char: Option<char>,
     pipe_syntax: PipeSyntax,
+    comments: Vec<SourceComment>,
 }
 
 impl Lexer {
@@ -200,9 +285,15 @@ impl Lexer {
             position: 0,
             current_char,
             pipe_syntax,
+            comments: Vec::new(),
         }
     }
 
+    /// Returns the comments skipped so far, in source order.
+    pub fn comments(&self) -> &[SourceComment] {
+        &self.comments
+    }
+
     /// Returns the pipe syntax this lexer recognizes.
     pub const fn pipe_syntax(&self) -> PipeSyntax {
         self.pipe_syntax
@@ -246,6 +337,9 @@ impl Lexer {
                         if let Some(next_char) = self.input.get(self.position + 1) {
                             if next_char.is_ascii_digit() {
                                 self.read_number()
+                            } else if next_char.is_ascii_alphabetic() || *next_char == '_' {
+                                // Dot-prefixed argument names such as .by_group
+                                self.read_identifier_or_keyword()
                             } else {
                                 self.advance();
                                 Ok(Token::Dot)
@@ -276,6 +370,10 @@ impl Lexer {
                         self.advance();
                         Ok(Token::Divide)
                     }
+                    '^' => {
+                        self.advance();
+                        Ok(Token::Caret)
+                    }
                     '\\' => {
                         self.advance();
                         Ok(Token::Backslash)
@@ -295,7 +393,7 @@ impl Lexer {
                             self.advance();
                             Ok(Token::NotEqual)
                         } else {
-                            Err(LexError::UnexpectedCharacter(ch, self.position))
+                            Ok(Token::Not)
                         }
                     }
                     '<' => {
@@ -331,10 +429,22 @@ impl Lexer {
                         Ok(Token::Or)
                     }
                     '%' => {
-                        // Handle pipe operator %>%
+                        // Handle pipe operator %>%, %/%, %% and named infix operators (%like%)
                         self.read_pipe_operator()
                     }
+                    '~' => {
+                        self.advance();
+                        Ok(Token::Tilde)
+                    }
+                    ':' => {
+                        self.advance();
+                        Ok(Token::Colon)
+                    }
                     '"' | '\'' => self.read_string(),
+                    '#' => {
+                        self.skip_comment();
+                        self.next_token()
+                    }
                     '\n' => {
                         self.advance();
                         Ok(Token::Newline)
@@ -377,7 +487,40 @@ impl Lexer {
         }
     }
 
-    /// Reads the magrittr pipe operator %>%.
+    /// Skips a `#` comment up to (not including) the line break and records it.
+    fn skip_comment(&mut self) {
+        let offset = self.position;
+        let mut text = String::new();
+        self.advance(); // Skip '#'
+        while let Some(ch) = self.current_char {
+            if ch == '\n' {
+                break;
+            }
+            text.push(ch);
+            self.advance();
+        }
+
+        // peek_token() lexes ahead and rewinds; record each comment once.
+        if self
+            .comments
+            .last()
+            .is_some_and(|last| last.offset >= offset)
+        {
+            return;
+        }
+        let line = self.input[..offset]
+            .iter()
+            .filter(|&&ch| ch == '\n')
+            .count()
+            + 1;
+        self.comments.push(SourceComment {
+            text: text.trim_start_matches('#').trim().to_string(),
+            line,
+            offset,
+        });
+    }
+
+    /// Reads the magrittr pipe operator %>% and the `%%`/`%/%` arithmetic operators.
     fn read_pipe_operator(&mut self) -> LexResult<Token> {
         let start_position = self.position;
         let mut pipe_str = String::new();
@@ -386,6 +529,21 @@ impl Lexer {
         pipe_str.push('%');
         self.advance();
 
+        if self.current_char.is_some_and(|ch| ch.is_ascii_alphabetic()) {
+            return self.read_infix_operator(start_position);
+        }
+
+        if self.current_char == Some('%') {
+            self.advance();
+            return Ok(Token::Modulo);
+        }
+
+        if self.current_char == Some('/') && self.input.get(self.position + 1) == Some(&'%') {
+            self.advance();
+            self.advance();
+            return Ok(Token::IntegerDivide);
+        }
+
         if self.current_char == Some('>') {
             pipe_str.push('>');
             self.advance();
@@ -412,6 +570,34 @@ impl Lexer {
         }
     }
 
+    /// Reads a named infix operator such as `%like%` (the leading `%` is consumed).
+    fn read_infix_operator(&mut self, start_position: usize) -> LexResult<Token> {
+        let mut name = String::new();
+        while let Some(ch) = self.current_char {
+            if !ch.is_ascii_alphabetic() {
+                break;
+            }
+            name.push(ch);
+            self.advance();
+        }
+
+        let operator = format!("%{name}%");
+        if self.current_char != Some('%') {
+            return Err(LexError::InvalidPipeOperator(
+                format!("%{name}"),
+                start_position,
+            ));
+        }
+        self.advance();
+
+        match name.as_str() {
+            "like" => Ok(Token::Like),
+            "ilike" => Ok(Token::ILike),
+            "in" => Ok(Token::In),
+            _ => Err(LexError::InvalidPipeOperator(operator, start_position)),
+        }
+    }
+
     /// Reads the base R native pipe operator |>.
     fn read_native_pipe_operator(&mut self) -> LexResult<Token> {
         let start_position = self.position;
@@ -546,6 +732,10 @@ mod tests {
             assert_tokens("{}", vec![Token::LeftBrace, Token::RightBrace, Token::EOF]);
             assert_tokens(",", vec![Token::Comma, Token::EOF]);
             assert_tokens(".", vec![Token::Dot, Token::EOF]);
+            assert_tokens(
+                ".by_group",
+                vec![Token::Identifier(".by_group".to_string()), Token::EOF],
+            );
             assert_tokens(
                 "(){},.)",
                 vec![
@@ -585,6 +775,47 @@ mod tests {
             );
         }
 
+        #[test]
+        fn test_integer_division_and_modulo_operators() {
+            assert_tokens(
+                "a %/% b %% c",
+                vec![
+                    Token::Identifier("a".to_string()),
+                    Token::IntegerDivide,
+                    Token::Identifier("b".to_string()),
+                    Token::Modulo,
+                    Token::Identifier("c".to_string()),
+                    Token::EOF,
+                ],
+            );
+        }
+
+        #[test]
+        fn test_caret_operator() {
+            assert_tokens(
+                "x^2",
+                vec![
+                    Token::Identifier("x".to_string()),
+                    Token::Caret,
+                    Token::Number(2.0),
+                    Token::EOF,
+                ],
+            );
+        }
+
+        #[test]
+        fn test_in_operator() {
+            assert_tokens(
+                "x %in% y",
+                vec![
+                    Token::Identifier("x".to_string()),
+                    Token::In,
+                    Token::Identifier("y".to_string()),
+                    Token::EOF,
+                ],
+            );
+        }
+
         #[test]
         fn test_comparison_operators() {
             assert_tokens(
@@ -605,6 +836,16 @@ mod tests {
         #[test]
         fn test_logical_operators() {
             assert_tokens("& |", vec![Token::And, Token::Or, Token::EOF]);
+            assert_tokens(
+                "!x != y",
+                vec![
+                    Token::Not,
+                    Token::Identifier("x".to_string()),
+                    Token::NotEqual,
+                    Token::Identifier("y".to_string()),
+                    Token::EOF,
+                ],
+            );
         }
 
         #[test]
@@ -705,6 +946,19 @@ mod tests {
             );
         }
 
+        #[test]
+        fn test_string_literals_keep_edge_whitespace() {
+            assert_tokens(
+                "\"  x \"",
+                vec![Token::String("  x ".to_string()), Token::EOF],
+            );
+            assert_tokens(
+                "'a   b'",
+                vec![Token::String("a   b".to_string()), Token::EOF],
+            );
+            assert_tokens("\" \"", vec![Token::String(" ".to_string()), Token::EOF]);
+        }
+
         #[test]
         fn test_string_literals_mixed_quotes() {
             assert_tokens(
@@ -754,6 +1008,8 @@ mod tests {
             assert_tokens("NULL", vec![Token::Null, Token::EOF]);
             assert_tokens("null", vec![Token::Null, Token::EOF]);
             assert_tokens("NA", vec![Token::Null, Token::EOF]);
+            assert_tokens("NA_real_", vec![Token::Null, Token::EOF]);
+            assert_tokens("NA_character_", vec![Token::Null, Token::EOF]);
         }
     }
 
@@ -982,6 +1238,101 @@ mod tests {
             }
         }
 
+        #[test]
+        fn test_like_operators() {
+            assert_tokens(
+                "name %like% \"A%\" %ilike% x",
+                vec![
+                    Token::Identifier("name".to_string()),
+                    Token::Like,
+                    Token::String("A%".to_string()),
+                    Token::ILike,
+                    Token::Identifier("x".to_string()),
+                    Token::EOF,
+                ],
+            );
+
+            let mut lexer = Lexer::new("%unknown%".to_string());
+            match lexer.next_token() {
+                Err(LexError::InvalidPipeOperator(op, _)) => assert_eq!(op, "%unknown%"),
+                other => panic!("Expected InvalidPipeOperator error, got: {other:?}"),
+            }
+        }
+
+        #[test]
+        fn test_range_colon() {
+            assert_tokens(
+                "1:3",
+                vec![
+                    Token::Number(1.0),
+                    Token::Colon,
+                    Token::Number(3.0),
+                    Token::EOF,
+                ],
+            );
+        }
+
+        #[test]
+        fn test_comments_are_skipped_and_recorded() {
+            let mut lexer = Lexer::new("# pick columns\nselect(a) # trailing".to_string());
+            let mut tokens = Vec::new();
+            loop {
+                let token = lexer.next_token().unwrap();
+                let done = token == Token::EOF;
+                tokens.push(token);
+                if done {
+                    break;
+                }
+            }
+            assert_eq!(
+                tokens,
+                vec![
+                    Token::Newline,
+                    Token::Select,
+                    Token::LeftParen,
+                    Token::Identifier("a".to_string()),
+                    Token::RightParen,
+                    Token::EOF,
+                ]
+            );
+            let comments: Vec<_> = lexer
+                .comments()
+                .iter()
+                .map(|c| (c.text.as_str(), c.line))
+                .collect();
+            assert_eq!(comments, [("pick columns", 1), ("trailing", 2)]);
+        }
+
+        #[test]
+        fn test_dialect_pragma_on_first_line() {
+            assert_eq!(
+                dialect_pragma("# dialect: postgres\nt %>% select(a)").as_deref(),
+                Some("postgres")
+            );
+            // 키워드는 대소문자를 구분하지 않음
+            for input in ["# Dialect: duckdb", "# DIALECT : duckdb"] {
+                assert_eq!(dialect_pragma(input).as_deref(), Some("duckdb"), "{input}");
+            }
+            // 첫 줄이 아니거나 다른 주석이면 무시
+            assert_eq!(dialect_pragma("t %>% select(a)\n# dialect: mysql"), None);
+            assert_eq!(dialect_pragma("# dialects: mysql"), None);
+            assert_eq!(dialect_pragma("# pick columns\nt %>% select(a)"), None);
+        }
+
+        #[test]
+        fn test_formula_tilde() {
+            assert_tokens(
+                "~ .x * 2",
+                vec![
+                    Token::Tilde,
+                    Token::Identifier(".x".to_string()),
+                    Token::Multiply,
+                    Token::Number(2.0),
+                    Token::EOF,
+                ],
+            );
+        }
+
         #[test]
         fn test_invalid_pipe_operator_incomplete() {
             let mut lexer = Lexer::new("%>".to_string());
@@ -1035,7 +1386,7 @@ mod tests {
 
         #[test]
         fn test_unexpected_character_symbols() {
-            let test_cases = vec!['@', '#', '$', '^', '~', '`', '[', ']'];
+            let test_cases = vec!['@', '$', '`', '[', ']'];
 
             for ch in test_cases {
                 let mut lexer = Lexer::new(ch.to_string());
@@ -1059,15 +1410,6 @@ mod tests {
             }
         }
 
-        #[test]
-        fn test_exclamation_without_equals() {
-            let mut lexer = Lexer::new("!".to_string());
-            match lexer.next_token() {
-                Err(LexError::UnexpectedCharacter('!', _)) => {}
-                other => panic!("Expected UnexpectedCharacter error for '!', got: {other:?}"),
-            }
-        }
-
         #[test]
         fn test_error_position_tracking() {
             let mut lexer = Lexer::new("select @".to_string());
@@ -273,6 +273,7 @@
 
 pub mod error;
 pub mod lexer;
+pub mod options;
 pub mod parser;
 pub mod performance;
 pub mod pipe_syntax;
@@ -282,17 +283,20 @@ pub mod sql_generator;
 #[cfg(not(target_family = "wasm"))]
 pub mod cli;
 
+use std::collections::BTreeMap;
+
 // Re-export public API
 pub use crate::error::{GenerationError, LexError, ParseError, TranspileError};
-pub use crate::lexer::{Lexer, Token};
+pub use crate::lexer::{dialect_pragma, Lexer, SourceComment, Token};
+pub use crate::options::{NullsOrdering, Schema, SchemaColumn, TranspileOptions};
 pub use crate::parser::{DplyrNode, DplyrOperation, Parser};
 pub use crate::performance::{
     BatchPerformanceStats, PerformanceMetrics, PerformanceProfiler, RegressionDetector,
 };
 pub use crate::pipe_syntax::{PipeSyntax, PIPE_SYNTAX_ENV_VAR};
 pub use crate::sql_generator::{
-    DialectConfig, DuckDbDialect, MySqlDialect, PostgreSqlDialect, SqlDialect, SqlGenerator,
-    SqliteDialect,
+    ClauseFragments, DebugInfo, DialectConfig, DuckDbDialect, LintWarning, MySqlDialect,
+    PostgreSqlDialect, SqlDialect, SqlGenerator, SqlServerDialect, SqliteDialect,
 };
 
 /// Main transpiler struct for converting dplyr code to SQL
@@ -384,6 +388,29 @@ impl Transpiler {
         }
     }
 
+    /// Creates a new transpiler with explicit generation options.
+    ///
+    /// # Examples
+    ///
+    /// ```rust
+    /// use libdplyr::{Transpiler, TranspileOptions, Schema, PostgreSqlDialect};
+    ///
+    /// let options = TranspileOptions {
+    ///     no_select_star: true,
+    ///     schema: Some(Schema::new().with_table("users", ["id", "name"])),
+    ///     ..TranspileOptions::default()
+    /// };
+    /// let transpiler = Transpiler::with_options(Box::new(PostgreSqlDialect::new()), options);
+    /// let sql = transpiler.transpile("users %>% filter(id > 1)").unwrap();
+    /// assert!(sql.starts_with("SELECT \"id\", \"name\""));
+    /// ```
+    pub fn with_options(dialect: Box<dyn SqlDialect>, options: TranspileOptions) -> Self {
+        Self {
+            generator: SqlGenerator::with_options(dialect, options),
+            pipe_syntax: PipeSyntax::default(),
+        }
+    }
+
     /// Creates a new transpiler using `DPLYR_PIPE_SYNTAX`, defaulting to `%>%`.
     pub fn from_env(dialect: Box<dyn SqlDialect>) -> Result<Self, TranspileError> {
         let pipe_syntax =
@@ -431,6 +458,13 @@ impl Transpiler {
     /// "#).unwrap();
     /// ```
     pub fn transpile(&self, dplyr_code: &str) -> Result<String, TranspileError> {
+        if self.generator.options().preserve_comments {
+            let mut parser = self.parser(dplyr_code)?;
+            let ast = parser.parse()?;
+            return Ok(self
+                .generator
+                .generate_with_comments(&ast, parser.comments())?);
+        }
         let ast = self.parse_dplyr(dplyr_code)?;
         Ok(self.generate_sql(&ast)?)
     }
@@ -462,9 +496,13 @@ impl Transpiler {
     /// assert!(ast.is_pipeline());
     /// ```
     pub fn parse_dplyr(&self, code: &str) -> Result<DplyrNode, ParseError> {
+        self.parser(code)?.parse()
+    }
+
+    /// Creates a parser for `code` configured from the transpile options.
+    fn parser(&self, code: &str) -> Result<Parser, ParseError> {
         let lexer = Lexer::with_pipe_syntax(code.to_string(), self.pipe_syntax);
-        let mut parser = Parser::new(lexer)?;
-        parser.parse()
+        Ok(Parser::new(lexer)?.with_trailing_pipe(self.generator.options().allow_trailing_pipe))
     }
 
     /// Converts an AST to SQL using the configured dialect.
@@ -499,6 +537,114 @@ impl Transpiler {
     pub fn generate_sql(&self, ast: &DplyrNode) -> Result<String, GenerationError> {
         self.generator.generate(ast)
     }
+
+    /// Transpiles dplyr code and reports constructs that may not run on the
+    /// configured dialect.
+    ///
+    /// Errors that prevent transpilation are returned as `Err`; constructs
+    /// that transpile but are not portable (e.g. `FULL JOIN` on MySQL) are
+    /// returned as warnings with the position of their operation.
+    ///
+    /// # Examples
+    ///
+    /// ```rust
+    /// use libdplyr::{Transpiler, DuckDbDialect, MySqlDialect};
+    ///
+    /// let code = r#"orders %>% full_join(customers, by = "id")"#;
+    ///
+    /// let mysql = Transpiler::new(Box::new(MySqlDialect::new()));
+    /// assert_eq!(mysql.lint(code).unwrap().len(), 1);
+    ///
+    /// let duckdb = Transpiler::new(Box::new(DuckDbDialect::new()));
+    /// assert!(duckdb.lint(code).unwrap().is_empty());
+    /// ```
+    pub fn lint(&self, dplyr_code: &str) -> Result<Vec<LintWarning>, TranspileError> {
+        let ast = self.parse_dplyr(dplyr_code)?;
+        self.generate_sql(&ast)?;
+        Ok(self.generator.lint(&ast))
+    }
+
+    /// Transpiles dplyr code and returns the SQL together with the
+    /// intermediate representations it was built from: the parsed pipeline,
+    /// the source table, the grouping context and the rendered clauses.
+    ///
+    /// # Examples
+    ///
+    /// ```rust
+    /// use libdplyr::{Transpiler, PostgreSqlDialect};
+    ///
+    /// let transpiler = Transpiler::new(Box::new(PostgreSqlDialect::new()));
+    /// let (sql, info) = transpiler
+    ///     .transpile_debug("sales %>% group_by(region) %>% summarise(total = sum(amount))")
+    ///     .unwrap();
+    ///
+    /// assert!(sql.contains("GROUP BY"));
+    /// assert_eq!(info.source_table, "sales");
+    /// assert_eq!(info.clauses.group_by, "\"region\"");
+    /// ```
+    pub fn transpile_debug(&self, dplyr_code: &str) -> Result<(String, DebugInfo), TranspileError> {
+        let sql = self.transpile(dplyr_code)?;
+        let ast = self.parse_dplyr(dplyr_code)?;
+        let info = self.generator.debug_info(&ast)?;
+        Ok((sql, info))
+    }
+}
+
+/// SQL of one pipeline rendered for every dialect of [`builtin_dialects`],
+/// keyed by [`SqlDialect::dialect_name`] (`postgresql`, `sqlserver`, ...).
+#[derive(Debug, Default)]
+pub struct DialectOutputs {
+    /// SQL of the dialects that rendered the pipeline
+    pub sql: BTreeMap<&'static str, String>,
+    /// Errors of the dialects that cannot render the pipeline
+    pub errors: BTreeMap<&'static str, GenerationError>,
+}
+
+/// Returns an instance of every built-in SQL dialect.
+pub fn builtin_dialects() -> Vec<Box<dyn SqlDialect>> {
+    vec![
+        Box::new(PostgreSqlDialect::new()),
+        Box::new(MySqlDialect::new()),
+        Box::new(SqliteDialect::new()),
+        Box::new(DuckDbDialect::new()),
+        Box::new(SqlServerDialect::new()),
+    ]
+}
+
+/// Transpiles dplyr code for every dialect of [`builtin_dialects`], to
+/// compare how portable a pipeline is.
+///
+/// A dialect that cannot render a construct reports its error in
+/// [`DialectOutputs::errors`] without affecting the others; only lexing and
+/// parsing errors, which no dialect can get past, fail the whole call.
+///
+/// # Examples
+///
+/// ```rust
+/// use libdplyr::{transpile_all, TranspileOptions};
+///
+/// let outputs = transpile_all("t %>% bind_cols(u)", &TranspileOptions::default()).unwrap();
+/// assert!(outputs.sql.contains_key("duckdb"));
+/// assert!(outputs.errors.contains_key("postgresql"));
+/// ```
+pub fn transpile_all(
+    dplyr_code: &str,
+    options: &TranspileOptions,
+) -> Result<DialectOutputs, TranspileError> {
+    let mut outputs = DialectOutputs::default();
+    for dialect in builtin_dialects() {
+        let name = dialect.dialect_name();
+        match Transpiler::with_options(dialect, options.clone()).transpile(dplyr_code) {
+            Ok(sql) => {
+                outputs.sql.insert(name, sql);
+            }
+            Err(TranspileError::GenerationError(err)) => {
+                outputs.errors.insert(name, err);
+            }
+            Err(err) => return Err(err),
+        }
+    }
+    Ok(outputs)
 }
 
 #[cfg(test)]
@@ -526,6 +672,38 @@ mod tests {
         }
     }
 
+    #[test]
+    fn test_transpile_all_compares_dialects() {
+        let outputs = transpile_all(
+            "users %>% select(name, age) %>% filter(age > 18)",
+            &TranspileOptions::default(),
+        )
+        .unwrap();
+        assert!(outputs.errors.is_empty(), "{:?}", outputs.errors);
+        assert_eq!(
+            outputs.sql.keys().copied().collect::<Vec<_>>(),
+            ["duckdb", "mysql", "postgresql", "sqlite", "sqlserver"]
+        );
+        let expected = "SELECT \"name\", \"age\"\nFROM \"users\"\nWHERE (\"age\" > 18)";
+        for dialect in ["duckdb", "postgresql", "sqlite"] {
+            assert_eq!(outputs.sql[dialect], expected, "{dialect}");
+        }
+        assert_eq!(
+            outputs.sql["mysql"],
+            "SELECT `name`, `age`\nFROM `users`\nWHERE (`age` > 18)"
+        );
+        assert_eq!(
+            outputs.sql["sqlserver"],
+            "SELECT [name], [age]\nFROM [users]\nWHERE ([age] > 18)"
+        );
+
+        // 방언별 오류는 따로 모으고, 구문 오류는 호출 전체를 실패시킨다
+        let outputs = transpile_all("t %>% bind_cols(u)", &TranspileOptions::default()).unwrap();
+        assert_eq!(outputs.sql.keys().copied().collect::<Vec<_>>(), ["duckdb"]);
+        assert_eq!(outputs.errors.len(), 4);
+        assert!(transpile_all("select(", &TranspileOptions::default()).is_err());
+    }
+
     #[test]
     fn test_transpile_simple_select() {
         let transpiler = Transpiler::new(Box::new(PostgreSqlDialect::new()));
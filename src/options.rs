@@ -0,0 +1,257 @@
+//! Transpilation options.
+//!
+//! `TranspileOptions` carries opt-in behaviour switches for SQL generation.
+//! All switches default to the historical behaviour, so `TranspileOptions::default()`
+//! produces exactly the same SQL as a generator created without options.
+
+use std::collections::HashMap;
+
+/// Column metadata known for a table.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SchemaColumn {
+    /// Column name as it appears in the table
+    pub name: String,
+    /// SQL type name (e.g. `INTEGER`, `VARCHAR(20)`), when known
+    pub data_type: Option<String>,
+    /// Human-readable description (e.g. an R `labelled()` variable label),
+    /// emitted as a `--` comment with `emit_column_comments`
+    pub description: Option<String>,
+}
+
+impl SchemaColumn {
+    /// Creates a column definition with the given name.
+    pub fn new(name: impl Into<String>) -> Self {
+        Self {
+            name: name.into(),
+            data_type: None,
+            description: None,
+        }
+    }
+
+    /// Creates a column definition with a SQL type.
+    pub fn typed(name: impl Into<String>, data_type: impl Into<String>) -> Self {
+        Self {
+            name: name.into(),
+            data_type: Some(data_type.into()),
+            description: None,
+        }
+    }
+
+    /// Sets the description of the column and returns it.
+    pub fn with_description(mut self, description: impl Into<String>) -> Self {
+        self.description = Some(description.into());
+        self
+    }
+}
+
+/// Table schemas used to resolve column lists at generation time.
+///
+/// Columns keep their declaration order, which is the order used when a
+/// projection has to be expanded.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct Schema {
+    tables: HashMap<String, Vec<SchemaColumn>>,
+}
+
+impl Schema {
+    /// Creates an empty schema.
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Adds (or replaces) a table definition and returns the schema.
+    pub fn with_table<I, S>(mut self, table: impl Into<String>, columns: I) -> Self
+    where
+        I: IntoIterator<Item = S>,
+        S: Into<String>,
+    {
+        self.add_table(table, columns);
+        self
+    }
+
+    /// Adds (or replaces) a table definition.
+    pub fn add_table<I, S>(&mut self, table: impl Into<String>, columns: I)
+    where
+        I: IntoIterator<Item = S>,
+        S: Into<String>,
+    {
+        self.tables.insert(
+            table.into(),
+            columns.into_iter().map(SchemaColumn::new).collect(),
+        );
+    }
+
+    /// Adds (or replaces) a table definition with `(name, type)` columns and
+    /// returns the schema.
+    pub fn with_typed_table<I, N, T>(mut self, table: impl Into<String>, columns: I) -> Self
+    where
+        I: IntoIterator<Item = (N, T)>,
+        N: Into<String>,
+        T: Into<String>,
+    {
+        self.tables.insert(
+            table.into(),
+            columns
+                .into_iter()
+                .map(|(name, data_type)| SchemaColumn::typed(name, data_type))
+                .collect(),
+        );
+        self
+    }
+
+    /// Describes `column` of `table` and returns the schema; unknown columns
+    /// are left alone.
+    pub fn with_column_description(
+        mut self,
+        table: &str,
+        column: &str,
+        description: impl Into<String>,
+    ) -> Self {
+        if let Some(col) = self
+            .tables
+            .get_mut(table)
+            .and_then(|columns| columns.iter_mut().find(|col| col.name == column))
+        {
+            col.description = Some(description.into());
+        }
+        self
+    }
+
+    /// Returns the columns of `table`, if the table is known.
+    pub fn columns(&self, table: &str) -> Option<&[SchemaColumn]> {
+        self.tables.get(table).map(Vec::as_slice)
+    }
+
+    /// Returns the column names of `table` in declaration order, if the table is known.
+    pub fn column_names(&self, table: &str) -> Option<Vec<&str>> {
+        self.columns(table)
+            .map(|columns| columns.iter().map(|col| col.name.as_str()).collect())
+    }
+
+    /// Returns the definition of `column` in `table`, if known.
+    pub fn column(&self, table: &str, column: &str) -> Option<&SchemaColumn> {
+        self.columns(table)?.iter().find(|col| col.name == column)
+    }
+
+    /// Returns true when no tables are registered.
+    pub fn is_empty(&self) -> bool {
+        self.tables.is_empty()
+    }
+}
+
+/// Placement of NULLs in sort orders.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum NullsOrdering {
+    /// NULLs sort before all values
+    First,
+    /// NULLs sort after all values (dplyr's `arrange()` behaviour)
+    Last,
+}
+
+/// Options that change how dplyr pipelines are rendered to SQL.
+#[derive(Debug, Clone, Default)]
+pub struct TranspileOptions {
+    /// Never emit `SELECT *`: expand the star from `schema`, or fail when the
+    /// columns of the table are unknown.
+    pub no_select_star: bool,
+    /// Known table schemas (used for star expansion and column helpers).
+    pub schema: Option<Schema>,
+    /// Per-function SQL name overrides (dplyr name -> SQL name), consulted
+    /// before the dialect's built-in mapping, e.g. `mean` -> `AVERAGE`.
+    pub function_map: HashMap<String, String>,
+    /// Reject constructs that would otherwise be rendered on a best-effort
+    /// basis: functions outside the dialect's known mapping (unless listed in
+    /// `function_map`), `bind_cols()`, `filter()` after `summarise()` and
+    /// mutate() entries that use a column created in the same call (otherwise
+    /// rendered through a nested subquery). The built-in dialects already
+    /// reject unknown functions, so for them only the other checks change the
+    /// result; the function check matters for dialects that override
+    /// `SqlDialect::translate_unknown_function`.
+    pub strict_mode: bool,
+    /// Emit each verb as a named CTE (`step1`, `step2`, ...) and select from
+    /// the last one, so intermediate results can be inspected.
+    pub staged_cte: bool,
+    /// Emit `#` comments of the input as `--` comments above the clause of
+    /// the verb they precede.
+    pub preserve_comments: bool,
+    /// Render GROUP BY as positional references to the SELECT list
+    /// (`GROUP BY 1, 2`) instead of repeating the grouping columns.
+    pub group_by_ordinals: bool,
+    /// Reject pipelines with more than this many steps (operations,
+    /// including nested pipelines); `0` means unlimited. Guards services
+    /// against oversized inputs.
+    pub max_pipeline_depth: usize,
+    /// Prefix the SQL with the dialect's `EXPLAIN` keyword, so the output
+    /// returns the query plan.
+    pub explain: bool,
+    /// Use `EXPLAIN ANALYZE` (executes the query to report actual costs);
+    /// implies `explain`.
+    pub analyze: bool,
+    /// Render column aliases without the `AS` keyword (`SUM("x") "total"`).
+    /// Derived tables keep their `AS`.
+    pub implicit_alias: bool,
+    /// End the SQL with a newline, for consumers writing it to files or
+    /// streams directly.
+    pub trailing_newline: bool,
+    /// Collapse each run of whitespace inside string literals to a single
+    /// space. By default string contents are emitted exactly as written.
+    pub collapse_whitespace_in_strings: bool,
+    /// Run the AST optimization pass before generation: adjacent filters
+    /// are merged into one condition and chained selects narrowed to one.
+    pub optimize: bool,
+    /// Place NULLs first or last in every sort key (`NULLS LAST`, or an
+    /// `IS NULL` key on dialects without that syntax); `None` keeps the
+    /// engine's default placement.
+    pub nulls_ordering: Option<NullsOrdering>,
+    /// Time zone (e.g. `UTC`) that date/time functions such as `year()` and
+    /// `floor_date()` convert timestamps to before extracting or truncating;
+    /// `None` uses the values as stored.
+    pub time_zone: Option<String>,
+    /// Accept a pipe operator with no verb after it at the end of the input
+    /// (`data %>% select(a) %>%`), as left behind when editing pipelines
+    /// interactively; by default it is a parse error.
+    pub allow_trailing_pipe: bool,
+    /// Seed for `slice_sample()`, so every run draws the same rows: DuckDB
+    /// passes it to `USING SAMPLE`, MySQL to `RAND()`. Dialects without a
+    /// seeded sample report it as unsupported; `None` draws a fresh sample.
+    pub seed: Option<u64>,
+    /// Put each projected column on its own line, followed by a
+    /// `-- description` comment when `schema` describes it. Applies to
+    /// source columns projected as is or renamed.
+    pub emit_column_comments: bool,
+}
+
+impl TranspileOptions {
+    /// Creates the default option set.
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Returns the SQL name overriding the dplyr function `name`, if any.
+    pub fn function_override(&self, name: &str) -> Option<&str> {
+        self.function_map.get(name).map(String::as_str)
+    }
+
+    /// Returns the known column names for `table`, if a schema provides them.
+    pub fn table_columns(&self, table: &str) -> Option<Vec<&str>> {
+        self.schema.as_ref()?.column_names(table)
+    }
+
+    /// Returns the declared SQL type of `column` in `table`, if known.
+    pub fn column_type(&self, table: &str, column: &str) -> Option<&str> {
+        self.schema
+            .as_ref()?
+            .column(table, column)?
+            .data_type
+            .as_deref()
+    }
+
+    /// Returns the description of `column` in `table`, if known.
+    pub fn column_description(&self, table: &str, column: &str) -> Option<&str> {
+        self.schema
+            .as_ref()?
+            .column(table, column)?
+            .description
+            .as_deref()
+    }
+}
@@ -63,6 +63,23 @@ impl DplyrNode {
     pub const fn is_data_source(&self) -> bool {
         matches!(self, Self::DataSource { .. })
     }
+
+    /// Returns the number of operations, including those of nested pipelines
+    /// (`bind_rows()`/`bind_cols()` inputs and parenthesized sources).
+    pub fn step_count(&self) -> usize {
+        let Self::Pipeline { operations, .. } = self else {
+            return 0;
+        };
+        operations
+            .iter()
+            .map(|operation| match operation {
+                DplyrOperation::BindRows { source, .. }
+                | DplyrOperation::BindCols { source, .. }
+                | DplyrOperation::Subquery { source, .. } => 1 + source.step_count(),
+                _ => 1,
+            })
+            .sum()
+    }
 }
 
 /// dplyr operation types
@@ -81,6 +98,10 @@ pub enum DplyrOperation {
     /// Create/modify new columns
     Mutate {
         assignments: Vec<Assignment>,
+        /// Row frame of the window aggregates (`.frame = c(-2, 0)`)
+        frame: Option<WindowFrame>,
+        /// `.by`: window partition for this mutate only; the result is ungrouped
+        by: Vec<String>,
         location: SourceLocation,
     },
     /// Rename one or more columns (dplyr-style: new_name = old_name)
@@ -88,19 +109,38 @@ pub enum DplyrOperation {
         renames: Vec<RenameSpec>,
         location: SourceLocation,
     },
+    /// Rename columns by applying a function to their names (`rename_with()`)
+    RenameWith {
+        /// New name as an expression over `.x` (`toupper(.x)`)
+        function: Expr,
+        /// Columns to rename: names or select helpers (`everything()` by default)
+        columns: Expr,
+        location: SourceLocation,
+    },
     /// ORDER BY operation (sorting)
     Arrange {
         columns: Vec<OrderExpr>,
+        /// `.by_group = TRUE`: sort by the grouping columns first
+        by_group: bool,
         location: SourceLocation,
     },
     /// GROUP BY operation (grouping)
     GroupBy {
         columns: Vec<String>,
+        /// `.add = TRUE`: extend the current grouping instead of replacing it
+        add: bool,
+        /// `rollup()`/`cube()`/`groupingsets()` wrapper around `columns`
+        sets: Option<GroupingSets>,
         location: SourceLocation,
     },
     /// Aggregation operation
     Summarise {
         aggregations: Vec<Aggregation>,
+        /// Grouping left for later verbs (`.groups`); without it the last
+        /// grouping column is dropped
+        groups: Option<SummariseGroups>,
+        /// `.by`: grouping for this summary only; the result is ungrouped
+        by: Vec<String>,
         location: SourceLocation,
     },
     /// JOIN operation for combining tables
@@ -115,6 +155,142 @@ pub enum DplyrOperation {
         right_table: String,
         location: SourceLocation,
     },
+    /// Row binding (`bind_rows()`): stacks another table or pipeline (UNION ALL)
+    BindRows {
+        source: Box<DplyrNode>,
+        location: SourceLocation,
+    },
+    /// Column binding (`bind_cols()`): pastes another table's columns side by side
+    BindCols {
+        source: Box<DplyrNode>,
+        location: SourceLocation,
+    },
+    /// Keep the first `n` rows of an ordering (slice_min(), slice_max(),
+    /// top_n(), head())
+    TopN {
+        kind: TopNKind,
+        /// Resolved ordering (slice_max already inverted to DESC); empty
+        /// for head(), which keeps the current row order
+        order_by: Vec<OrderExpr>,
+        n: usize,
+        location: SourceLocation,
+    },
+    /// Column reordering (`relocate()`): moves the selected columns (plain
+    /// names or select helpers) to the front, or before/after an anchor
+    Relocate {
+        columns: Vec<Expr>,
+        anchor: Option<RelocateAnchor>,
+        location: SourceLocation,
+    },
+    /// Fill missing values from neighbouring rows (`fill()`)
+    Fill {
+        columns: Vec<String>,
+        direction: FillDirection,
+        location: SourceLocation,
+    },
+    /// Parenthesized pipeline read as a derived table
+    /// (`(raw %>% filter(x > 0)) %>% ...`); only valid as the first operation
+    Subquery {
+        source: Box<DplyrNode>,
+        location: SourceLocation,
+    },
+    /// Inline data source (`tribble()`); only valid as the first operation
+    Tribble {
+        columns: Vec<String>,
+        rows: Vec<Vec<LiteralValue>>,
+        location: SourceLocation,
+    },
+    /// Unique rows (`distinct()`): of the whole projection, or of `columns`
+    /// only, which then form the projection unless `keep_all`
+    /// (`.keep_all = TRUE`) keeps every column of the first row per key
+    Distinct {
+        columns: Vec<String>,
+        keep_all: bool,
+        location: SourceLocation,
+    },
+    /// Row counts per combination of `columns` (`count()`), added to any
+    /// current grouping
+    Count {
+        columns: Vec<String>,
+        /// Name of the count column (`name = ...`, default `n`)
+        name: String,
+        /// `sort = TRUE`: largest counts first
+        sort: bool,
+        /// `wt = ...`: sum of this weight per group instead of a row count
+        wt: Option<Expr>,
+        location: SourceLocation,
+    },
+    /// Random sample of rows (`slice_sample()`)
+    SliceSample {
+        size: SampleSize,
+        /// Sampling weights (`weight_by = w`); larger weights are more
+        /// likely to be drawn
+        weight_by: Option<Expr>,
+        location: SourceLocation,
+    },
+    /// Rows by position (`slice()`), resolved to one contiguous range:
+    /// `slice(2:4)` skips 1 row and keeps 3, `slice(-1)` skips 1 and keeps
+    /// the rest
+    Slice {
+        /// Rows dropped before the kept ones
+        offset: usize,
+        /// Number of rows kept; `None` keeps every remaining row
+        limit: Option<usize>,
+        location: SourceLocation,
+    },
+}
+
+/// Grouping construct of `group_by(rollup(...))` and friends; the grouped
+/// columns themselves stay in `GroupBy::columns`.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum GroupingSets {
+    /// `rollup(a, b)`: the groups `(a, b)`, `(a)` and the grand total
+    Rollup,
+    /// `cube(a, b)`: every combination of the columns
+    Cube,
+    /// `groupingsets(c(a, b), a, c())`: the listed column sets, `c()` being
+    /// the grand total
+    Sets(Vec<Vec<String>>),
+}
+
+/// Size of a `slice_sample()`.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub enum SampleSize {
+    /// A number of rows (`n = 50`, the default `n = 1`)
+    Rows(usize),
+    /// A fraction of the rows between 0 and 1 (`prop = 0.1`)
+    Fraction(f64),
+}
+
+/// Direction of `fill(.direction = ...)`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum FillDirection {
+    /// Carry the last non-missing value forward (default)
+    Down,
+    /// Carry the next non-missing value backward
+    Up,
+    /// Fill down, then up for leading gaps
+    DownUp,
+    /// Fill up, then down for trailing gaps
+    UpDown,
+}
+
+/// Grouping after `summarise(.groups = ...)`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum SummariseGroups {
+    /// Drop the last grouping column (`"drop_last"`, the default)
+    DropLast,
+    /// Drop all grouping, like ungroup() (`"drop"`)
+    Drop,
+    /// Keep the input grouping (`"keep"`)
+    Keep,
+}
+
+/// Destination of relocated columns (`.before` / `.after`).
+#[derive(Debug, Clone, PartialEq)]
+pub enum RelocateAnchor {
+    Before(Expr),
+    After(Expr),
 }
 
 /// Column rename specification (dplyr-style: new_name = old_name).
@@ -132,11 +308,23 @@ impl DplyrOperation {
             Self::Filter { location, .. } => location,
             Self::Mutate { location, .. } => location,
             Self::Rename { location, .. } => location,
+            Self::RenameWith { location, .. } => location,
             Self::Arrange { location, .. } => location,
             Self::GroupBy { location, .. } => location,
             Self::Summarise { location, .. } => location,
             Self::Join { location, .. } => location,
             Self::SetOp { location, .. } => location,
+            Self::BindRows { location, .. } => location,
+            Self::BindCols { location, .. } => location,
+            Self::TopN { location, .. } => location,
+            Self::Relocate { location, .. } => location,
+            Self::Fill { location, .. } => location,
+            Self::Distinct { location, .. } => location,
+            Self::Count { location, .. } => location,
+            Self::Subquery { location, .. } => location,
+            Self::Tribble { location, .. } => location,
+            Self::SliceSample { location, .. } => location,
+            Self::Slice { location, .. } => location,
         }
     }
 
@@ -147,6 +335,7 @@ impl DplyrOperation {
             Self::Filter { .. } => "filter",
             Self::Mutate { .. } => "mutate",
             Self::Rename { .. } => "rename",
+            Self::RenameWith { .. } => "rename_with",
             Self::Arrange { .. } => "arrange",
             Self::GroupBy { .. } => "group_by",
             Self::Summarise { .. } => "summarise",
@@ -156,8 +345,44 @@ impl DplyrOperation {
                 SetOperation::Union => "union",
                 SetOperation::SetDiff => "setdiff",
             },
+            Self::BindRows { .. } => "bind_rows",
+            Self::BindCols { .. } => "bind_cols",
+            Self::TopN { kind, .. } => match kind {
+                TopNKind::SliceMin => "slice_min",
+                TopNKind::SliceMax => "slice_max",
+                TopNKind::TopN => "top_n",
+                TopNKind::Head => "head",
+            },
+            Self::Relocate { .. } => "relocate",
+            Self::Fill { .. } => "fill",
+            Self::Distinct { .. } => "distinct",
+            Self::Count { .. } => "count",
+            Self::Subquery { .. } => "subquery",
+            Self::Tribble { .. } => "tribble",
+            Self::SliceSample { .. } => "slice_sample",
+            Self::Slice { .. } => "slice",
+        }
+    }
+}
+
+/// Column placeholder of across() and if_all()/if_any() lambdas
+/// (`~ .x * 2`).
+pub const LAMBDA_PLACEHOLDER: &str = ".x";
+
+/// Expands the across() `.names` template for each of `columns`: `{.col}` is
+/// the column name and `{.fn}` the function, `1` as across() applies a single
+/// one. Returns the first name given to several columns as the error, since
+/// the later column would silently replace the earlier one.
+pub fn across_output_names(template: &str, columns: &[String]) -> Result<Vec<String>, String> {
+    let mut names: Vec<String> = Vec::with_capacity(columns.len());
+    for column in columns {
+        let name = template.replace("{.col}", column).replace("{.fn}", "1");
+        if names.contains(&name) {
+            return Err(name);
         }
+        names.push(name);
     }
+    Ok(names)
 }
 
 /// Expression types
@@ -165,6 +390,8 @@ impl DplyrOperation {
 pub enum Expr {
     /// Identifier (column name, variable name, etc.)
     Identifier(String),
+    /// Column qualified by a table of the pipeline, e.g. `orders.price`
+    QualifiedIdentifier { table: String, column: String },
     /// Literal value
     Literal(LiteralValue),
     /// Binary operation
@@ -177,6 +404,48 @@ pub enum Expr {
     Function { name: String, args: Vec<Expr> },
     /// Named function argument, e.g. `sep = " "`.
     NamedArg { name: String, value: Box<Expr> },
+    /// Vector literal `c(...)`: a value list for `%in%` or a column list
+    /// for selections and across()
+    Vector(Vec<Expr>),
+}
+
+impl Expr {
+    /// Returns the expression with every identifier `from` replaced by the
+    /// column `to`.
+    pub fn replace_identifier(&self, from: &str, to: &str) -> Expr {
+        match self {
+            Self::Identifier(name) if name == from => Self::Identifier(to.to_string()),
+            Self::Identifier(_) | Self::QualifiedIdentifier { .. } | Self::Literal(_) => {
+                self.clone()
+            }
+            Self::Binary {
+                left,
+                operator,
+                right,
+            } => Self::Binary {
+                left: Box::new(left.replace_identifier(from, to)),
+                operator: operator.clone(),
+                right: Box::new(right.replace_identifier(from, to)),
+            },
+            Self::Function { name, args } => Self::Function {
+                name: name.clone(),
+                args: args
+                    .iter()
+                    .map(|arg| arg.replace_identifier(from, to))
+                    .collect(),
+            },
+            Self::NamedArg { name, value } => Self::NamedArg {
+                name: name.clone(),
+                value: Box::new(value.replace_identifier(from, to)),
+            },
+            Self::Vector(items) => Self::Vector(
+                items
+                    .iter()
+                    .map(|item| item.replace_identifier(from, to))
+                    .collect(),
+            ),
+        }
+    }
 }
 
 /// Literal value types
@@ -199,6 +468,14 @@ pub enum BinaryOp {
     GreaterThan,
     GreaterThanOrEqual,
 
+    // Pattern matching operators
+    /// `%like%`: SQL `LIKE` with the pattern passed through unchanged
+    Like,
+    /// `%ilike%`: case-insensitive `LIKE`
+    ILike,
+    /// `%in%`: membership in a value list (`IN (...)`)
+    In,
+
     // Logical operators
     And,
     Or,
@@ -208,6 +485,10 @@ pub enum BinaryOp {
     Minus,
     Multiply,
     Divide,
+    /// `%/%`: integer division
+    IntegerDivide,
+    /// `%%`: remainder
+    Modulo,
 }
 
 /// Column expression (with alias support)
@@ -231,6 +512,25 @@ pub enum OrderDirection {
     Desc,
 }
 
+impl OrderDirection {
+    /// Returns the opposite direction.
+    pub const fn reversed(&self) -> Self {
+        match self {
+            Self::Asc => Self::Desc,
+            Self::Desc => Self::Asc,
+        }
+    }
+}
+
+/// Verb that produced a top-n row selection
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum TopNKind {
+    SliceMin,
+    SliceMax,
+    TopN,
+    Head,
+}
+
 /// Assignment statement (used in mutate)
 #[derive(Debug, Clone, PartialEq)]
 pub struct Assignment {
@@ -238,12 +538,56 @@ pub struct Assignment {
     pub expr: Expr,
 }
 
+/// Row frame of window aggregates in mutate (`.frame = c(from, to)`).
+///
+/// Offsets are relative to the current row: negative values precede it,
+/// positive values follow it and `None` leaves that side unbounded.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct WindowFrame {
+    pub start: Option<i64>,
+    pub end: Option<i64>,
+}
+
 /// Aggregation operation (used in summarise)
-#[derive(Debug, Clone, PartialEq, Eq)]
+#[derive(Debug, Clone, PartialEq)]
 pub struct Aggregation {
     pub function: String,
     pub column: String,
     pub alias: Option<String>,
+    /// General summary expression (e.g. `sum(x) / total`); when set,
+    /// `function` and `column` are empty.
+    pub expr: Option<Expr>,
+}
+
+impl Aggregation {
+    /// Builds an aggregation from a parsed summary expression.
+    ///
+    /// The common `fn()` and `fn(column)` shapes keep their compact form;
+    /// anything else is stored as a general expression.
+    pub fn from_expr(expr: Expr, alias: Option<String>) -> Self {
+        if let Expr::Function { name, args } = &expr {
+            let column = match args.as_slice() {
+                [] => Some(String::new()),
+                [Expr::Identifier(column)] => Some(column.clone()),
+                _ => None,
+            };
+            if let Some(column) = column {
+                return Self {
+                    function: name.clone(),
+                    column,
+                    alias,
+                    expr: None,
+                };
+            }
+        }
+
+        Self {
+            function: String::new(),
+            column: String::new(),
+            alias,
+            expr: Some(expr),
+        }
+    }
 }
 
 /// Join type for different join operations
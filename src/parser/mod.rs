@@ -4,7 +4,9 @@
 //! smaller modules.
 
 pub mod ast;
+pub mod optimize;
 pub mod parse;
 
 pub use ast::*;
+pub use optimize::optimize;
 pub use parse::Parser;
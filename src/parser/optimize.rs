@@ -0,0 +1,162 @@
+//! AST optimization pass
+//!
+//! Rewrites redundant operation sequences into equivalent, shorter ones
+//! before SQL generation.
+
+use super::ast::{BinaryOp, ColumnExpr, DplyrNode, DplyrOperation, Expr};
+
+/// Functions whose result depends on the rows reaching the filter; a filter
+/// using them cannot be folded into the filter before it.
+const ROW_SET_FUNCTIONS: &[&str] = &[
+    "n",
+    "n_distinct",
+    "sum",
+    "mean",
+    "median",
+    "min",
+    "max",
+    "sd",
+    "var",
+    "any",
+    "all",
+    "row_number",
+    "rank",
+    "min_rank",
+    "dense_rank",
+    "percent_rank",
+    "cume_dist",
+    "ntile",
+    "lag",
+    "lead",
+    "first",
+    "last",
+    "nth",
+    "cumsum",
+    "cummean",
+    "cummin",
+    "cummax",
+];
+
+/// Merges adjacent operations of a pipeline:
+///
+/// - `filter(a) %>% filter(b)` becomes `filter(a & b)`, unless the second
+///   condition uses an aggregate or window function (its value would then
+///   be computed over the rows removed by the first filter);
+/// - `select(a, b) %>% select(a)` becomes `select(a)` when both selections
+///   list plain columns, keeping the aliases of either step.
+///
+/// Nested pipelines (`bind_rows()`/`bind_cols()` inputs) are optimized too.
+pub fn optimize(node: DplyrNode) -> DplyrNode {
+    let DplyrNode::Pipeline {
+        source,
+        target,
+        operations,
+        location,
+    } = node
+    else {
+        return node;
+    };
+
+    let mut merged: Vec<DplyrOperation> = Vec::with_capacity(operations.len());
+    for operation in operations {
+        let operation = optimize_nested(operation);
+        match (merged.last_mut(), operation) {
+            (
+                Some(DplyrOperation::Filter { condition, .. }),
+                DplyrOperation::Filter {
+                    condition: next, ..
+                },
+            ) if !uses_row_set_function(&next) => {
+                *condition = Expr::Binary {
+                    left: Box::new(condition.clone()),
+                    operator: BinaryOp::And,
+                    right: Box::new(next),
+                };
+            }
+            (Some(DplyrOperation::Select { columns, .. }), operation) => match operation {
+                DplyrOperation::Select {
+                    columns: next,
+                    location,
+                } => match narrow_selection(columns, &next) {
+                    Some(narrowed) => *columns = narrowed,
+                    None => merged.push(DplyrOperation::Select {
+                        columns: next,
+                        location,
+                    }),
+                },
+                other => merged.push(other),
+            },
+            (_, operation) => merged.push(operation),
+        }
+    }
+
+    DplyrNode::Pipeline {
+        source,
+        target,
+        operations: merged,
+        location,
+    }
+}
+
+/// Optimizes the pipelines nested in `operation`.
+fn optimize_nested(operation: DplyrOperation) -> DplyrOperation {
+    match operation {
+        DplyrOperation::BindRows { source, location } => DplyrOperation::BindRows {
+            source: Box::new(optimize(*source)),
+            location,
+        },
+        DplyrOperation::BindCols { source, location } => DplyrOperation::BindCols {
+            source: Box::new(optimize(*source)),
+            location,
+        },
+        DplyrOperation::Subquery { source, location } => DplyrOperation::Subquery {
+            source: Box::new(optimize(*source)),
+            location,
+        },
+        other => other,
+    }
+}
+
+/// True when `expr` calls an aggregate or window function.
+fn uses_row_set_function(expr: &Expr) -> bool {
+    match expr {
+        Expr::Identifier(_) | Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => false,
+        Expr::Binary { left, right, .. } => {
+            uses_row_set_function(left) || uses_row_set_function(right)
+        }
+        Expr::Function { name, args } => {
+            ROW_SET_FUNCTIONS.contains(&name.as_str()) || args.iter().any(uses_row_set_function)
+        }
+        Expr::NamedArg { value, .. } => uses_row_set_function(value),
+        Expr::Vector(items) => items.iter().any(uses_row_set_function),
+    }
+}
+
+/// Returns the selection `next` expressed over the inputs of `previous`, or
+/// `None` when either lists something other than plain columns or `next`
+/// picks a column `previous` does not produce.
+fn narrow_selection(previous: &[ColumnExpr], next: &[ColumnExpr]) -> Option<Vec<ColumnExpr>> {
+    let plain_column = |column: &ColumnExpr| match &column.expr {
+        Expr::Identifier(name) => Some(name.clone()),
+        _ => None,
+    };
+
+    next.iter()
+        .map(|column| {
+            let name = plain_column(column)?;
+            let source = previous.iter().find(|candidate| {
+                candidate
+                    .alias
+                    .as_ref()
+                    .or(plain_column(candidate).as_ref())
+                    == Some(&name)
+            })?;
+            let source_name = plain_column(source)?;
+            let output = column.alias.clone().unwrap_or(name);
+            Some(ColumnExpr {
+                alias: (output != source_name).then_some(output),
+                expr: source.expr.clone(),
+            })
+        })
+        .collect()
+}
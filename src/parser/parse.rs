@@ -3,11 +3,15 @@
 //! Provides functionality to convert tokens to AST (Abstract Syntax Tree).
 
 use crate::error::{ParseError, ParseResult};
-use crate::lexer::{Lexer, Token};
+use crate::lexer::{Lexer, SourceComment, Token};
 use crate::PipeSyntax;
 
 pub use super::ast::*;
 
+/// Column placeholder of filter_at()/filter_all() predicates (`. > 0`) and
+/// across() formula lambdas (`~ . * 2`).
+const SCOPED_PREDICATE_PLACEHOLDER: &str = ".";
+
 /// Parser struct
 ///
 /// Provides functionality to parse dplyr tokens into an Abstract Syntax Tree (AST).
@@ -16,6 +20,10 @@ pub struct Parser {
     pipe_syntax: PipeSyntax,
     lazy_input_context: Option<LazyInput>,
     lazy_input_consumed: bool,
+    allow_trailing_pipe: bool,
+    /// Tables read by the pipeline so far (source and joined tables); a
+    /// dotted name starting with one of them is a qualified column.
+    tables: Vec<String>,
     current_token: Token,
     position: usize,
     line: usize,
@@ -50,6 +58,8 @@ impl Parser {
             pipe_syntax,
             lazy_input_context: None,
             lazy_input_consumed: false,
+            allow_trailing_pipe: false,
+            tables: Vec::new(),
             current_token,
             position: 0,
             line: 1,
@@ -57,6 +67,18 @@ impl Parser {
         })
     }
 
+    /// Sets whether a pipe operator at the end of the input, with no verb
+    /// after it, is ignored instead of rejected.
+    pub fn with_trailing_pipe(mut self, allow: bool) -> Self {
+        self.allow_trailing_pipe = allow;
+        self
+    }
+
+    /// Returns the `#` comments seen by the lexer so far.
+    pub fn comments(&self) -> &[SourceComment] {
+        self.lexer.comments()
+    }
+
     /// Parses dplyr code to generate an AST.
     ///
     /// # Returns
@@ -135,6 +157,16 @@ impl Parser {
         Ok(())
     }
 
+    /// Skips a pipe operator and the newlines after it.
+    ///
+    /// Returns false when the pipe is a tolerated trailing one, i.e. no
+    /// verb follows before the end of input.
+    fn advance_past_pipe(&mut self) -> ParseResult<bool> {
+        self.advance()?; // Skip %>%
+        self.skip_newlines()?; // Skip newlines after pipe
+        Ok(!(self.allow_trailing_pipe && self.current_token == Token::EOF))
+    }
+
     /// Checks if we've reached the end of input.
     #[allow(dead_code)]
     fn is_at_end(&self) -> bool {
@@ -171,16 +203,13 @@ impl Parser {
 
             // If followed by pipe operator, this is a data source with pipeline
             if self.current_token == Token::Pipe {
-                // This is a data source followed by operations
-                self.advance()?; // Skip %>%
-                self.skip_newlines()?; // Skip newlines after pipe
-
-                operations.extend(self.parse_pipeline_step()?);
-
-                // Parse additional operations connected by pipe operators
+                self.tables.push(name.clone());
+                // This is a data source followed by operations connected by
+                // pipe operators
                 while self.current_token == Token::Pipe {
-                    self.advance()?; // Skip %>%
-                    self.skip_newlines()?; // Skip newlines after pipe
+                    if !self.advance_past_pipe()? {
+                        break;
+                    }
                     operations.extend(self.parse_pipeline_step()?);
                 }
 
@@ -211,6 +240,14 @@ impl Parser {
                     None
                 };
 
+                // Only a tolerated trailing pipe followed the table.
+                if operations.is_empty() && target.is_none() {
+                    return Ok(DplyrNode::DataSource {
+                        name,
+                        location: start_location,
+                    });
+                }
+
                 return Ok(DplyrNode::Pipeline {
                     source: Some(name),
                     target,
@@ -252,22 +289,16 @@ impl Parser {
 
                     if let Token::Identifier(source_name) = &self.current_token {
                         let source = source_name.clone();
+                        self.tables.push(source.clone());
                         self.advance()?;
                         self.skip_newlines()?;
 
-                        if self.current_token == Token::Pipe {
-                            // Pipeline on the right side
-                            self.advance()?;
-                            self.skip_newlines()?;
-
-                            operations.extend(self.parse_pipeline_step()?);
-
-                            // Parse additional operations
-                            while self.current_token == Token::Pipe {
-                                self.advance()?;
-                                self.skip_newlines()?;
-                                operations.extend(self.parse_pipeline_step()?);
+                        // Pipeline on the right side
+                        while self.current_token == Token::Pipe {
+                            if !self.advance_past_pipe()? {
+                                break;
                             }
+                            operations.extend(self.parse_pipeline_step()?);
                         }
 
                         (Some(source), Some(target))
@@ -286,6 +317,10 @@ impl Parser {
                     operations,
                     location: start_location,
                 });
+            } else if self.current_token == Token::LeftParen && name == "tribble" {
+                // Inline data is the source of the following operations.
+                let inline_data = self.parse_tribble(start_location.clone())?;
+                return self.parse_pipeline_tail(vec![inline_data], start_location);
             } else if self.current_token == Token::LeftParen {
                 // This might be a function call, backtrack and parse as operation
                 // We need to handle this case by creating a synthetic identifier token
@@ -304,13 +339,73 @@ impl Parser {
             }
         }
 
+        if self.current_token == Token::LeftParen {
+            return self.parse_parenthesized_source(start_location);
+        }
+
         // Parse first operation (no data source prefix)
         operations.extend(self.parse_pipeline_step()?);
+        self.parse_pipeline_tail(operations, start_location)
+    }
+
+    /// Parses a pipeline whose source is a parenthesized pipeline, as in
+    /// `(raw %>% filter(x > 0)) %>% summarise(n = n())`. The inner pipeline
+    /// becomes a derived table named after its own source.
+    fn parse_parenthesized_source(
+        &mut self,
+        start_location: SourceLocation,
+    ) -> ParseResult<DplyrNode> {
+        let location = self.current_location();
+        self.advance()?; // Skip (
+        let inner = self.parse_pipeline()?;
+        self.skip_newlines()?;
+        self.expect_token(Token::RightParen)?;
+        self.skip_newlines()?;
+
+        let (source, operations) = match inner {
+            DplyrNode::DataSource { name, .. } => (name, Vec::new()),
+            DplyrNode::Pipeline {
+                target: Some(_), ..
+            } => {
+                return Err(ParseError::InvalidExpression {
+                    expr: "assignment inside a parenthesized pipeline".to_string(),
+                    position: self.position,
+                })
+            }
+            DplyrNode::Pipeline { ref source, .. } => (
+                source.clone().unwrap_or_else(|| "data".to_string()),
+                vec![DplyrOperation::Subquery {
+                    source: Box::new(inner),
+                    location,
+                }],
+            ),
+        };
+        if operations.is_empty() && self.current_token != Token::Pipe {
+            return Ok(DplyrNode::DataSource {
+                name: source,
+                location: start_location,
+            });
+        }
+
+        let mut node = self.parse_pipeline_tail(operations, start_location)?;
+        if let DplyrNode::Pipeline { source: slot, .. } = &mut node {
+            *slot = Some(source);
+        }
+        Ok(node)
+    }
 
+    /// Parses the rest of a pipeline without a named source: further
+    /// operations connected by pipe operators and an optional assignment target.
+    fn parse_pipeline_tail(
+        &mut self,
+        mut operations: Vec<DplyrOperation>,
+        start_location: SourceLocation,
+    ) -> ParseResult<DplyrNode> {
         // Parse additional operations connected by pipe operators
         while self.current_token == Token::Pipe {
-            self.advance()?; // Skip %>%
-            self.skip_newlines()?; // Skip newlines after pipe
+            if !self.advance_past_pipe()? {
+                break;
+            }
             operations.extend(self.parse_pipeline_step()?);
         }
 
@@ -349,6 +444,78 @@ impl Parser {
         })
     }
 
+    /// Parses the arguments of `tribble(~a, ~b, 1, "x", ...)`: column headers
+    /// written as formulas, followed by the cell values in row-major order.
+    /// The `tribble` name has already been consumed.
+    fn parse_tribble(&mut self, location: SourceLocation) -> ParseResult<DplyrOperation> {
+        self.expect_token(Token::LeftParen)?;
+
+        let mut columns = Vec::new();
+        let mut values = Vec::new();
+        while self.current_token != Token::RightParen {
+            self.skip_newlines()?;
+            if self.current_token == Token::Tilde {
+                if !values.is_empty() {
+                    return Err(ParseError::InvalidExpression {
+                        expr: "tribble() column header after values".to_string(),
+                        position: self.position,
+                    });
+                }
+                self.advance()?; // Skip ~
+                columns.push(self.parse_identifier_like("column name")?);
+            } else {
+                values.push(self.parse_tribble_value()?);
+            }
+
+            self.skip_newlines()?;
+            if self.current_token != Token::Comma {
+                break;
+            }
+            self.advance()?; // Skip comma
+            self.skip_newlines()?;
+        }
+        self.expect_token(Token::RightParen)?;
+
+        if columns.is_empty() || values.is_empty() || values.len() % columns.len() != 0 {
+            return Err(ParseError::InvalidExpression {
+                expr: format!(
+                    "tribble() with {} columns and {} values",
+                    columns.len(),
+                    values.len()
+                ),
+                position: self.position,
+            });
+        }
+        let rows = values
+            .chunks(columns.len())
+            .map(<[LiteralValue]>::to_vec)
+            .collect();
+        Ok(DplyrOperation::Tribble {
+            columns,
+            rows,
+            location,
+        })
+    }
+
+    /// Parses one literal cell of a tribble().
+    fn parse_tribble_value(&mut self) -> ParseResult<LiteralValue> {
+        let value = match &self.current_token {
+            Token::String(s) => LiteralValue::String(s.clone()),
+            Token::Number(n) => LiteralValue::Number(*n),
+            Token::Boolean(b) => LiteralValue::Boolean(*b),
+            Token::Null => LiteralValue::Null,
+            _ => {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "literal value".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                })
+            }
+        };
+        self.advance()?;
+        Ok(value)
+    }
+
     /// Parses one pipeline step. A native-pipe lambda RHS like
     /// `(\(x) x |> select(col))()` is normalized to the operations in its body.
     fn parse_pipeline_step(&mut self) -> ParseResult<Vec<DplyrOperation>> {
@@ -437,6 +604,20 @@ impl Parser {
         Ok(self.lexer.peek_token()?)
     }
 
+    /// Consumes a `name =` argument prefix and returns the name, if present.
+    fn parse_argument_name(&mut self) -> ParseResult<Option<String>> {
+        let Token::Identifier(name) = &self.current_token else {
+            return Ok(None);
+        };
+        let name = name.clone();
+        if self.peek_token()? != Token::Assignment {
+            return Ok(None);
+        }
+        self.advance()?; // Skip name
+        self.advance()?; // Skip '='
+        Ok(Some(name))
+    }
+
     fn parse_magrittr_lambda_pipeline_application(
         &mut self,
         opening: Token,
@@ -544,6 +725,22 @@ impl Parser {
             Token::Intersect => self.parse_set_op(SetOperation::Intersect),
             Token::Union => self.parse_set_op(SetOperation::Union),
             Token::SetDiff => self.parse_set_op(SetOperation::SetDiff),
+            Token::BindRows | Token::BindCols => self.parse_bind(),
+            Token::SliceMin | Token::SliceMax => self.parse_slice_min_max(),
+            Token::Relocate => self.parse_relocate(),
+            Token::TopN => self.parse_top_n(),
+            Token::Fill => self.parse_fill(),
+            Token::Distinct => self.parse_distinct(),
+            // `count` stays an identifier so it remains usable as a column name.
+            Token::Identifier(name) if name == "count" => self.parse_count(),
+            Token::Identifier(name) if name == "filter_at" || name == "filter_all" => {
+                self.parse_scoped_filter()
+            }
+            Token::Identifier(name) if name == "rename_with" => self.parse_rename_with(),
+            Token::Identifier(name) if name == "head" => self.parse_head(),
+            Token::Identifier(name) if name == "slice_sample" => self.parse_slice_sample(),
+            Token::Identifier(name) if name == "slice" => self.parse_slice(),
+            Token::Identifier(name) if name == "pull" => self.parse_pull(),
             _ => Err(ParseError::UnexpectedToken {
                 expected: "dplyr function".to_string(),
                 found: format!("{}", self.current_token),
@@ -563,12 +760,12 @@ impl Parser {
 
         // First column
         if self.current_token != Token::RightParen {
-            columns.push(self.parse_column_expr()?);
+            push_select_column(&mut columns, self.parse_column_expr()?);
 
             // Additional columns (comma-separated)
             while self.current_token == Token::Comma {
                 self.advance()?; // Skip comma
-                columns.push(self.parse_column_expr()?);
+                push_select_column(&mut columns, self.parse_column_expr()?);
             }
         }
 
@@ -576,6 +773,65 @@ impl Parser {
         Ok(DplyrOperation::Select { columns, location })
     }
 
+    /// Parses `pull(var, name = )` into the projection of `var`, preceded by
+    /// the `name` column that labels a named vector: `pull(v, name = k)`
+    /// selects `k, v`. `var` defaults to the last column, as in dplyr.
+    fn parse_pull(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'pull'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut value = None;
+        let mut name = None;
+        while self.current_token != Token::RightParen {
+            match self.parse_argument_name()?.as_deref() {
+                Some("var") | None if value.is_none() => {
+                    let column = self.parse_identifier_like("column name")?;
+                    value = Some(self.column_reference(column));
+                }
+                Some("name") => {
+                    let column = self.parse_identifier_like("column name")?;
+                    name = Some(self.column_reference(column));
+                }
+                Some(other) => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("pull({other} = ...)"),
+                        position: self.position,
+                    })
+                }
+                None => {
+                    return Err(ParseError::TooManyArguments {
+                        function: "pull".to_string(),
+                        position: self.position,
+                    })
+                }
+            }
+
+            if self.current_token == Token::Comma {
+                self.advance()?;
+            } else if self.current_token != Token::RightParen {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "comma or closing paren".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                });
+            }
+        }
+        self.expect_token(Token::RightParen)?;
+
+        let value = value.unwrap_or_else(|| Expr::Function {
+            name: "last_col".to_string(),
+            args: Vec::new(),
+        });
+        let columns = name
+            .into_iter()
+            .chain([value])
+            .map(|expr| ColumnExpr { expr, alias: None })
+            .collect();
+        Ok(DplyrOperation::Select { columns, location })
+    }
+
     /// Parses filter() operation.
     fn parse_filter(&mut self) -> ParseResult<DplyrOperation> {
         let location = self.current_location();
@@ -592,6 +848,87 @@ impl Parser {
         })
     }
 
+    /// Parses the superseded scoped filters `filter_at(vars(a, b),
+    /// all_vars(. > 0))` and `filter_all(any_vars(. > 0))` into a filter on
+    /// `filter_at(cols, all_vars(pred))` over the columns (`everything()` for
+    /// filter_all()), keeping the verb name for error messages.
+    fn parse_scoped_filter(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        let verb = match &self.current_token {
+            Token::Identifier(name) => name.clone(),
+            _ => unreachable!("parse_scoped_filter is only called on filter_at/filter_all"),
+        };
+        let scoped_to_vars = verb == "filter_at";
+        self.advance()?; // Skip 'filter_at' / 'filter_all'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let columns = if scoped_to_vars {
+            let columns = self.parse_vars()?;
+            self.expect_token(Token::Comma)?;
+            Expr::Vector(columns)
+        } else {
+            Expr::Function {
+                name: "everything".to_string(),
+                args: Vec::new(),
+            }
+        };
+
+        let combinator = match &self.current_token {
+            Token::Identifier(name) if name == "all_vars" || name == "any_vars" => name.clone(),
+            _ => {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "all_vars() or any_vars()".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                })
+            }
+        };
+        self.advance()?; // Skip 'all_vars' / 'any_vars'
+        self.expect_token(Token::LeftParen)?;
+        let predicate = self
+            .parse_expression()?
+            .replace_identifier(SCOPED_PREDICATE_PLACEHOLDER, LAMBDA_PLACEHOLDER);
+        self.expect_token(Token::RightParen)?;
+
+        self.expect_token(Token::RightParen)?;
+        Ok(DplyrOperation::Filter {
+            condition: Expr::Function {
+                name: verb,
+                args: vec![
+                    columns,
+                    Expr::Function {
+                        name: combinator,
+                        args: vec![predicate],
+                    },
+                ],
+            },
+            location,
+        })
+    }
+
+    /// Parses `vars(a, b, ...)`: column names or select helpers.
+    fn parse_vars(&mut self) -> ParseResult<Vec<Expr>> {
+        if self.current_token != Token::Identifier("vars".to_string()) {
+            return Err(ParseError::UnexpectedToken {
+                expected: "vars()".to_string(),
+                found: format!("{}", self.current_token),
+                position: self.position,
+            });
+        }
+        self.advance()?; // Skip 'vars'
+        self.expect_token(Token::LeftParen)?;
+
+        let mut columns = vec![self.parse_expression()?];
+        while self.current_token == Token::Comma {
+            self.advance()?; // Skip comma
+            columns.push(self.parse_expression()?);
+        }
+
+        self.expect_token(Token::RightParen)?;
+        Ok(columns)
+    }
+
     /// Parses mutate() operation.
     fn parse_mutate(&mut self) -> ParseResult<DplyrOperation> {
         let location = self.current_location();
@@ -600,21 +937,25 @@ impl Parser {
         self.consume_optional_lazy_data_argument()?;
 
         let mut assignments = Vec::new();
+        let mut frame = None;
+        let mut by = Vec::new();
 
         // First assignment
         if self.current_token != Token::RightParen {
-            assignments.push(self.parse_assignment()?);
+            self.parse_mutate_argument(&mut assignments, &mut frame, &mut by)?;
 
             // Additional assignments (comma-separated)
             while self.current_token == Token::Comma {
                 self.advance()?; // Skip comma
-                assignments.push(self.parse_assignment()?);
+                self.parse_mutate_argument(&mut assignments, &mut frame, &mut by)?;
             }
         }
 
         self.expect_token(Token::RightParen)?;
         Ok(DplyrOperation::Mutate {
             assignments,
+            frame,
+            by,
             location,
         })
     }
@@ -668,6 +1009,72 @@ impl Parser {
         }
     }
 
+    /// Parses `rename_with(.fn, .cols)`. `.fn` is a function name
+    /// (`toupper`) or a formula over `.x` (`~ paste0(.x, "_old")`); `.cols`
+    /// defaults to `everything()`.
+    fn parse_rename_with(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'rename_with'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut function = None;
+        let mut columns = None;
+        let mut positional = 0;
+
+        while self.current_token != Token::RightParen {
+            let slot = match self.parse_argument_name()?.as_deref() {
+                Some(".fn") => 0,
+                Some(".cols") => 1,
+                Some(other) => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("rename_with({other} = ...)"),
+                        position: self.position,
+                    })
+                }
+                None => {
+                    positional += 1;
+                    positional - 1
+                }
+            };
+
+            match slot {
+                0 => function = Some(self.parse_across_function()?),
+                1 => columns = Some(self.parse_expression()?),
+                _ => {
+                    return Err(ParseError::TooManyArguments {
+                        function: "rename_with".to_string(),
+                        position: self.position,
+                    })
+                }
+            }
+
+            if self.current_token == Token::Comma {
+                self.advance()?;
+            } else if self.current_token != Token::RightParen {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "comma or closing paren".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                });
+            }
+        }
+        self.expect_token(Token::RightParen)?;
+
+        let function = function.ok_or_else(|| ParseError::MissingArgument {
+            function: "rename_with".to_string(),
+            position: self.position,
+        })?;
+        Ok(DplyrOperation::RenameWith {
+            function,
+            columns: columns.unwrap_or_else(|| Expr::Function {
+                name: "everything".to_string(),
+                args: Vec::new(),
+            }),
+            location,
+        })
+    }
+
     /// Parses arrange() operation.
     fn parse_arrange(&mut self) -> ParseResult<DplyrOperation> {
         let location = self.current_location();
@@ -676,20 +1083,59 @@ impl Parser {
         self.consume_optional_lazy_data_argument()?;
 
         let mut columns = Vec::new();
+        let mut by_group = false;
 
-        // First sort column
         if self.current_token != Token::RightParen {
-            columns.push(self.parse_order_expr()?);
+            loop {
+                match self.parse_argument_name()?.as_deref() {
+                    Some(".by_group") => by_group = self.parse_logical_argument(".by_group")?,
+                    Some(other) => {
+                        return Err(ParseError::InvalidExpression {
+                            expr: format!("arrange({other} = ...)"),
+                            position: self.position,
+                        })
+                    }
+                    None if matches!(&self.current_token, Token::Identifier(name) if name == "across")
+                        && self.peek_token()? == Token::LeftParen =>
+                    {
+                        columns.extend(self.parse_arrange_across()?)
+                    }
+                    None if matches!(&self.current_token, Token::Identifier(name) if name == "c")
+                        && self.peek_token()? == Token::LeftParen =>
+                    {
+                        columns.extend(self.parse_arrange_vector()?)
+                    }
+                    None => columns.push(self.parse_order_expr()?),
+                }
 
-            // Additional sort columns (comma-separated)
-            while self.current_token == Token::Comma {
+                // Additional sort columns (comma-separated)
+                if self.current_token != Token::Comma {
+                    break;
+                }
                 self.advance()?; // Skip comma
-                columns.push(self.parse_order_expr()?);
             }
         }
 
         self.expect_token(Token::RightParen)?;
-        Ok(DplyrOperation::Arrange { columns, location })
+        Ok(DplyrOperation::Arrange {
+            columns,
+            by_group,
+            location,
+        })
+    }
+
+    /// Parses a `TRUE`/`FALSE` argument value.
+    fn parse_logical_argument(&mut self, argument: &str) -> ParseResult<bool> {
+        match self.current_token {
+            Token::Boolean(value) => {
+                self.advance()?;
+                Ok(value)
+            }
+            _ => Err(ParseError::InvalidExpression {
+                expr: format!("{argument} = {}", self.current_token),
+                position: self.position,
+            }),
+        }
     }
 
     /// Parses group_by() operation.
@@ -700,32 +1146,147 @@ impl Parser {
         self.consume_optional_lazy_data_argument()?;
 
         let mut columns = Vec::new();
+        let mut add = false;
+        let mut sets = None;
 
-        // First group column
         if self.current_token != Token::RightParen {
-            if let Token::Identifier(name) = &self.current_token {
-                columns.push(name.clone());
-                self.advance()?;
-
-                // Additional group columns (comma-separated)
-                while self.current_token == Token::Comma {
-                    self.advance()?; // Skip comma
-                    if let Token::Identifier(name) = &self.current_token {
+            loop {
+                match self.parse_argument_name()?.as_deref() {
+                    Some(".add") => add = self.parse_logical_argument(".add")?,
+                    Some(other) => {
+                        return Err(ParseError::InvalidExpression {
+                            expr: format!("group_by({other} = ...)"),
+                            position: self.position,
+                        })
+                    }
+                    None if self.at_grouping_sets_wrapper()? => {
+                        if sets.is_some() || !columns.is_empty() {
+                            return Err(ParseError::InvalidExpression {
+                                expr: "group_by() with rollup()/cube()/groupingsets() takes no other columns"
+                                    .to_string(),
+                                position: self.position,
+                            });
+                        }
+                        let (wrapper, wrapped) = self.parse_grouping_sets()?;
+                        sets = Some(wrapper);
+                        columns = wrapped;
+                    }
+                    None => {
+                        let Token::Identifier(name) = &self.current_token else {
+                            return Err(ParseError::UnexpectedToken {
+                                expected: "identifier".to_string(),
+                                found: format!("{}", self.current_token),
+                                position: self.position,
+                            });
+                        };
                         columns.push(name.clone());
                         self.advance()?;
-                    } else {
-                        return Err(ParseError::UnexpectedToken {
-                            expected: "identifier".to_string(),
-                            found: format!("{}", self.current_token),
-                            position: self.position,
-                        });
                     }
                 }
+
+                // Additional group columns (comma-separated)
+                if self.current_token != Token::Comma {
+                    break;
+                }
+                self.advance()?; // Skip comma
             }
         }
 
         self.expect_token(Token::RightParen)?;
-        Ok(DplyrOperation::GroupBy { columns, location })
+        if add && sets.is_some() {
+            return Err(ParseError::InvalidExpression {
+                expr: "group_by(.add = TRUE) with rollup()/cube()/groupingsets()".to_string(),
+                position: self.position,
+            });
+        }
+        Ok(DplyrOperation::GroupBy {
+            columns,
+            add,
+            sets,
+            location,
+        })
+    }
+
+    /// True at a `rollup(`, `cube(` or `groupingsets(` grouping wrapper.
+    fn at_grouping_sets_wrapper(&mut self) -> ParseResult<bool> {
+        Ok(matches!(
+            &self.current_token,
+            Token::Identifier(name) if matches!(name.as_str(), "rollup" | "cube" | "groupingsets")
+        ) && self.peek_token()? == Token::LeftParen)
+    }
+
+    /// Parses `rollup(a, b)`, `cube(a, b)` or `groupingsets(c(a, b), a, c())`
+    /// and returns the construct with every grouped column, in order of
+    /// first appearance.
+    fn parse_grouping_sets(&mut self) -> ParseResult<(GroupingSets, Vec<String>)> {
+        let Token::Identifier(wrapper) = self.current_token.clone() else {
+            unreachable!("checked by at_grouping_sets_wrapper");
+        };
+        self.advance()?; // Skip wrapper name
+        self.expect_token(Token::LeftParen)?;
+
+        let mut sets = Vec::new();
+        while self.current_token != Token::RightParen {
+            let set = if wrapper == "groupingsets"
+                && matches!(&self.current_token, Token::Identifier(name) if name == "c")
+            {
+                self.advance()?; // Skip 'c'
+                self.expect_token(Token::LeftParen)?;
+                let mut set = Vec::new();
+                if self.current_token != Token::RightParen {
+                    loop {
+                        set.push(self.parse_grouping_column()?);
+                        if self.current_token != Token::Comma {
+                            break;
+                        }
+                        self.advance()?; // Skip comma
+                    }
+                }
+                self.expect_token(Token::RightParen)?;
+                set
+            } else {
+                vec![self.parse_grouping_column()?]
+            };
+            sets.push(set);
+            if self.current_token != Token::Comma {
+                break;
+            }
+            self.advance()?; // Skip comma
+        }
+        self.expect_token(Token::RightParen)?;
+
+        let mut columns: Vec<String> = Vec::new();
+        for column in sets.iter().flatten() {
+            if !columns.contains(column) {
+                columns.push(column.clone());
+            }
+        }
+        if columns.is_empty() {
+            return Err(ParseError::InvalidExpression {
+                expr: format!("{wrapper}() requires at least one column"),
+                position: self.position,
+            });
+        }
+        let sets = match wrapper.as_str() {
+            "rollup" => GroupingSets::Rollup,
+            "cube" => GroupingSets::Cube,
+            _ => GroupingSets::Sets(sets),
+        };
+        Ok((sets, columns))
+    }
+
+    /// Parses one column name inside a grouping wrapper.
+    fn parse_grouping_column(&mut self) -> ParseResult<String> {
+        let Token::Identifier(name) = &self.current_token else {
+            return Err(ParseError::UnexpectedToken {
+                expected: "identifier".to_string(),
+                found: format!("{}", self.current_token),
+                position: self.position,
+            });
+        };
+        let name = name.clone();
+        self.advance()?;
+        Ok(name)
     }
 
     /// Parses summarise() operation.
@@ -736,25 +1297,87 @@ impl Parser {
         self.consume_optional_lazy_data_argument()?;
 
         let mut aggregations = Vec::new();
+        let mut groups = None;
+        let mut by = Vec::new();
 
-        // First aggregation
         if self.current_token != Token::RightParen {
-            aggregations.push(self.parse_aggregation()?);
+            loop {
+                if matches!(&self.current_token, Token::Identifier(name) if name == ".groups")
+                    && self.peek_token()? == Token::Assignment
+                {
+                    self.advance()?; // Skip '.groups'
+                    self.advance()?; // Skip '='
+                    groups = Some(self.parse_summarise_groups()?);
+                } else if matches!(&self.current_token, Token::Identifier(name) if name == ".by")
+                    && self.peek_token()? == Token::Assignment
+                {
+                    self.advance()?; // Skip '.by'
+                    self.advance()?; // Skip '='
+                    by = self.parse_by_columns("summarise")?;
+                } else {
+                    aggregations.push(self.parse_aggregation()?);
+                }
 
-            // Additional aggregations (comma-separated)
-            while self.current_token == Token::Comma {
+                // Additional aggregations (comma-separated)
+                if self.current_token != Token::Comma {
+                    break;
+                }
                 self.advance()?; // Skip comma
-                aggregations.push(self.parse_aggregation()?);
             }
         }
 
         self.expect_token(Token::RightParen)?;
+        if groups.is_some() && !by.is_empty() {
+            // A `.by` summary is always ungrouped.
+            return Err(ParseError::InvalidExpression {
+                expr: "summarise() cannot combine .by with .groups".to_string(),
+                position: self.position,
+            });
+        }
         Ok(DplyrOperation::Summarise {
             aggregations,
+            groups,
+            by,
             location,
         })
     }
 
+    /// Parses the columns of a `.by` argument: `c(a, b)` or `a`.
+    fn parse_by_columns(&mut self, function: &str) -> ParseResult<Vec<String>> {
+        let position = self.position;
+        let selection = self.parse_expression()?;
+        explicit_columns(&selection).ok_or_else(|| ParseError::InvalidExpression {
+            expr: format!("{function}(.by) columns must be listed explicitly, e.g. c(a, b)"),
+            position,
+        })
+    }
+
+    /// Parses the value of `summarise(.groups = ...)`.
+    fn parse_summarise_groups(&mut self) -> ParseResult<SummariseGroups> {
+        let groups = match &self.current_token {
+            Token::String(value) => match value.as_str() {
+                "drop_last" => SummariseGroups::DropLast,
+                "drop" => SummariseGroups::Drop,
+                "keep" => SummariseGroups::Keep,
+                other => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("summarise(.groups = \"{other}\")"),
+                        position: self.position,
+                    })
+                }
+            },
+            _ => {
+                return Err(ParseError::UnexpectedToken {
+                    expected: ".groups string".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                })
+            }
+        };
+        self.advance()?;
+        Ok(groups)
+    }
+
     /// Parses join operations (inner_join, left_join, right_join, full_join, semi_join, anti_join).
     fn parse_join(&mut self) -> ParseResult<DplyrOperation> {
         let join_type = match &self.current_token {
@@ -790,6 +1413,7 @@ impl Parser {
             }
         };
         self.advance()?;
+        self.tables.push(table_name.clone());
 
         // Parse by parameter
         if self.current_token != Token::RightParen && self.current_token != Token::Comma {
@@ -800,147 +1424,1160 @@ impl Parser {
             });
         }
 
+        // Without `by`, the join uses the columns the tables have in common.
+        if self.current_token == Token::RightParen {
+            self.advance()?;
+            return Ok(DplyrOperation::Join {
+                join_type,
+                spec: JoinSpec {
+                    table: table_name,
+                    by_column: None,
+                    on_expr: None,
+                },
+                location,
+            });
+        }
+
         self.expect_token(Token::Comma)?;
         self.expect_identifier_name("by")?;
         self.expect_token(Token::Assignment)?;
 
-        // Parse by parameter - handle string literal as column name
-        let (by_column, on_expr) = match &self.current_token {
-            Token::String(s) => {
-                // by = "column_name" - simple join on same column name
-                let col_name = s.clone();
+        // Parse by parameter - handle string literal as column name
+        let (by_column, on_expr) = match &self.current_token {
+            Token::String(s) => {
+                // by = "column_name" - simple join on same column name
+                let col_name = s.clone();
+                self.advance()?;
+                (Some(col_name), None)
+            }
+            Token::Identifier(_) => {
+                // Could be a column reference or complex expression
+                // For now, parse as expression
+                let expr = self.parse_expression()?;
+                (None, Some(expr))
+            }
+            _ => {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "string literal or identifier for join column".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                })
+            }
+        };
+
+        self.expect_token(Token::RightParen)?;
+
+        Ok(DplyrOperation::Join {
+            join_type,
+            spec: JoinSpec {
+                table: table_name,
+                by_column,
+                on_expr,
+            },
+            location,
+        })
+    }
+
+    /// Parses set operations (intersect, union, setdiff).
+    fn parse_set_op(&mut self, operation: SetOperation) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip function name
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        // Parse table name
+        let right_table = match &self.current_token {
+            Token::Identifier(name) => name.clone(),
+            _ => {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "table name".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                })
+            }
+        };
+        self.advance()?;
+
+        self.expect_token(Token::RightParen)?;
+
+        Ok(DplyrOperation::SetOp {
+            operation,
+            right_table,
+            location,
+        })
+    }
+
+    /// Parses bind_rows()/bind_cols(): the argument is a table name or a nested
+    /// pipeline starting from a table (`bind_rows(b %>% filter(x > 1))`).
+    fn parse_bind(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        let is_rows = self.current_token == Token::BindRows;
+        self.advance()?; // Skip function name
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        if !matches!(self.current_token, Token::Identifier(_)) {
+            return Err(ParseError::UnexpectedToken {
+                expected: "table name".to_string(),
+                found: format!("{}", self.current_token),
+                position: self.position,
+            });
+        }
+        let source = self.parse_pipeline()?;
+
+        self.expect_token(Token::RightParen)?;
+
+        let source = Box::new(source);
+        Ok(if is_rows {
+            DplyrOperation::BindRows { source, location }
+        } else {
+            DplyrOperation::BindCols { source, location }
+        })
+    }
+
+    /// Parses top_n(n, wt).
+    ///
+    /// A positive `n` keeps the largest `wt` values and a negative `n` the
+    /// smallest; `wt` may be wrapped in `desc()`/`asc()`, so `top_n(5,
+    /// desc(x))` keeps the five smallest `x`.
+    fn parse_top_n(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'top_n'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut count = None;
+        let mut wt = None;
+        let mut positional = 0;
+
+        while self.current_token != Token::RightParen {
+            let slot = match self.parse_argument_name()?.as_deref() {
+                Some("n") => 0,
+                Some("wt") => 1,
+                Some(other) => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("top_n({other} = ...)"),
+                        position: self.position,
+                    })
+                }
+                None => {
+                    positional += 1;
+                    positional - 1
+                }
+            };
+
+            match slot {
+                0 => count = Some(self.parse_signed_row_count("top_n")?),
+                1 => wt = Some(self.parse_top_n_weights()?),
+                _ => {
+                    return Err(ParseError::TooManyArguments {
+                        function: "top_n".to_string(),
+                        position: self.position,
+                    })
+                }
+            }
+
+            if self.current_token == Token::Comma {
+                self.advance()?;
+            } else if self.current_token != Token::RightParen {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "comma or closing paren".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                });
+            }
+        }
+        self.expect_token(Token::RightParen)?;
+
+        let missing = || ParseError::MissingArgument {
+            function: "top_n".to_string(),
+            position: self.position,
+        };
+        let (n, bottom) = count.ok_or_else(missing)?;
+        let mut order_by = wt.ok_or_else(missing)?;
+        if !bottom {
+            for order in &mut order_by {
+                order.direction = order.direction.reversed();
+            }
+        }
+
+        Ok(DplyrOperation::TopN {
+            kind: TopNKind::TopN,
+            order_by,
+            n,
+            location,
+        })
+    }
+
+    /// Parses the `wt` of top_n(): one column, or several keys in `c(...)`
+    /// where `desc()` inverts a key (`c(sales, desc(returns))`).
+    fn parse_top_n_weights(&mut self) -> ParseResult<Vec<OrderExpr>> {
+        if !(matches!(&self.current_token, Token::Identifier(name) if name == "c")
+            && self.peek_token()? == Token::LeftParen)
+        {
+            return Ok(vec![self.parse_order_expr()?]);
+        }
+        self.advance()?; // Skip 'c'
+        self.expect_token(Token::LeftParen)?;
+        let mut keys = vec![self.parse_order_expr()?];
+        while self.current_token == Token::Comma {
+            self.advance()?; // Skip comma
+            keys.push(self.parse_order_expr()?);
+        }
+        self.expect_token(Token::RightParen)?;
+        Ok(keys)
+    }
+
+    /// Parses fill(col, ..., .direction = "down").
+    fn parse_fill(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'fill'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut columns = Vec::new();
+        let mut direction = FillDirection::Down;
+
+        if self.current_token != Token::RightParen {
+            loop {
+                match self.parse_argument_name()?.as_deref() {
+                    Some(".direction") => {
+                        direction = match &self.current_token {
+                            Token::String(value) => match value.as_str() {
+                                "down" => FillDirection::Down,
+                                "up" => FillDirection::Up,
+                                "downup" => FillDirection::DownUp,
+                                "updown" => FillDirection::UpDown,
+                                other => {
+                                    return Err(ParseError::InvalidExpression {
+                                        expr: format!("fill(.direction = \"{other}\")"),
+                                        position: self.position,
+                                    })
+                                }
+                            },
+                            _ => {
+                                return Err(ParseError::UnexpectedToken {
+                                    expected: "direction string".to_string(),
+                                    found: format!("{}", self.current_token),
+                                    position: self.position,
+                                })
+                            }
+                        };
+                        self.advance()?;
+                    }
+                    Some(other) => {
+                        return Err(ParseError::InvalidExpression {
+                            expr: format!("fill({other} = ...)"),
+                            position: self.position,
+                        })
+                    }
+                    None => columns.push(self.parse_identifier_like("column name")?),
+                }
+
+                if self.current_token != Token::Comma {
+                    break;
+                }
+                self.advance()?; // Skip comma
+            }
+        }
+
+        self.expect_token(Token::RightParen)?;
+        if columns.is_empty() {
+            return Err(ParseError::MissingArgument {
+                function: "fill".to_string(),
+                position: self.position,
+            });
+        }
+        Ok(DplyrOperation::Fill {
+            columns,
+            direction,
+            location,
+        })
+    }
+
+    /// Parses distinct(): optional column names to deduplicate on and the
+    /// `.keep_all` flag.
+    fn parse_distinct(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'distinct'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut columns = Vec::new();
+        let mut keep_all = false;
+        if self.current_token != Token::RightParen {
+            loop {
+                match self.parse_argument_name()?.as_deref() {
+                    Some(".keep_all") => keep_all = self.parse_logical_argument(".keep_all")?,
+                    Some(other) => {
+                        return Err(ParseError::InvalidExpression {
+                            expr: format!("distinct({other} = ...)"),
+                            position: self.position,
+                        })
+                    }
+                    None => columns.push(self.parse_identifier_like("column name")?),
+                }
+
+                if self.current_token != Token::Comma {
+                    break;
+                }
+                self.advance()?; // Skip comma
+            }
+        }
+
+        self.expect_token(Token::RightParen)?;
+        Ok(DplyrOperation::Distinct {
+            columns,
+            keep_all,
+            location,
+        })
+    }
+
+    /// Parses count(): grouping columns plus the `sort` and `name` arguments.
+    fn parse_count(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'count'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut columns = Vec::new();
+        let mut name = "n".to_string();
+        let mut sort = false;
+        let mut wt = None;
+
+        if self.current_token != Token::RightParen {
+            loop {
+                match self.parse_argument_name()?.as_deref() {
+                    Some("sort") => sort = self.parse_logical_argument("sort")?,
+                    Some("wt") => wt = Some(self.parse_expression()?),
+                    Some("name") => name = self.parse_identifier_like("count column name")?,
+                    Some(other) => {
+                        return Err(ParseError::InvalidExpression {
+                            expr: format!("count({other} = ...)"),
+                            position: self.position,
+                        })
+                    }
+                    None => columns.push(self.parse_identifier_like("column name")?),
+                }
+
+                if self.current_token != Token::Comma {
+                    break;
+                }
+                self.advance()?; // Skip comma
+            }
+        }
+
+        self.expect_token(Token::RightParen)?;
+        Ok(DplyrOperation::Count {
+            columns,
+            name,
+            sort,
+            wt,
+            location,
+        })
+    }
+
+    /// Parses relocate(): column names or select helpers, plus at most one of
+    /// `.before` / `.after`.
+    fn parse_relocate(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'relocate'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut columns = Vec::new();
+        let mut anchor = None;
+
+        if self.current_token != Token::RightParen {
+            loop {
+                let name = self.parse_argument_name()?;
+                match name.as_deref() {
+                    Some(".before") | Some(".after") if anchor.is_some() => {
+                        return Err(ParseError::InvalidExpression {
+                            expr: "relocate() accepts only one of .before and .after".to_string(),
+                            position: self.position,
+                        })
+                    }
+                    Some(".before") => {
+                        anchor = Some(RelocateAnchor::Before(self.parse_expression()?))
+                    }
+                    Some(".after") => {
+                        anchor = Some(RelocateAnchor::After(self.parse_expression()?))
+                    }
+                    Some(other) => {
+                        return Err(ParseError::InvalidExpression {
+                            expr: format!("relocate({other} = ...)"),
+                            position: self.position,
+                        })
+                    }
+                    None => columns.push(self.parse_expression()?),
+                }
+
+                if self.current_token != Token::Comma {
+                    break;
+                }
+                self.advance()?; // Skip comma
+            }
+        }
+
+        self.expect_token(Token::RightParen)?;
+        if columns.is_empty() {
+            return Err(ParseError::MissingArgument {
+                function: "relocate".to_string(),
+                position: self.position,
+            });
+        }
+        Ok(DplyrOperation::Relocate {
+            columns,
+            anchor,
+            location,
+        })
+    }
+
+    /// Parses head(n): the first `n` rows (6 by default, as in R), given
+    /// positionally or as `n = `.
+    fn parse_head(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'head'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut n = 6;
+        if self.current_token != Token::RightParen {
+            match self.parse_argument_name()?.as_deref() {
+                Some("n") | None => n = self.parse_row_count("head")?,
+                Some(other) => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("head({other} = ...)"),
+                        position: self.position,
+                    })
+                }
+            }
+        }
+        if self.current_token == Token::Comma {
+            return Err(ParseError::TooManyArguments {
+                function: "head".to_string(),
+                position: self.position,
+            });
+        }
+        self.expect_token(Token::RightParen)?;
+
+        Ok(DplyrOperation::TopN {
+            kind: TopNKind::Head,
+            order_by: Vec::new(),
+            n,
+            location,
+        })
+    }
+
+    /// Parses slice_sample(): named `n` or `prop` (at most one; `n = 1` by
+    /// default) and `weight_by`. Sampling with replacement is rejected.
+    fn parse_slice_sample(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        self.advance()?; // Skip 'slice_sample'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut size = None;
+        let mut weight_by = None;
+
+        while self.current_token != Token::RightParen {
+            let argument = self.parse_argument_name()?;
+            let invalid = |parser: &Self, argument: &str| ParseError::InvalidExpression {
+                expr: format!("slice_sample({argument} = {})", parser.current_token),
+                position: parser.position,
+            };
+            match argument.as_deref() {
+                Some(name @ ("n" | "prop")) if size.is_some() => {
+                    return Err(invalid(self, name));
+                }
+                Some("n") => size = Some(SampleSize::Rows(self.parse_row_count("slice_sample")?)),
+                Some("prop") => match self.current_token {
+                    Token::Number(prop) if (0.0..=1.0).contains(&prop) => {
+                        self.advance()?;
+                        size = Some(SampleSize::Fraction(prop));
+                    }
+                    _ => return Err(invalid(self, "prop")),
+                },
+                Some("weight_by") => weight_by = Some(self.parse_expression()?),
+                Some("replace") => {
+                    if self.current_token == Token::Boolean(true) {
+                        return Err(invalid(self, "replace"));
+                    }
+                    self.parse_logical_argument("replace")?;
+                }
+                Some(other) => return Err(invalid(self, other)),
+                None => {
+                    return Err(ParseError::UnexpectedToken {
+                        expected: "named slice_sample() argument (n, prop, weight_by)".to_string(),
+                        found: format!("{}", self.current_token),
+                        position: self.position,
+                    })
+                }
+            }
+
+            if self.current_token == Token::Comma {
+                self.advance()?;
+            } else if self.current_token != Token::RightParen {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "comma or closing paren".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                });
+            }
+        }
+        self.expect_token(Token::RightParen)?;
+
+        Ok(DplyrOperation::SliceSample {
+            size: size.unwrap_or(SampleSize::Rows(1)),
+            weight_by,
+            location,
+        })
+    }
+
+    /// Parses slice(): comma-separated row positions and ranges (`2`,
+    /// `1:3`), or negated ones dropping rows (`-1`, `-(1:3)`, `-1:-3`).
+    ///
+    /// The positions must form one contiguous range, kept in ascending
+    /// order, and dropped rows must be the leading ones; that is what a
+    /// LIMIT/OFFSET can render.
+    fn parse_slice(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        let start = self.position;
+        self.advance()?; // Skip 'slice'
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut kept = Vec::new();
+        let mut dropped = Vec::new();
+        loop {
+            if self.current_token == Token::Minus {
+                self.advance()?;
+                if self.current_token == Token::LeftParen {
+                    self.advance()?;
+                    dropped.extend(self.parse_slice_range(false)?);
+                    self.expect_token(Token::RightParen)?;
+                } else {
+                    dropped.extend(self.parse_slice_range(true)?);
+                }
+            } else {
+                kept.extend(self.parse_slice_range(false)?);
+            }
+            if self.current_token != Token::Comma {
+                break;
+            }
+            self.advance()?;
+        }
+        self.expect_token(Token::RightParen)?;
+
+        let invalid = |reason: &str| ParseError::InvalidExpression {
+            expr: format!("slice(): {reason}"),
+            position: start,
+        };
+        if kept.contains(&0) || dropped.contains(&0) {
+            return Err(invalid("row positions start at 1"));
+        }
+        let (offset, limit) = match (kept.is_empty(), dropped.is_empty()) {
+            (false, true) => {
+                let first = kept[0];
+                if kept.iter().enumerate().any(|(i, row)| *row != first + i) {
+                    return Err(invalid("rows must form one ascending range"));
+                }
+                (first - 1, Some(kept.len()))
+            }
+            (true, false) => {
+                dropped.sort_unstable();
+                dropped.dedup();
+                if dropped.iter().enumerate().any(|(i, row)| *row != i + 1) {
+                    return Err(invalid("only leading rows can be dropped"));
+                }
+                (dropped.len(), None)
+            }
+            _ => return Err(invalid("cannot mix kept and dropped rows")),
+        };
+
+        Ok(DplyrOperation::Slice {
+            offset,
+            limit,
+            location,
+        })
+    }
+
+    /// Parses a row position or `from:to` range of slice(). With
+    /// `negated_end` the sign of a leading `-` also applies to the range
+    /// end, as in `-1:-3`.
+    fn parse_slice_range(&mut self, negated_end: bool) -> ParseResult<Vec<usize>> {
+        let from = self.parse_row_count("slice")?;
+        if self.current_token != Token::Colon {
+            return Ok(vec![from]);
+        }
+        self.advance()?; // Skip ':'
+        if negated_end {
+            self.expect_token(Token::Minus)?;
+        }
+        let to = self.parse_row_count("slice")?;
+        Ok(if from <= to {
+            (from..=to).collect()
+        } else {
+            (to..=from).rev().collect()
+        })
+    }
+
+    /// Parses slice_min()/slice_max().
+    ///
+    /// Accepts `order_by` and `n` positionally or by name. The `order_by` value
+    /// may be wrapped in `desc()`/`asc()`: slice_max keeps the largest values, so
+    /// `slice_max(order_by = desc(x))` keeps the smallest `x` (ORDER BY x ASC).
+    fn parse_slice_min_max(&mut self) -> ParseResult<DplyrOperation> {
+        let location = self.current_location();
+        let kind = if self.current_token == Token::SliceMax {
+            TopNKind::SliceMax
+        } else {
+            TopNKind::SliceMin
+        };
+        let function = self.current_token.to_string();
+        self.advance()?; // Skip function name
+        self.expect_token(Token::LeftParen)?;
+        self.consume_optional_lazy_data_argument()?;
+
+        let mut order_by = None;
+        let mut n = None;
+        let mut positional = 0;
+
+        while self.current_token != Token::RightParen {
+            let slot = match self.parse_argument_name()?.as_deref() {
+                Some("order_by") => 0,
+                Some("n") => 1,
+                Some("with_ties") => 2,
+                Some(other) => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("{function}({other} = ...)"),
+                        position: self.position,
+                    })
+                }
+                None => {
+                    positional += 1;
+                    positional - 1
+                }
+            };
+
+            match slot {
+                0 => order_by = Some(self.parse_order_expr()?),
+                1 => n = Some(self.parse_row_count(&function)?),
+                2 => {
+                    // Ties are not kept (ROW_NUMBER/LIMIT semantics); accept the flag.
+                    self.parse_expression()?;
+                }
+                _ => {
+                    return Err(ParseError::TooManyArguments {
+                        function,
+                        position: self.position,
+                    })
+                }
+            }
+
+            if self.current_token == Token::Comma {
+                self.advance()?;
+            } else if self.current_token != Token::RightParen {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "comma or closing paren".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                });
+            }
+        }
+        self.expect_token(Token::RightParen)?;
+
+        let mut order = order_by.ok_or_else(|| ParseError::MissingArgument {
+            function: function.clone(),
+            position: self.position,
+        })?;
+        if kind == TopNKind::SliceMax {
+            order.direction = order.direction.reversed();
+        }
+
+        Ok(DplyrOperation::TopN {
+            kind,
+            order_by: vec![order],
+            n: n.unwrap_or(1),
+            location,
+        })
+    }
+
+    /// Parses a non-negative whole row count such as the `n` of slice_max().
+    fn parse_row_count(&mut self, function: &str) -> ParseResult<usize> {
+        match self.current_token {
+            Token::Number(value) if value >= 0.0 && value.fract() == 0.0 => {
+                self.advance()?;
+                Ok(value as usize)
+            }
+            _ => Err(ParseError::InvalidExpression {
+                expr: format!("{function}(n = {})", self.current_token),
+                position: self.position,
+            }),
+        }
+    }
+
+    /// Parses a row count that may be negative; returns the magnitude and
+    /// whether the sign was negative.
+    fn parse_signed_row_count(&mut self, function: &str) -> ParseResult<(usize, bool)> {
+        let negative = self.current_token == Token::Minus;
+        if negative {
+            self.advance()?;
+        }
+        Ok((self.parse_row_count(function)?, negative))
+    }
+
+    /// Parses column expressions.
+    fn parse_column_expr(&mut self) -> ParseResult<ColumnExpr> {
+        // Check if this is an alias assignment (alias = expr)
+        if let Token::Identifier(first_name) = &self.current_token {
+            let first_name = first_name.clone();
+
+            // Advance past the identifier
+            self.advance()?;
+
+            // Check if next token is assignment
+            if self.current_token == Token::Assignment {
+                // This is an alias assignment: alias = expr
+                self.advance()?; // Skip =
+                let expr = self.parse_expression()?;
+                return Ok(ColumnExpr {
+                    expr,
+                    alias: Some(first_name),
+                });
+            } else if self.current_token == Token::LeftParen {
+                // This is a function call, we need to backtrack and parse as expression
+                // Put the identifier back and parse as a full expression
+                // Since we can't backtrack easily, we'll handle function call here
+                self.advance()?; // Skip (
+
+                let mut args = Vec::new();
+                if self.current_token != Token::RightParen {
+                    args.push(self.parse_function_argument()?);
+
+                    while self.current_token == Token::Comma {
+                        self.advance()?; // Skip ,
+                        args.push(self.parse_function_argument()?);
+                    }
+                }
+
+                self.expect_token(Token::RightParen)?;
+                let expr = call_expr(first_name, args);
+                return Ok(ColumnExpr { expr, alias: None });
+            } else {
+                // Not an alias or function call, treat the identifier as a regular expression
+                // We already consumed the identifier, so create an Identifier expression
+                return Ok(ColumnExpr {
+                    expr: self.column_reference(first_name),
+                    alias: None,
+                });
+            }
+        }
+
+        // Regular expression without alias (for non-identifier expressions)
+        let expr = self.parse_expression()?;
+        Ok(ColumnExpr { expr, alias: None })
+    }
+
+    /// Parses assignment statements.
+    fn parse_assignment(&mut self) -> ParseResult<Assignment> {
+        if let Token::Identifier(column) = &self.current_token {
+            let column = column.clone();
+            self.advance()?;
+
+            self.expect_token(Token::Assignment)?;
+            let expr = self.parse_expression()?;
+
+            Ok(Assignment { column, expr })
+        } else {
+            Err(ParseError::UnexpectedToken {
+                expected: "column identifier".to_string(),
+                found: format!("{}", self.current_token),
+                position: self.position,
+            })
+        }
+    }
+
+    /// Parses one mutate() argument: an assignment, an across() call, the
+    /// `.frame` of the window aggregates or the `.by` partition.
+    fn parse_mutate_argument(
+        &mut self,
+        assignments: &mut Vec<Assignment>,
+        frame: &mut Option<WindowFrame>,
+        by: &mut Vec<String>,
+    ) -> ParseResult<()> {
+        if matches!(&self.current_token, Token::Identifier(name) if name == "across")
+            && self.peek_token()? == Token::LeftParen
+        {
+            assignments.extend(self.parse_across()?);
+        } else if matches!(&self.current_token, Token::Identifier(name) if name == ".frame")
+            && self.peek_token()? == Token::Assignment
+        {
+            self.advance()?; // Skip '.frame'
+            self.advance()?; // Skip '='
+            *frame = Some(self.parse_window_frame()?);
+        } else if matches!(&self.current_token, Token::Identifier(name) if name == ".by")
+            && self.peek_token()? == Token::Assignment
+        {
+            self.advance()?; // Skip '.by'
+            self.advance()?; // Skip '='
+            *by = self.parse_by_columns("mutate")?;
+        } else {
+            assignments.push(self.parse_assignment()?);
+        }
+        Ok(())
+    }
+
+    /// Parses a window frame `c(from, to)`: integer row offsets relative to
+    /// the current row, or `-Inf`/`Inf` for an unbounded side.
+    fn parse_window_frame(&mut self) -> ParseResult<WindowFrame> {
+        let position = self.position;
+        if !matches!(&self.current_token, Token::Identifier(name) if name == "c") {
+            return Err(ParseError::UnexpectedToken {
+                expected: "c(from, to)".to_string(),
+                found: format!("{}", self.current_token),
+                position,
+            });
+        }
+        self.advance()?; // Skip 'c'
+        self.expect_token(Token::LeftParen)?;
+        let start = self.parse_frame_bound()?;
+        self.expect_token(Token::Comma)?;
+        let end = self.parse_frame_bound()?;
+        self.expect_token(Token::RightParen)?;
+
+        if start == f64::INFINITY || end == f64::NEG_INFINITY || start > end {
+            return Err(ParseError::InvalidExpression {
+                expr: ".frame must run from an earlier row to a later one".to_string(),
+                position,
+            });
+        }
+        let offset = |value: f64| value.is_finite().then_some(value as i64);
+        Ok(WindowFrame {
+            start: offset(start),
+            end: offset(end),
+        })
+    }
+
+    /// Parses one frame bound: an integer or `Inf`, optionally negated.
+    fn parse_frame_bound(&mut self) -> ParseResult<f64> {
+        let negative = self.current_token == Token::Minus;
+        if negative {
+            self.advance()?; // Skip '-'
+        }
+        let value = match &self.current_token {
+            Token::Number(value) if value.fract() == 0.0 => *value,
+            Token::Identifier(name) if name == "Inf" => f64::INFINITY,
+            other => {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "integer row offset or Inf".to_string(),
+                    found: format!("{other}"),
+                    position: self.position,
+                })
+            }
+        };
+        self.advance()?;
+        Ok(if negative { -value } else { value })
+    }
+
+    /// Parses `across(.cols, .fns, .names)` and expands it into one assignment
+    /// per column.
+    ///
+    /// `.cols` lists the columns (`c(a, b)` or a single name) or uses
+    /// selection helpers such as `where(is.numeric)`; `.fns` is a formula
+    /// lambda using `.x` or `.` (`~ .x * 2`) or a function name. `.names` is
+    /// a glue template where `{.col}` is the column name and `{.fn}` the
+    /// function (`1`); without it the columns are overwritten. A template
+    /// giving several columns the same name is an error.
+    ///
+    /// Helpers need the table schema, so such a call is kept as one
+    /// assignment of `across(selection, body)` to the `.names` template and
+    /// expanded during SQL generation.
+    fn parse_across(&mut self) -> ParseResult<Vec<Assignment>> {
+        self.advance()?; // Skip 'across'
+        self.expect_token(Token::LeftParen)?;
+
+        let mut columns = None;
+        let mut function = None;
+        let mut names = None;
+        let mut positional = 0;
+
+        while self.current_token != Token::RightParen {
+            let slot = match self.parse_argument_name()?.as_deref() {
+                Some(".cols") => 0,
+                Some(".fns") => 1,
+                Some(".names") => 2,
+                Some(other) => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("across({other} = ...)"),
+                        position: self.position,
+                    })
+                }
+                None => {
+                    positional += 1;
+                    positional - 1
+                }
+            };
+
+            match slot {
+                0 => columns = Some(self.parse_across_selection()?),
+                1 => function = Some(self.parse_across_function()?),
+                2 => names = Some(self.parse_across_names()?),
+                _ => {
+                    return Err(ParseError::TooManyArguments {
+                        function: "across".to_string(),
+                        position: self.position,
+                    })
+                }
+            }
+
+            if self.current_token == Token::Comma {
+                self.advance()?;
+            } else if self.current_token != Token::RightParen {
+                return Err(ParseError::UnexpectedToken {
+                    expected: "comma or closing paren".to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                });
+            }
+        }
+        self.expect_token(Token::RightParen)?;
+
+        let columns = columns.ok_or_else(|| ParseError::MissingArgument {
+            function: "across".to_string(),
+            position: self.position,
+        })?;
+        let template = names.unwrap_or_else(|| "{.col}".to_string());
+
+        let Some(columns) = explicit_columns(&columns) else {
+            let body = function.unwrap_or_else(|| Expr::Identifier(LAMBDA_PLACEHOLDER.to_string()));
+            return Ok(vec![Assignment {
+                column: template,
+                expr: Expr::Function {
+                    name: "across".to_string(),
+                    args: vec![columns, body],
+                },
+            }]);
+        };
+
+        let names =
+            across_output_names(&template, &columns).map_err(|alias| ParseError::InvalidAlias {
+                alias,
+                position: self.position,
+            })?;
+        Ok(columns
+            .into_iter()
+            .zip(names)
+            .map(|(column, name)| Assignment {
+                column: name,
+                expr: match &function {
+                    Some(body) => body.replace_identifier(LAMBDA_PLACEHOLDER, &column),
+                    None => Expr::Identifier(column),
+                },
+            })
+            .collect())
+    }
+
+    /// Parses `c(a, desc(b))` inside arrange() into its sort keys, in order.
+    fn parse_arrange_vector(&mut self) -> ParseResult<Vec<OrderExpr>> {
+        self.advance()?; // Skip 'c'
+        self.expect_token(Token::LeftParen)?;
+
+        let mut columns = Vec::new();
+        while self.current_token != Token::RightParen {
+            columns.push(self.parse_order_expr()?);
+            if self.current_token != Token::Comma {
+                break;
+            }
+            self.advance()?; // Skip comma
+        }
+
+        self.expect_token(Token::RightParen)?;
+        Ok(columns)
+    }
+
+    /// Parses `across(.cols, .fns)` inside arrange() into one sort key per
+    /// column. `.fns` is `desc`/`asc` or a formula such as `~ desc(.x)`;
+    /// without it the columns sort ascending.
+    fn parse_arrange_across(&mut self) -> ParseResult<Vec<OrderExpr>> {
+        self.advance()?; // Skip 'across'
+        self.expect_token(Token::LeftParen)?;
+
+        let mut columns = None;
+        let mut direction = OrderDirection::Asc;
+        let mut positional = 0;
+
+        while self.current_token != Token::RightParen {
+            let slot = match self.parse_argument_name()?.as_deref() {
+                Some(".cols") => 0,
+                Some(".fns") => 1,
+                Some(other) => {
+                    return Err(ParseError::InvalidExpression {
+                        expr: format!("across({other} = ...)"),
+                        position: self.position,
+                    })
+                }
+                None => {
+                    positional += 1;
+                    positional - 1
+                }
+            };
+
+            match slot {
+                0 => columns = Some(self.parse_explicit_across_columns()?),
+                1 => direction = self.parse_across_direction()?,
+                _ => {
+                    return Err(ParseError::TooManyArguments {
+                        function: "across".to_string(),
+                        position: self.position,
+                    })
+                }
+            }
+
+            if self.current_token == Token::Comma {
                 self.advance()?;
-                (Some(col_name), None)
-            }
-            Token::Identifier(_) => {
-                // Could be a column reference or complex expression
-                // For now, parse as expression
-                let expr = self.parse_expression()?;
-                (None, Some(expr))
-            }
-            _ => {
+            } else if self.current_token != Token::RightParen {
                 return Err(ParseError::UnexpectedToken {
-                    expected: "string literal or identifier for join column".to_string(),
+                    expected: "comma or closing paren".to_string(),
                     found: format!("{}", self.current_token),
                     position: self.position,
-                })
+                });
             }
-        };
-
+        }
         self.expect_token(Token::RightParen)?;
 
-        Ok(DplyrOperation::Join {
-            join_type,
-            spec: JoinSpec {
-                table: table_name,
-                by_column,
-                on_expr,
-            },
-            location,
-        })
+        let columns = columns.ok_or_else(|| ParseError::MissingArgument {
+            function: "across".to_string(),
+            position: self.position,
+        })?;
+        Ok(columns
+            .into_iter()
+            .map(|column| OrderExpr {
+                column,
+                direction: direction.clone(),
+            })
+            .collect())
     }
 
-    /// Parses set operations (intersect, union, setdiff).
-    fn parse_set_op(&mut self, operation: SetOperation) -> ParseResult<DplyrOperation> {
-        let location = self.current_location();
-        self.advance()?; // Skip function name
-        self.expect_token(Token::LeftParen)?;
-        self.consume_optional_lazy_data_argument()?;
+    /// Parses the sort function of `arrange(across(...))`: `desc`, `asc`,
+    /// `~ desc(.x)`, `~ asc(.x)` or `~ .x`.
+    fn parse_across_direction(&mut self) -> ParseResult<OrderDirection> {
+        let formula = self.current_token == Token::Tilde;
+        if formula {
+            self.advance()?; // Skip '~'
+            if matches!(&self.current_token, Token::Identifier(name) if name == LAMBDA_PLACEHOLDER)
+            {
+                self.advance()?;
+                return Ok(OrderDirection::Asc);
+            }
+        }
 
-        // Parse table name
-        let right_table = match &self.current_token {
-            Token::Identifier(name) => name.clone(),
-            _ => {
+        let direction = match &self.current_token {
+            Token::Desc => OrderDirection::Desc,
+            Token::Asc => OrderDirection::Asc,
+            Token::Identifier(name) if name == "desc" => OrderDirection::Desc,
+            Token::Identifier(name) if name == "asc" => OrderDirection::Asc,
+            other => {
                 return Err(ParseError::UnexpectedToken {
-                    expected: "table name".to_string(),
-                    found: format!("{}", self.current_token),
+                    expected: "desc or asc".to_string(),
+                    found: format!("{other}"),
                     position: self.position,
                 })
             }
         };
         self.advance()?;
 
-        self.expect_token(Token::RightParen)?;
-
-        Ok(DplyrOperation::SetOp {
-            operation,
-            right_table,
-            location,
-        })
-    }
-
-    /// Parses column expressions.
-    fn parse_column_expr(&mut self) -> ParseResult<ColumnExpr> {
-        // Check if this is an alias assignment (alias = expr)
-        if let Token::Identifier(first_name) = &self.current_token {
-            let first_name = first_name.clone();
-
-            // Advance past the identifier
+        if formula {
+            self.expect_token(Token::LeftParen)?;
+            if !matches!(&self.current_token, Token::Identifier(name) if name == LAMBDA_PLACEHOLDER)
+            {
+                return Err(ParseError::UnexpectedToken {
+                    expected: LAMBDA_PLACEHOLDER.to_string(),
+                    found: format!("{}", self.current_token),
+                    position: self.position,
+                });
+            }
             self.advance()?;
+            self.expect_token(Token::RightParen)?;
+        }
+        Ok(direction)
+    }
 
-            // Check if next token is assignment
-            if self.current_token == Token::Assignment {
-                // This is an alias assignment: alias = expr
-                self.advance()?; // Skip =
-                let expr = self.parse_expression()?;
-                return Ok(ColumnExpr {
-                    expr,
-                    alias: Some(first_name),
-                });
-            } else if self.current_token == Token::LeftParen {
-                // This is a function call, we need to backtrack and parse as expression
-                // Put the identifier back and parse as a full expression
-                // Since we can't backtrack easily, we'll handle function call here
-                self.advance()?; // Skip (
+    /// Parses the column selection of across(): `c(a, b)`, `a` or selection
+    /// helpers such as `where(is.numeric)`.
+    fn parse_across_selection(&mut self) -> ParseResult<Expr> {
+        let position = self.position;
+        let selection = self.parse_expression()?;
+        let items = match &selection {
+            Expr::Vector(items) => items.as_slice(),
+            other => std::slice::from_ref(other),
+        };
+        if items.is_empty()
+            || !items
+                .iter()
+                .all(|item| matches!(item, Expr::Identifier(_) | Expr::Function { .. }))
+        {
+            return Err(ParseError::InvalidExpression {
+                expr: "across() columns must be column names or selection helpers, e.g. c(a, b)"
+                    .to_string(),
+                position,
+            });
+        }
+        Ok(selection)
+    }
 
-                let mut args = Vec::new();
-                if self.current_token != Token::RightParen {
-                    args.push(self.parse_function_argument()?);
+    /// Parses the explicit column list of across(): `c(a, b)` or `a`.
+    fn parse_explicit_across_columns(&mut self) -> ParseResult<Vec<String>> {
+        let position = self.position;
+        let selection = self.parse_expression()?;
+        explicit_columns(&selection).ok_or_else(|| ParseError::InvalidExpression {
+            expr: "across() columns must be listed explicitly, e.g. c(a, b)".to_string(),
+            position,
+        })
+    }
 
-                    while self.current_token == Token::Comma {
-                        self.advance()?; // Skip ,
-                        args.push(self.parse_function_argument()?);
-                    }
-                }
+    /// Parses the function of across() into an expression over `.x`.
+    fn parse_across_function(&mut self) -> ParseResult<Expr> {
+        if self.current_token == Token::Tilde {
+            self.advance()?; // Skip '~'
+                             // `.` is the older spelling of `.x` in formula lambdas.
+            return Ok(self
+                .parse_expression()?
+                .replace_identifier(SCOPED_PREDICATE_PLACEHOLDER, LAMBDA_PLACEHOLDER));
+        }
 
-                self.expect_token(Token::RightParen)?;
-                let expr = Expr::Function {
-                    name: first_name,
-                    args,
-                };
-                return Ok(ColumnExpr { expr, alias: None });
-            } else {
-                // Not an alias or function call, treat the identifier as a regular expression
-                // We already consumed the identifier, so create an Identifier expression
-                return Ok(ColumnExpr {
-                    expr: Expr::Identifier(first_name),
-                    alias: None,
+        if let Token::Identifier(name) = &self.current_token {
+            let name = name.clone();
+            if self.peek_token()? != Token::LeftParen {
+                self.advance()?;
+                return Ok(Expr::Function {
+                    name,
+                    args: vec![Expr::Identifier(LAMBDA_PLACEHOLDER.to_string())],
                 });
             }
         }
 
-        // Regular expression without alias (for non-identifier expressions)
-        let expr = self.parse_expression()?;
-        Ok(ColumnExpr { expr, alias: None })
+        Err(ParseError::UnexpectedToken {
+            expected: "formula lambda (~ .x) or function name".to_string(),
+            found: format!("{}", self.current_token),
+            position: self.position,
+        })
     }
 
-    /// Parses assignment statements.
-    fn parse_assignment(&mut self) -> ParseResult<Assignment> {
-        if let Token::Identifier(column) = &self.current_token {
-            let column = column.clone();
+    /// Parses the `.names` template of across().
+    fn parse_across_names(&mut self) -> ParseResult<String> {
+        if let Token::String(template) = &self.current_token {
+            let template = template.clone();
             self.advance()?;
-
-            self.expect_token(Token::Assignment)?;
-            let expr = self.parse_expression()?;
-
-            Ok(Assignment { column, expr })
-        } else {
-            Err(ParseError::UnexpectedToken {
-                expected: "column identifier".to_string(),
-                found: format!("{}", self.current_token),
-                position: self.position,
-            })
+            return Ok(template);
         }
+
+        Err(ParseError::UnexpectedToken {
+            expected: "string template for .names".to_string(),
+            found: format!("{}", self.current_token),
+            position: self.position,
+        })
     }
 
     /// Parses sort expressions.
@@ -1050,91 +2687,18 @@ impl Parser {
 
     /// Parses aggregation operations.
     fn parse_aggregation(&mut self) -> ParseResult<Aggregation> {
-        // Handle alias = aggregation_function(column) format
-        if let Token::Identifier(first_name) = &self.current_token {
-            let first_name = first_name.clone();
-            self.advance()?;
-
-            // If = token exists, it's an alias
-            if self.current_token == Token::Assignment {
-                self.advance()?; // Skip =
-
-                // Aggregation function name
-                if let Token::Identifier(function) = &self.current_token {
-                    let function = function.clone();
-                    self.advance()?;
-
-                    self.expect_token(Token::LeftParen)?;
-
-                    // Handle functions with no arguments (like n())
-                    if self.current_token == Token::RightParen {
-                        self.advance()?; // Skip )
-                        Ok(Aggregation {
-                            function,
-                            column: "".to_string(), // Empty column for functions like n()
-                            alias: Some(first_name),
-                        })
-                    } else if let Token::Identifier(column) = &self.current_token {
-                        let column = column.clone();
-                        self.advance()?;
-                        self.expect_token(Token::RightParen)?;
-
-                        Ok(Aggregation {
-                            function,
-                            column,
-                            alias: Some(first_name),
-                        })
-                    } else {
-                        Err(ParseError::UnexpectedToken {
-                            expected: "column identifier or closing parenthesis".to_string(),
-                            found: format!("{}", self.current_token),
-                            position: self.position,
-                        })
-                    }
-                } else {
-                    Err(ParseError::UnexpectedToken {
-                        expected: "aggregation function name".to_string(),
-                        found: format!("{}", self.current_token),
-                        position: self.position,
-                    })
-                }
-            } else {
-                // Function(column) format without alias
-                self.expect_token(Token::LeftParen)?;
-
-                // Handle functions with no arguments (like n())
-                if self.current_token == Token::RightParen {
-                    self.advance()?; // Skip )
-                    Ok(Aggregation {
-                        function: first_name,
-                        column: "".to_string(), // Empty column for functions like n()
-                        alias: None,
-                    })
-                } else if let Token::Identifier(column) = &self.current_token {
-                    let column = column.clone();
-                    self.advance()?;
-                    self.expect_token(Token::RightParen)?;
-
-                    Ok(Aggregation {
-                        function: first_name,
-                        column,
-                        alias: None,
-                    })
-                } else {
-                    Err(ParseError::UnexpectedToken {
-                        expected: "column identifier or closing parenthesis".to_string(),
-                        found: format!("{}", self.current_token),
-                        position: self.position,
-                    })
-                }
-            }
-        } else {
-            Err(ParseError::UnexpectedToken {
+        // Handle [alias =] summary_expression format
+        if !matches!(self.current_token, Token::Identifier(_)) {
+            return Err(ParseError::UnexpectedToken {
                 expected: "aggregation function name or alias".to_string(),
                 found: format!("{}", self.current_token),
                 position: self.position,
-            })
+            });
         }
+
+        let alias = self.parse_argument_name()?;
+        let expr = self.parse_expression()?;
+        Ok(Aggregation::from_expr(expr, alias))
     }
 
     /// Parses expressions.
@@ -1161,11 +2725,11 @@ impl Parser {
 
     /// Parses AND expressions.
     fn parse_and_expression(&mut self) -> ParseResult<Expr> {
-        let mut left = self.parse_equality_expression()?;
+        let mut left = self.parse_not_expression()?;
 
         while self.current_token == Token::And {
             self.advance()?;
-            let right = self.parse_equality_expression()?;
+            let right = self.parse_not_expression()?;
             left = Expr::Binary {
                 left: Box::new(left),
                 operator: BinaryOp::And,
@@ -1176,6 +2740,28 @@ impl Parser {
         Ok(left)
     }
 
+    /// Parses negation (`!x`), kept as a call to R's `!` function. As in R
+    /// it binds looser than comparisons: `!x == 1` is `!(x == 1)`.
+    fn parse_not_expression(&mut self) -> ParseResult<Expr> {
+        if self.current_token != Token::Not {
+            return self.parse_equality_expression();
+        }
+        self.advance()?; // Skip !
+                         // rlang's `!!!` splices a list into the call; there is no list to
+                         // expand here, so reject it instead of reading it as triple negation.
+        if self.current_token == Token::Not && self.peek_token()? == Token::Not {
+            return Err(ParseError::InvalidExpression {
+                expr: "`!!!` splicing is not supported; list the arguments explicitly".to_string(),
+                position: self.position,
+            });
+        }
+        let operand = self.parse_not_expression()?;
+        Ok(Expr::Function {
+            name: "!".to_string(),
+            args: vec![operand],
+        })
+    }
+
     /// Parses equality expressions.
     fn parse_equality_expression(&mut self) -> ParseResult<Expr> {
         let mut left = self.parse_comparison_expression()?;
@@ -1208,12 +2794,18 @@ impl Parser {
                 | Token::LessThanOrEqual
                 | Token::GreaterThan
                 | Token::GreaterThanOrEqual
+                | Token::Like
+                | Token::ILike
+                | Token::In
         ) {
             let operator = match self.current_token {
                 Token::LessThan => BinaryOp::LessThan,
                 Token::LessThanOrEqual => BinaryOp::LessThanOrEqual,
                 Token::GreaterThan => BinaryOp::GreaterThan,
                 Token::GreaterThanOrEqual => BinaryOp::GreaterThanOrEqual,
+                Token::Like => BinaryOp::Like,
+                Token::ILike => BinaryOp::ILike,
+                Token::In => BinaryOp::In,
                 _ => unreachable!(),
             };
             self.advance()?;
@@ -1252,7 +2844,7 @@ impl Parser {
 
     /// Parses multiplication/division expressions.
     fn parse_multiplicative_expression(&mut self) -> ParseResult<Expr> {
-        let mut left = self.parse_primary_expression()?;
+        let mut left = self.parse_special_operator_expression()?;
 
         while matches!(self.current_token, Token::Multiply | Token::Divide) {
             let operator = match self.current_token {
@@ -1261,7 +2853,29 @@ impl Parser {
                 _ => unreachable!(),
             };
             self.advance()?;
-            let right = self.parse_primary_expression()?;
+            let right = self.parse_special_operator_expression()?;
+            left = Expr::Binary {
+                left: Box::new(left),
+                operator,
+                right: Box::new(right),
+            };
+        }
+
+        Ok(left)
+    }
+
+    /// Parses `%/%` and `%%`, which bind tighter than `*` and `/` as in R.
+    fn parse_special_operator_expression(&mut self) -> ParseResult<Expr> {
+        let mut left = self.parse_range_expression()?;
+
+        while matches!(self.current_token, Token::IntegerDivide | Token::Modulo) {
+            let operator = match self.current_token {
+                Token::IntegerDivide => BinaryOp::IntegerDivide,
+                Token::Modulo => BinaryOp::Modulo,
+                _ => unreachable!(),
+            };
+            self.advance()?;
+            let right = self.parse_range_expression()?;
             left = Expr::Binary {
                 left: Box::new(left),
                 operator,
@@ -1272,11 +2886,62 @@ impl Parser {
         Ok(left)
     }
 
+    /// Parses integer ranges such as `1:3`, kept as a call to R's `:` function.
+    fn parse_range_expression(&mut self) -> ParseResult<Expr> {
+        let mut left = self.parse_power_expression()?;
+
+        while self.current_token == Token::Colon {
+            self.advance()?; // Skip :
+            let right = self.parse_power_expression()?;
+            left = Expr::Function {
+                name: ":".to_string(),
+                args: vec![left, right],
+            };
+        }
+
+        Ok(left)
+    }
+
+    /// Parses exponentiation (`x ^ 2`), kept as a call to R's `^` function.
+    /// It binds tightest and is right-associative: `2 ^ 3 ^ 2` is `2 ^ 9`.
+    fn parse_power_expression(&mut self) -> ParseResult<Expr> {
+        let base = self.parse_primary_expression()?;
+        if self.current_token != Token::Caret {
+            return Ok(base);
+        }
+
+        self.advance()?; // Skip ^
+        let exponent = self.parse_power_expression()?;
+        Ok(Expr::Function {
+            name: "^".to_string(),
+            args: vec![base, exponent],
+        })
+    }
+
+    /// Builds the expression for a column name. R names may contain dots
+    /// (`Sepal.Length`), so `orders.price` is only read as the column `price`
+    /// of `orders` when `orders` is a table of the pipeline.
+    fn column_reference(&self, name: String) -> Expr {
+        for table in &self.tables {
+            let column = name
+                .strip_prefix(table.as_str())
+                .and_then(|rest| rest.strip_prefix('.'));
+            if let Some(column) = column.filter(|column| !column.is_empty()) {
+                return Expr::QualifiedIdentifier {
+                    table: table.clone(),
+                    column: column.to_string(),
+                };
+            }
+        }
+        Expr::Identifier(name)
+    }
+
     /// Parses primary expressions.
     fn parse_primary_expression(&mut self) -> ParseResult<Expr> {
         match &self.current_token {
             Token::Identifier(name) => {
                 let name = name.clone();
+                let position = self.position;
                 self.advance()?;
 
                 // Check for function call
@@ -1294,9 +2959,12 @@ impl Parser {
                     }
 
                     self.expect_token(Token::RightParen)?;
-                    Ok(Expr::Function { name, args })
+                    if name == "glue" || name == "str_glue" {
+                        return self.glue_expr(&name, &args, position);
+                    }
+                    Ok(call_expr(name, args))
                 } else {
-                    Ok(Expr::Identifier(name))
+                    Ok(self.column_reference(name))
                 }
             }
             Token::String(s) => {
@@ -1318,6 +2986,12 @@ impl Parser {
                 self.advance()?;
                 Ok(Expr::Literal(LiteralValue::Null))
             }
+            // The current column in all_vars()/any_vars() predicates and
+            // formula lambdas.
+            Token::Dot => {
+                self.advance()?;
+                Ok(Expr::Identifier(SCOPED_PREDICATE_PLACEHOLDER.to_string()))
+            }
             Token::LeftParen => {
                 self.advance()?; // Skip (
                 let expr = self.parse_expression()?;
@@ -1332,8 +3006,76 @@ impl Parser {
         }
     }
 
+    /// Desugars `str_glue("{city}, {state}")` (or `glue()`) into
+    /// `paste0(city, ", ", state)`. Placeholders name columns; `{{` and `}}`
+    /// are literal braces.
+    fn glue_expr(&self, function: &str, args: &[Expr], position: usize) -> ParseResult<Expr> {
+        let invalid = |reason: String| ParseError::InvalidExpression {
+            expr: format!("{function}(): {reason}"),
+            position,
+        };
+        let [Expr::Literal(LiteralValue::String(template))] = args else {
+            return Err(invalid("expects a single template string".to_string()));
+        };
+
+        let mut parts = Vec::new();
+        let mut text = String::new();
+        let mut chars = template.chars().peekable();
+        while let Some(c) = chars.next() {
+            match c {
+                '{' | '}' if chars.peek() == Some(&c) => {
+                    chars.next();
+                    text.push(c);
+                }
+                '{' => {
+                    let mut placeholder = String::new();
+                    loop {
+                        match chars.next() {
+                            Some('}') => break,
+                            Some(c) => placeholder.push(c),
+                            None => return Err(invalid("unclosed '{' in template".to_string())),
+                        }
+                    }
+                    let mut lexer = Lexer::new(placeholder.clone());
+                    let column = match (lexer.next_token(), lexer.next_token()) {
+                        (Ok(Token::Identifier(column)), Ok(Token::EOF)) => column,
+                        _ => {
+                            return Err(invalid(format!(
+                                "placeholder '{{{placeholder}}}' must name a column"
+                            )))
+                        }
+                    };
+                    if !text.is_empty() {
+                        parts.push(Expr::Literal(LiteralValue::String(std::mem::take(
+                            &mut text,
+                        ))));
+                    }
+                    parts.push(self.column_reference(column));
+                }
+                '}' => return Err(invalid("unmatched '}' in template".to_string())),
+                c => text.push(c),
+            }
+        }
+        if !text.is_empty() || parts.is_empty() {
+            parts.push(Expr::Literal(LiteralValue::String(text)));
+        }
+        Ok(Expr::Function {
+            name: "paste0".to_string(),
+            args: parts,
+        })
+    }
+
     fn parse_function_argument(&mut self) -> ParseResult<Expr> {
         let expr = self.parse_expression()?;
+        if self.current_token == Token::Tilde {
+            // Two-sided formula `lhs ~ rhs`, e.g. a case_match() arm.
+            self.advance()?; // Skip ~
+            let rhs = self.parse_expression()?;
+            return Ok(Expr::Function {
+                name: "~".to_string(),
+                args: vec![expr, rhs],
+            });
+        }
         if self.current_token != Token::Assignment {
             return Ok(expr);
         }
@@ -1366,6 +3108,47 @@ enum LazyInput {
     NativeParameter(String),
 }
 
+/// Builds the expression for a call; `c(...)` is a vector literal.
+fn call_expr(name: String, args: Vec<Expr>) -> Expr {
+    if name == "c" {
+        Expr::Vector(args)
+    } else {
+        Expr::Function { name, args }
+    }
+}
+
+/// Adds a select() entry; an unnamed `c(a, b)` contributes its elements.
+fn push_select_column(columns: &mut Vec<ColumnExpr>, column: ColumnExpr) {
+    match column {
+        ColumnExpr {
+            expr: Expr::Vector(items),
+            alias: None,
+        } => columns.extend(
+            items
+                .into_iter()
+                .map(|expr| ColumnExpr { expr, alias: None }),
+        ),
+        column => columns.push(column),
+    }
+}
+
 #[cfg(test)]
 #[path = "tests/parse_tests.rs"]
 mod tests;
+
+/// Column names of an across() selection that lists them explicitly
+/// (`c(a, b)` or `a`); `None` when it uses selection helpers.
+fn explicit_columns(selection: &Expr) -> Option<Vec<String>> {
+    let items = match selection {
+        Expr::Vector(items) => items.as_slice(),
+        other => std::slice::from_ref(other),
+    };
+    let columns = items
+        .iter()
+        .map(|item| match item {
+            Expr::Identifier(column) => Some(column.clone()),
+            _ => None,
+        })
+        .collect::<Option<Vec<_>>>()?;
+    (!columns.is_empty()).then_some(columns)
+}
@@ -100,6 +100,16 @@ fn test_parse_rejects_trailing_tokens_after_operation() {
     assert!(parser.parse().is_err());
 }
 
+#[test]
+fn test_parse_rejects_splice_operator() {
+    // !!! 는 리스트 펼치기이므로 삼중 부정으로 읽지 않음
+    let lexer = Lexer::new("mutate(x = coalesce(!!!cols))".to_string());
+    let mut parser = Parser::new(lexer).unwrap();
+
+    let error = parser.parse().unwrap_err();
+    assert!(error.to_string().contains("!!!"), "{error}");
+}
+
 #[test]
 fn test_parse_rejects_trailing_tokens_after_pipeline() {
     let lexer = Lexer::new("data %>% select(name) bogus".to_string());
@@ -136,6 +146,32 @@ fn test_parse_single_table_left_join() {
     }
 }
 
+#[test]
+fn test_parse_chained_joins_without_by() {
+    let input = "orders %>% left_join(customers) %>% left_join(regions)";
+    let lexer = Lexer::new(input.to_string());
+    let mut parser = Parser::new(lexer).unwrap();
+
+    let ast = parser.parse().expect("joins without by should parse");
+
+    if let DplyrNode::Pipeline { operations, .. } = ast {
+        assert_eq!(operations.len(), 2);
+        let tables: Vec<_> = operations
+            .iter()
+            .map(|op| match op {
+                DplyrOperation::Join { spec, .. } => {
+                    assert!(spec.by_column.is_none() && spec.on_expr.is_none());
+                    spec.table.as_str()
+                }
+                _ => panic!("Expected Join operation"),
+            })
+            .collect();
+        assert_eq!(tables, ["customers", "regions"]);
+    } else {
+        panic!("Expected Pipeline node");
+    }
+}
+
 #[test]
 fn test_parse_single_table_right_join() {
     let input = "right_join(df2, by = \"id\")";
@@ -831,6 +867,65 @@ mod select_parsing_tests {
             panic!("Expected Pipeline node");
         }
     }
+
+    #[test]
+    fn test_select_num_range_keeps_colon_range() {
+        let lexer = Lexer::new("select(num_range(\"x\", 1:3))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Select { columns, .. } = &operations[0] {
+                assert_eq!(
+                    columns[0].expr,
+                    Expr::Function {
+                        name: "num_range".to_string(),
+                        args: vec![
+                            Expr::Literal(LiteralValue::String("x".to_string())),
+                            Expr::Function {
+                                name: ":".to_string(),
+                                args: vec![
+                                    Expr::Literal(LiteralValue::Number(1.0)),
+                                    Expr::Literal(LiteralValue::Number(3.0)),
+                                ],
+                            },
+                        ],
+                    }
+                );
+            } else {
+                panic!("Expected Select operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+    }
+
+    #[test]
+    fn test_select_vector_flattens_to_columns() {
+        let lexer = Lexer::new("select(c(a, b), id)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Select { columns, .. } = &operations[0] {
+                let names: Vec<_> = columns.iter().map(|column| &column.expr).collect();
+                assert_eq!(
+                    names,
+                    vec![
+                        &Expr::Identifier("a".to_string()),
+                        &Expr::Identifier("b".to_string()),
+                        &Expr::Identifier("id".to_string()),
+                    ]
+                );
+            } else {
+                panic!("Expected Select operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+    }
 }
 
 // ===== filter() 함수 파싱 테스트 =====
@@ -1162,6 +1257,8 @@ mod filter_parsing_tests {
             ("filter(a >= b)", BinaryOp::GreaterThanOrEqual),
             ("filter(a == b)", BinaryOp::Equal),
             ("filter(a != b)", BinaryOp::NotEqual),
+            ("filter(a %like% \"A%\")", BinaryOp::Like),
+            ("filter(a %ilike% \"a%\")", BinaryOp::ILike),
         ];
 
         for (input, expected_op) in test_cases {
@@ -1186,6 +1283,116 @@ mod filter_parsing_tests {
             }
         }
     }
+
+    #[test]
+    fn test_filter_at_vars_predicate() {
+        let lexer = Lexer::new("t %>% filter_at(vars(a, b), all_vars(. > 0))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Filter { condition, .. } = &operations[0] {
+                // `.`는 람다 자리표시자 `.x`로 바뀜
+                assert_eq!(
+                    *condition,
+                    Expr::Function {
+                        name: "filter_at".to_string(),
+                        args: vec![
+                            Expr::Vector(vec![
+                                Expr::Identifier("a".to_string()),
+                                Expr::Identifier("b".to_string()),
+                            ]),
+                            Expr::Function {
+                                name: "all_vars".to_string(),
+                                args: vec![Expr::Binary {
+                                    left: Box::new(Expr::Identifier(".x".to_string())),
+                                    operator: BinaryOp::GreaterThan,
+                                    right: Box::new(Expr::Literal(LiteralValue::Number(0.0))),
+                                }],
+                            },
+                        ],
+                    }
+                );
+            } else {
+                panic!("Expected Filter operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+
+        for input in [
+            "t %>% filter_at(c(a, b), all_vars(. > 0))",
+            "t %>% filter_at(vars(a), some_vars(. > 0))",
+            "t %>% filter_all(vars(a), any_vars(. > 0))",
+        ] {
+            let lexer = Lexer::new(input.to_string());
+            let mut parser = Parser::new(lexer).unwrap();
+            assert!(parser.parse().is_err(), "{input} should fail");
+        }
+    }
+
+    #[test]
+    fn test_filter_in_vector() {
+        let lexer = Lexer::new("filter(x %in% c(1, 2))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Filter { condition, .. } = &operations[0] {
+                assert_eq!(
+                    *condition,
+                    Expr::Binary {
+                        left: Box::new(Expr::Identifier("x".to_string())),
+                        operator: BinaryOp::In,
+                        right: Box::new(Expr::Vector(vec![
+                            Expr::Literal(LiteralValue::Number(1.0)),
+                            Expr::Literal(LiteralValue::Number(2.0)),
+                        ])),
+                    }
+                );
+            } else {
+                panic!("Expected Filter operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+    }
+
+    #[test]
+    fn test_filter_qualified_column() {
+        let parse_condition = |input: &str| {
+            let mut parser = Parser::new(Lexer::new(input.to_string())).unwrap();
+            match parser.parse().unwrap() {
+                DplyrNode::Pipeline { operations, .. } => match &operations[0] {
+                    DplyrOperation::Filter { condition, .. } => condition.clone(),
+                    other => panic!("Expected Filter operation, got {other:?}"),
+                },
+                other => panic!("Expected Pipeline node, got {other:?}"),
+            }
+        };
+
+        let condition = parse_condition("orders %>% filter(orders.price > 100)");
+        assert_eq!(
+            condition,
+            Expr::Binary {
+                left: Box::new(Expr::QualifiedIdentifier {
+                    table: "orders".to_string(),
+                    column: "price".to_string(),
+                }),
+                operator: BinaryOp::GreaterThan,
+                right: Box::new(Expr::Literal(LiteralValue::Number(100.0))),
+            }
+        );
+
+        // 파이프라인의 테이블이 아닌 접두어는 점이 들어간 R 열 이름이다
+        let condition = parse_condition("iris %>% filter(Sepal.Length > 5)");
+        assert!(
+            matches!(&condition, Expr::Binary { left, .. } if **left == Expr::Identifier("Sepal.Length".to_string())),
+            "{condition:?}"
+        );
+    }
 }
 
 // ===== mutate() 함수 파싱 테스트 =====
@@ -1486,6 +1693,8 @@ mod mutate_parsing_tests {
             ("mutate(result = a - b)", BinaryOp::Minus),
             ("mutate(result = a * b)", BinaryOp::Multiply),
             ("mutate(result = a / b)", BinaryOp::Divide),
+            ("mutate(result = a %/% b)", BinaryOp::IntegerDivide),
+            ("mutate(result = a %% b)", BinaryOp::Modulo),
         ];
 
         for (input, expected_op) in test_cases {
@@ -1513,6 +1722,343 @@ mod mutate_parsing_tests {
             }
         }
     }
+
+    #[test]
+    fn test_modulo_binds_tighter_than_multiplication() {
+        let lexer = Lexer::new("mutate(result = a * b %% c)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        let ast = parser.parse().unwrap();
+
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::Mutate { assignments, .. } = &operations[0] else {
+            panic!("Expected Mutate operation");
+        };
+        // R과 같이 a * (b %% c)로 파싱되어야 함
+        let Expr::Binary {
+            operator, right, ..
+        } = &assignments[0].expr
+        else {
+            panic!("Expected binary expression");
+        };
+        assert_eq!(*operator, BinaryOp::Multiply);
+        assert!(matches!(
+            right.as_ref(),
+            Expr::Binary {
+                operator: BinaryOp::Modulo,
+                ..
+            }
+        ));
+    }
+
+    #[test]
+    fn test_caret_binds_tighter_than_multiplication() {
+        let lexer = Lexer::new("mutate(sq = 2 * x ^ 2 ^ 3)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        let ast = parser.parse().unwrap();
+
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::Mutate { assignments, .. } = &operations[0] else {
+            panic!("Expected Mutate operation");
+        };
+        // 2 * (x ^ (2 ^ 3)): ^는 우결합
+        let power = |base: Expr, exponent: Expr| Expr::Function {
+            name: "^".to_string(),
+            args: vec![base, exponent],
+        };
+        assert_eq!(
+            assignments[0].expr,
+            Expr::Binary {
+                left: Box::new(Expr::Literal(LiteralValue::Number(2.0))),
+                operator: BinaryOp::Multiply,
+                right: Box::new(power(
+                    Expr::Identifier("x".to_string()),
+                    power(
+                        Expr::Literal(LiteralValue::Number(2.0)),
+                        Expr::Literal(LiteralValue::Number(3.0)),
+                    ),
+                )),
+            }
+        );
+    }
+
+    #[test]
+    fn test_not_binds_looser_than_comparison() {
+        let lexer = Lexer::new("filter(!x == 1 & !is.na(y))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        let DplyrNode::Pipeline { operations, .. } = parser.parse().unwrap() else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::Filter { condition, .. } = &operations[0] else {
+            panic!("Expected Filter operation");
+        };
+        // (!(x == 1)) & (!is.na(y))
+        let not = |operand: Expr| Expr::Function {
+            name: "!".to_string(),
+            args: vec![operand],
+        };
+        assert_eq!(
+            *condition,
+            Expr::Binary {
+                left: Box::new(not(Expr::Binary {
+                    left: Box::new(Expr::Identifier("x".to_string())),
+                    operator: BinaryOp::Equal,
+                    right: Box::new(Expr::Literal(LiteralValue::Number(1.0))),
+                })),
+                operator: BinaryOp::And,
+                right: Box::new(not(Expr::Function {
+                    name: "is.na".to_string(),
+                    args: vec![Expr::Identifier("y".to_string())],
+                })),
+            }
+        );
+    }
+
+    #[test]
+    fn test_mutate_case_match_arms() {
+        let lexer =
+            Lexer::new(r#"mutate(code = case_match(grade, "a" ~ 1, .default = 0))"#.to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Mutate { assignments, .. } = &operations[0] {
+                // 각 분기는 `~` 함수, .default는 이름 있는 인자
+                assert_eq!(
+                    assignments[0].expr,
+                    Expr::Function {
+                        name: "case_match".to_string(),
+                        args: vec![
+                            Expr::Identifier("grade".to_string()),
+                            Expr::Function {
+                                name: "~".to_string(),
+                                args: vec![
+                                    Expr::Literal(LiteralValue::String("a".to_string())),
+                                    Expr::Literal(LiteralValue::Number(1.0)),
+                                ],
+                            },
+                            Expr::NamedArg {
+                                name: ".default".to_string(),
+                                value: Box::new(Expr::Literal(LiteralValue::Number(0.0))),
+                            },
+                        ],
+                    }
+                );
+            } else {
+                panic!("Expected Mutate operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+    }
+
+    #[test]
+    fn test_mutate_window_frame() {
+        let lexer = Lexer::new("mutate(roll = mean(x), .frame = c(-2, 0))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Mutate {
+                assignments, frame, ..
+            } = &operations[0]
+            {
+                // .frame은 할당이 아니라 프레임 지정
+                assert_eq!(assignments.len(), 1);
+                assert_eq!(
+                    *frame,
+                    Some(WindowFrame {
+                        start: Some(-2),
+                        end: Some(0),
+                    })
+                );
+            } else {
+                panic!("Expected Mutate operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+
+        // -Inf는 제한 없음, 거꾸로 된 프레임은 오류
+        let lexer = Lexer::new("mutate(total = sum(x), .frame = c(-Inf, 1))".to_string());
+        let ast = Parser::new(lexer).unwrap().parse().unwrap();
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        assert!(matches!(
+            operations[0],
+            DplyrOperation::Mutate {
+                frame: Some(WindowFrame {
+                    start: None,
+                    end: Some(1),
+                }),
+                ..
+            }
+        ));
+
+        let lexer = Lexer::new("mutate(total = sum(x), .frame = c(1, -1))".to_string());
+        assert!(Parser::new(lexer).unwrap().parse().is_err());
+    }
+
+    #[test]
+    fn test_str_glue_desugars_to_paste0() {
+        let lexer = Lexer::new(r#"mutate(label = str_glue("{city}, { state }{{!}}"))"#.to_string());
+        let ast = Parser::new(lexer).unwrap().parse().unwrap();
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::Mutate { assignments, .. } = &operations[0] else {
+            panic!("Expected Mutate operation");
+        };
+        // 자리표시자는 컬럼, 나머지는 문자열 조각, {{ }}는 중괄호 문자
+        assert_eq!(
+            assignments[0].expr,
+            Expr::Function {
+                name: "paste0".to_string(),
+                args: vec![
+                    Expr::Identifier("city".to_string()),
+                    Expr::Literal(LiteralValue::String(", ".to_string())),
+                    Expr::Identifier("state".to_string()),
+                    Expr::Literal(LiteralValue::String("{!}".to_string())),
+                ],
+            }
+        );
+
+        for input in [
+            r#"mutate(label = glue("{city"))"#,
+            r#"mutate(label = glue("{city + 1}"))"#,
+            "mutate(label = glue(city))",
+        ] {
+            let lexer = Lexer::new(input.to_string());
+            assert!(Parser::new(lexer).unwrap().parse().is_err(), "{input}");
+        }
+    }
+
+    #[test]
+    fn test_mutate_by_partition() {
+        let lexer = Lexer::new("mutate(rank = row_number(), .by = region)".to_string());
+        let ast = Parser::new(lexer).unwrap().parse().unwrap();
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::Mutate {
+            assignments, by, ..
+        } = &operations[0]
+        else {
+            panic!("Expected Mutate operation");
+        };
+        // .by는 새 컬럼이 아님
+        assert_eq!(assignments.len(), 1);
+        assert_eq!(by, &vec!["region".to_string()]);
+    }
+
+    #[test]
+    fn test_mutate_across_with_names_template() {
+        let lexer = Lexer::new(
+            "mutate(across(c(a, b), ~ .x * 2, .names = \"{.col}_dbl\"), flag = TRUE)".to_string(),
+        );
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Mutate { assignments, .. } = &operations[0] {
+                assert_eq!(assignments.len(), 3);
+                for (assignment, column) in assignments.iter().zip(["a", "b"]) {
+                    assert_eq!(assignment.column, format!("{column}_dbl"));
+                    assert_eq!(
+                        assignment.expr,
+                        Expr::Binary {
+                            left: Box::new(Expr::Identifier(column.to_string())),
+                            operator: BinaryOp::Multiply,
+                            right: Box::new(Expr::Literal(LiteralValue::Number(2.0))),
+                        }
+                    );
+                }
+                assert_eq!(assignments[2].column, "flag");
+            } else {
+                panic!("Expected Mutate operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+    }
+
+    #[test]
+    fn test_mutate_across_function_name_overwrites_columns() {
+        let lexer = Lexer::new("mutate(across(.cols = price, .fns = round))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Mutate { assignments, .. } = &operations[0] {
+                assert_eq!(assignments.len(), 1);
+                assert_eq!(assignments[0].column, "price");
+                assert_eq!(
+                    assignments[0].expr,
+                    Expr::Function {
+                        name: "round".to_string(),
+                        args: vec![Expr::Identifier("price".to_string())],
+                    }
+                );
+            } else {
+                panic!("Expected Mutate operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+
+        for input in [
+            "mutate(across(1, ~ .x * 2))",
+            "mutate(across(c(a, b), ~ .x, .names = col))",
+            "mutate(across(c(a), ~ .x, .unknown = 1))",
+        ] {
+            let lexer = Lexer::new(input.to_string());
+            let mut parser = Parser::new(lexer).unwrap();
+            assert!(parser.parse().is_err(), "{input} should fail");
+        }
+    }
+
+    #[test]
+    fn test_mutate_across_helper_selection_is_deferred() {
+        let lexer = Lexer::new("mutate(across(where(is.numeric), ~ . * 1.0))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        let ast = parser.parse().unwrap();
+
+        // 스키마가 필요한 선택은 SQL 생성 단계에서 펼친다
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::Mutate { assignments, .. } = &operations[0] else {
+            panic!("Expected Mutate operation");
+        };
+        assert_eq!(
+            assignments,
+            &vec![Assignment {
+                column: "{.col}".to_string(),
+                expr: Expr::Function {
+                    name: "across".to_string(),
+                    args: vec![
+                        Expr::Function {
+                            name: "where".to_string(),
+                            args: vec![Expr::Identifier("is.numeric".to_string())],
+                        },
+                        Expr::Binary {
+                            left: Box::new(Expr::Identifier(".x".to_string())),
+                            operator: BinaryOp::Multiply,
+                            right: Box::new(Expr::Literal(LiteralValue::Number(1.0))),
+                        },
+                    ],
+                },
+            }]
+        );
+    }
 }
 
 // ===== arrange() 함수 파싱 테스트 =====
@@ -1665,24 +2211,104 @@ mod arrange_parsing_tests {
     }
 
     #[test]
-    fn test_arrange_with_underscore_columns() {
-        let lexer = Lexer::new("arrange(first_name, desc(last_name))".to_string());
+    fn test_arrange_by_group_flag() {
+        let lexer = Lexer::new("arrange(desc(sales), .by_group = TRUE)".to_string());
         let mut parser = Parser::new(lexer).unwrap();
 
         let ast = parser.parse().unwrap();
 
         if let DplyrNode::Pipeline { operations, .. } = ast {
-            assert_eq!(operations.len(), 1);
-            if let DplyrOperation::Arrange { columns, .. } = &operations[0] {
-                assert_eq!(columns.len(), 2);
-
-                // First column: first_name (ascending by default)
-                assert_eq!(columns[0].column, "first_name");
-                assert_eq!(columns[0].direction, OrderDirection::Asc);
-
-                // Second column: last_name (descending)
-                assert_eq!(columns[1].column, "last_name");
-                assert_eq!(columns[1].direction, OrderDirection::Desc);
+            if let DplyrOperation::Arrange {
+                columns, by_group, ..
+            } = &operations[0]
+            {
+                assert!(*by_group);
+                assert_eq!(columns.len(), 1);
+                assert_eq!(columns[0].column, "sales");
+                assert_eq!(columns[0].direction, OrderDirection::Desc);
+            } else {
+                panic!("Expected Arrange operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+
+        let lexer = Lexer::new("arrange(.by_group = \"yes\")".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        assert!(parser.parse().is_err());
+    }
+
+    #[test]
+    fn test_arrange_across_expands_columns() {
+        let lexer = Lexer::new("arrange(id, across(c(a, b), desc))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Arrange { columns, .. } = &operations[0] {
+                // across()는 열마다 정렬 키 하나로 펼쳐진다
+                let keys = columns
+                    .iter()
+                    .map(|order| (order.column.as_str(), order.direction.clone()))
+                    .collect::<Vec<_>>();
+                assert_eq!(
+                    keys,
+                    vec![
+                        ("id", OrderDirection::Asc),
+                        ("a", OrderDirection::Desc),
+                        ("b", OrderDirection::Desc),
+                    ]
+                );
+            } else {
+                panic!("Expected Arrange operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+
+        // 수식 형태와 함수 생략
+        for (code, direction) in [
+            ("arrange(across(c(a, b), ~ desc(.x)))", OrderDirection::Desc),
+            ("arrange(across(c(a, b)))", OrderDirection::Asc),
+        ] {
+            let ast = Parser::new(Lexer::new(code.to_string()))
+                .unwrap()
+                .parse()
+                .unwrap();
+            let DplyrNode::Pipeline { operations, .. } = ast else {
+                panic!("Expected Pipeline node");
+            };
+            let DplyrOperation::Arrange { columns, .. } = &operations[0] else {
+                panic!("Expected Arrange operation");
+            };
+            assert_eq!(columns.len(), 2);
+            assert!(columns.iter().all(|order| order.direction == direction));
+        }
+
+        let lexer = Lexer::new("arrange(across(c(a, b), sqrt))".to_string());
+        assert!(Parser::new(lexer).unwrap().parse().is_err());
+    }
+
+    #[test]
+    fn test_arrange_with_underscore_columns() {
+        let lexer = Lexer::new("arrange(first_name, desc(last_name))".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            assert_eq!(operations.len(), 1);
+            if let DplyrOperation::Arrange { columns, .. } = &operations[0] {
+                assert_eq!(columns.len(), 2);
+
+                // First column: first_name (ascending by default)
+                assert_eq!(columns[0].column, "first_name");
+                assert_eq!(columns[0].direction, OrderDirection::Asc);
+
+                // Second column: last_name (descending)
+                assert_eq!(columns[1].column, "last_name");
+                assert_eq!(columns[1].direction, OrderDirection::Desc);
             } else {
                 panic!("Expected Arrange operation");
             }
@@ -1721,6 +2347,36 @@ mod arrange_parsing_tests {
             panic!("Expected Pipeline node");
         }
     }
+
+    #[test]
+    fn test_arrange_column_vector() {
+        let lexer = Lexer::new("arrange(c(a, desc(b)), c)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Arrange { columns, .. } = &operations[0] {
+                // c()의 열은 나열된 순서대로 정렬 키가 됨
+                let keys: Vec<_> = columns
+                    .iter()
+                    .map(|key| (key.column.as_str(), key.direction.clone()))
+                    .collect();
+                assert_eq!(
+                    keys,
+                    [
+                        ("a", OrderDirection::Asc),
+                        ("b", OrderDirection::Desc),
+                        ("c", OrderDirection::Asc),
+                    ]
+                );
+            } else {
+                panic!("Expected Arrange operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+    }
 }
 
 // ===== group_by() 함수 파싱 테스트 =====
@@ -1853,6 +2509,85 @@ mod group_by_parsing_tests {
             panic!("Expected Pipeline node");
         }
     }
+
+    #[test]
+    fn test_group_by_add_argument() {
+        let lexer = Lexer::new("group_by(a) %>% group_by(b, .add = TRUE)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        assert!(matches!(
+            &operations[0],
+            DplyrOperation::GroupBy { columns, add: false, .. } if columns == &["a"]
+        ));
+        assert!(matches!(
+            &operations[1],
+            DplyrOperation::GroupBy { columns, add: true, .. } if columns == &["b"]
+        ));
+    }
+
+    #[test]
+    fn test_group_by_rejects_unknown_named_argument() {
+        let lexer = Lexer::new("group_by(a, .drop = TRUE)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        // 지원하지 않는 인자는 오류
+        assert!(parser.parse().is_err());
+    }
+
+    #[test]
+    fn test_group_by_grouping_sets_wrappers() {
+        let parse_group_by = |code: &str| {
+            let mut parser = Parser::new(Lexer::new(code.to_string())).unwrap();
+            let DplyrNode::Pipeline { operations, .. } = parser.parse().unwrap() else {
+                panic!("Expected Pipeline node");
+            };
+            match operations.into_iter().next() {
+                Some(DplyrOperation::GroupBy { columns, sets, .. }) => (columns, sets),
+                other => panic!("Expected GroupBy operation, got {other:?}"),
+            }
+        };
+
+        assert_eq!(
+            parse_group_by("group_by(rollup(a, b))"),
+            (
+                vec!["a".to_string(), "b".to_string()],
+                Some(GroupingSets::Rollup)
+            )
+        );
+        assert_eq!(
+            parse_group_by("group_by(cube(a))"),
+            (vec!["a".to_string()], Some(GroupingSets::Cube))
+        );
+        // 컬럼은 처음 등장한 순서로 한 번씩, c()는 전체 합계
+        assert_eq!(
+            parse_group_by("group_by(groupingsets(c(a, b), b, c()))"),
+            (
+                vec!["a".to_string(), "b".to_string()],
+                Some(GroupingSets::Sets(vec![
+                    vec!["a".to_string(), "b".to_string()],
+                    vec!["b".to_string()],
+                    vec![],
+                ]))
+            )
+        );
+        assert_eq!(parse_group_by("group_by(a, b)").1, None);
+
+        // 다른 컬럼이나 .add와 함께 쓸 수 없음
+        for code in [
+            "group_by(c, rollup(a, b))",
+            "group_by(rollup(a), cube(b))",
+            "group_by(rollup(a, b), .add = TRUE)",
+            "group_by(rollup())",
+        ] {
+            let mut parser = Parser::new(Lexer::new(code.to_string())).unwrap();
+            assert!(parser.parse().is_err(), "{code}");
+        }
+    }
 }
 
 // ===== summarise() 함수 파싱 테스트 =====
@@ -2149,6 +2884,96 @@ mod summarise_parsing_tests {
             panic!("Expected Pipeline node");
         }
     }
+
+    #[test]
+    fn test_summarise_expression_referencing_alias() {
+        let lexer = Lexer::new("summarise(total = sum(x), pct = sum(x) / total)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Summarise { aggregations, .. } = &operations[0] {
+                assert_eq!(aggregations.len(), 2);
+                assert_eq!(aggregations[0].function, "sum");
+                assert_eq!(aggregations[0].expr, None);
+
+                // pct = sum(x) / total keeps the whole expression
+                assert_eq!(aggregations[1].function, "");
+                assert_eq!(aggregations[1].alias, Some("pct".to_string()));
+                assert_eq!(
+                    aggregations[1].expr,
+                    Some(Expr::Binary {
+                        left: Box::new(Expr::Function {
+                            name: "sum".to_string(),
+                            args: vec![Expr::Identifier("x".to_string())],
+                        }),
+                        operator: BinaryOp::Divide,
+                        right: Box::new(Expr::Identifier("total".to_string())),
+                    })
+                );
+            } else {
+                panic!("Expected Summarise operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+    }
+
+    #[test]
+    fn test_summarise_groups_argument() {
+        let lexer = Lexer::new("summarise(total = sum(x), .groups = \"drop\")".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+
+        let ast = parser.parse().unwrap();
+
+        if let DplyrNode::Pipeline { operations, .. } = ast {
+            if let DplyrOperation::Summarise {
+                aggregations,
+                groups,
+                ..
+            } = &operations[0]
+            {
+                // .groups는 집계가 아니라 그룹 처리 방식
+                assert_eq!(aggregations.len(), 1);
+                assert_eq!(*groups, Some(SummariseGroups::Drop));
+            } else {
+                panic!("Expected Summarise operation");
+            }
+        } else {
+            panic!("Expected Pipeline node");
+        }
+
+        let lexer = Lexer::new("summarise(total = sum(x), .groups = \"rowwise\")".to_string());
+        assert!(Parser::new(lexer).unwrap().parse().is_err());
+    }
+
+    #[test]
+    fn test_summarise_by_argument() {
+        let lexer = Lexer::new("summarise(n = n(), .by = c(region, year))".to_string());
+        let ast = Parser::new(lexer).unwrap().parse().unwrap();
+
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::Summarise {
+            aggregations, by, ..
+        } = &operations[0]
+        else {
+            panic!("Expected Summarise operation");
+        };
+        // .by는 집계가 아니라 이 요약에만 적용되는 그룹
+        assert_eq!(aggregations.len(), 1);
+        assert_eq!(by, &vec!["region".to_string(), "year".to_string()]);
+
+        for input in [
+            "summarise(n = n(), .by = region, .groups = \"drop\")",
+            "summarise(n = n(), .by = starts_with(\"r\"))",
+        ] {
+            let lexer = Lexer::new(input.to_string());
+            assert!(Parser::new(lexer).unwrap().parse().is_err(), "{input}");
+        }
+    }
 }
 
 // ===== 파이프라인 파싱 테스트 =====
@@ -3639,4 +4464,850 @@ mod pipeline_parsing_tests {
             }
         }
     }
+
+    #[test]
+    fn test_trailing_pipe_is_ignored_when_allowed() {
+        let code = "my_table %>% select(a) %>%\n";
+
+        // 기본값에서는 파이프 뒤에 동사가 없으면 오류
+        let mut parser = Parser::new(Lexer::new(code.to_string())).unwrap();
+        assert!(parser.parse().is_err());
+
+        let mut parser = Parser::new(Lexer::new(code.to_string()))
+            .unwrap()
+            .with_trailing_pipe(true);
+        match parser.parse().unwrap() {
+            DplyrNode::Pipeline {
+                source, operations, ..
+            } => {
+                assert_eq!(source.as_deref(), Some("my_table"));
+                assert_eq!(operations.len(), 1);
+                assert!(matches!(operations[0], DplyrOperation::Select { .. }));
+            }
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+
+        // 테이블 이름 바로 뒤의 파이프도 무시
+        let parse_allowing = |code: &str| {
+            let mut parser = Parser::new(Lexer::new(code.to_string())).unwrap();
+            assert!(parser.parse().is_err(), "{code}");
+            Parser::new(Lexer::new(code.to_string()))
+                .unwrap()
+                .with_trailing_pipe(true)
+                .parse()
+                .unwrap()
+        };
+        match parse_allowing("my_table %>%") {
+            DplyrNode::DataSource { name, .. } => assert_eq!(name, "my_table"),
+            other => panic!("Expected DataSource node, got {other:?}"),
+        }
+        match parse_allowing("out <- my_table %>%\n") {
+            DplyrNode::Pipeline {
+                source,
+                target,
+                operations,
+                ..
+            } => {
+                assert_eq!(source.as_deref(), Some("my_table"));
+                assert_eq!(target.as_deref(), Some("out"));
+                assert!(operations.is_empty());
+            }
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+
+        // 파이프 사이의 빈 단계는 여전히 오류
+        let mut parser = Parser::new(Lexer::new("my_table %>% %>% select(a)".to_string()))
+            .unwrap()
+            .with_trailing_pipe(true);
+        assert!(parser.parse().is_err());
+    }
+
+    #[test]
+    fn test_parenthesized_pipeline_source() {
+        let lexer = Lexer::new("(raw %>% filter(x > 0)) %>% summarise(n = n())".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        let DplyrNode::Pipeline {
+            source, operations, ..
+        } = parser.parse().unwrap()
+        else {
+            panic!("Expected Pipeline node");
+        };
+
+        // 괄호 안의 파이프라인이 첫 연산이 되고 그 소스 이름을 이어받는다
+        assert_eq!(source.as_deref(), Some("raw"));
+        assert_eq!(operations.len(), 2);
+        let DplyrOperation::Subquery { source: inner, .. } = &operations[0] else {
+            panic!("Expected Subquery operation, got {:?}", operations[0]);
+        };
+        assert!(matches!(
+            inner.as_ref(),
+            DplyrNode::Pipeline { source: Some(name), operations, .. }
+                if name == "raw" && matches!(operations[..], [DplyrOperation::Filter { .. }])
+        ));
+        assert!(matches!(operations[1], DplyrOperation::Summarise { .. }));
+
+        // 괄호로 감싼 테이블 이름은 그대로 소스
+        let lexer = Lexer::new("(raw)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        assert!(matches!(
+            parser.parse().unwrap(),
+            DplyrNode::DataSource { name, .. } if name == "raw"
+        ));
+
+        let lexer = Lexer::new("(raw %>% filter(x > 0)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        assert!(parser.parse().is_err());
+    }
+}
+
+// ===== bind_rows() / bind_cols() 파싱 테스트 =====
+
+mod binding_parsing_tests {
+    use super::*;
+
+    fn parse_operations(input: &str) -> Vec<DplyrOperation> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        match parser.parse().unwrap() {
+            DplyrNode::Pipeline { operations, .. } => operations,
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_bind_rows_with_table() {
+        let operations = parse_operations("a %>% bind_rows(b)");
+        assert_eq!(operations.len(), 1);
+        match &operations[0] {
+            DplyrOperation::BindRows { source, .. } => match source.as_ref() {
+                DplyrNode::DataSource { name, .. } => assert_eq!(name, "b"),
+                other => panic!("Expected DataSource, got {other:?}"),
+            },
+            other => panic!("Expected BindRows operation, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_bind_rows_with_nested_pipeline() {
+        let operations = parse_operations("a %>% bind_rows(b %>% filter(x > 1)) %>% arrange(x)");
+        assert_eq!(operations.len(), 2);
+        match &operations[0] {
+            DplyrOperation::BindRows { source, .. } => match source.as_ref() {
+                DplyrNode::Pipeline {
+                    source, operations, ..
+                } => {
+                    assert_eq!(source.as_deref(), Some("b"));
+                    assert_eq!(operations.len(), 1);
+                    assert!(matches!(operations[0], DplyrOperation::Filter { .. }));
+                }
+                other => panic!("Expected Pipeline, got {other:?}"),
+            },
+            other => panic!("Expected BindRows operation, got {other:?}"),
+        }
+        assert!(matches!(operations[1], DplyrOperation::Arrange { .. }));
+    }
+
+    #[test]
+    fn test_bind_cols_with_table() {
+        let operations = parse_operations("a %>% bind_cols(b)");
+        assert_eq!(operations.len(), 1);
+        assert!(matches!(
+            &operations[0],
+            DplyrOperation::BindCols { source, .. }
+                if matches!(source.as_ref(), DplyrNode::DataSource { name, .. } if name == "b")
+        ));
+    }
+
+    #[test]
+    fn test_bind_rows_requires_table() {
+        let lexer = Lexer::new("a %>% bind_rows(1)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        assert!(matches!(
+            parser.parse(),
+            Err(ParseError::UnexpectedToken { .. })
+        ));
+    }
+}
+
+// ===== slice_min() / slice_max() 파싱 테스트 =====
+
+mod slice_parsing_tests {
+    use super::*;
+
+    fn parse_single(input: &str) -> DplyrOperation {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        match parser.parse().unwrap() {
+            DplyrNode::Pipeline { mut operations, .. } => {
+                assert_eq!(operations.len(), 1);
+                operations.remove(0)
+            }
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+    }
+
+    fn assert_top_n(
+        op: DplyrOperation,
+        kind: TopNKind,
+        column: &str,
+        dir: OrderDirection,
+        n: usize,
+    ) {
+        match op {
+            DplyrOperation::TopN {
+                kind: actual_kind,
+                order_by,
+                n: actual_n,
+                ..
+            } => {
+                assert_eq!(actual_kind, kind);
+                assert_eq!(
+                    order_by,
+                    vec![OrderExpr {
+                        column: column.to_string(),
+                        direction: dir
+                    }]
+                );
+                assert_eq!(actual_n, n);
+            }
+            other => panic!("Expected TopN operation, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_slice_max_defaults_to_descending() {
+        let op = parse_single("t %>% slice_max(price, n = 3)");
+        assert_top_n(op, TopNKind::SliceMax, "price", OrderDirection::Desc, 3);
+    }
+
+    #[test]
+    fn test_slice_max_desc_wrapper_flips_direction() {
+        let op = parse_single("t %>% slice_max(order_by = desc(price), n = 3)");
+        assert_top_n(op, TopNKind::SliceMax, "price", OrderDirection::Asc, 3);
+    }
+
+    #[test]
+    fn test_slice_min_with_wrappers() {
+        let op = parse_single("t %>% slice_min(n = 2, order_by = desc(price))");
+        assert_top_n(op, TopNKind::SliceMin, "price", OrderDirection::Desc, 2);
+
+        let op = parse_single("t %>% slice_min(asc(price))");
+        assert_top_n(op, TopNKind::SliceMin, "price", OrderDirection::Asc, 1);
+    }
+
+    #[test]
+    fn test_top_n_sign_and_wrappers() {
+        let op = parse_single("t %>% top_n(3, price)");
+        assert_top_n(op, TopNKind::TopN, "price", OrderDirection::Desc, 3);
+
+        let op = parse_single("t %>% top_n(-3, price)");
+        assert_top_n(op, TopNKind::TopN, "price", OrderDirection::Asc, 3);
+
+        let op = parse_single("t %>% top_n(wt = asc(price), n = -2)");
+        assert_top_n(op, TopNKind::TopN, "price", OrderDirection::Asc, 2);
+
+        for input in ["t %>% top_n(3)", "t %>% top_n(1.5, price)"] {
+            let lexer = Lexer::new(input.to_string());
+            let mut parser = Parser::new(lexer).unwrap();
+            assert!(parser.parse().is_err(), "{input} should fail");
+        }
+    }
+
+    #[test]
+    fn test_top_n_vector_weights() {
+        let DplyrOperation::TopN { order_by, n, .. } =
+            parse_single("t %>% top_n(5, wt = c(sales, desc(returns)))")
+        else {
+            panic!("Expected TopN operation");
+        };
+        // 각 키가 뒤집히므로 desc()는 오름차순이 된다
+        assert_eq!(n, 5);
+        assert_eq!(
+            order_by,
+            vec![
+                OrderExpr {
+                    column: "sales".to_string(),
+                    direction: OrderDirection::Desc,
+                },
+                OrderExpr {
+                    column: "returns".to_string(),
+                    direction: OrderDirection::Asc,
+                },
+            ]
+        );
+    }
+
+    #[test]
+    fn test_slice_max_rejects_invalid_arguments() {
+        for input in [
+            "t %>% slice_max(n = 3)",
+            "t %>% slice_max(price, n = 1.5)",
+            "t %>% slice_max(price, prop = 0.1)",
+        ] {
+            let lexer = Lexer::new(input.to_string());
+            let mut parser = Parser::new(lexer).unwrap();
+            assert!(parser.parse().is_err(), "{input} should fail");
+        }
+    }
+
+    #[test]
+    fn test_head_positional_and_named_count() {
+        let positional = parse_single("t %>% head(10)");
+        let named = parse_single("t %>% head(n = 10)");
+        assert!(matches!(
+            &positional,
+            DplyrOperation::TopN { kind: TopNKind::Head, order_by, n: 10, .. } if order_by.is_empty()
+        ));
+        // 위치 인자와 이름 인자는 같은 노드가 된다 (위치 정보 제외)
+        let strip = |op: DplyrOperation| match op {
+            DplyrOperation::TopN {
+                kind, order_by, n, ..
+            } => (kind, order_by, n),
+            other => panic!("Expected TopN operation, got {other:?}"),
+        };
+        assert_eq!(strip(positional), strip(named));
+
+        // 기본값은 R과 같이 6행
+        assert!(matches!(
+            parse_single("t %>% head()"),
+            DplyrOperation::TopN { n: 6, .. }
+        ));
+
+        for input in ["t %>% head(-1)", "t %>% head(1, 2)", "t %>% head(rows = 1)"] {
+            let lexer = Lexer::new(input.to_string());
+            let mut parser = Parser::new(lexer).unwrap();
+            assert!(parser.parse().is_err(), "{input} should fail");
+        }
+    }
+}
+
+// ===== relocate() 파싱 테스트 =====
+
+mod relocate_parsing_tests {
+    use super::*;
+
+    fn parse_single(input: &str) -> DplyrOperation {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        match parser.parse().unwrap() {
+            DplyrNode::Pipeline { mut operations, .. } => operations.remove(0),
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_relocate_with_helper_and_after() {
+        let op = parse_single("t %>% relocate(starts_with(\"meta_\"), .after = id)");
+        if let DplyrOperation::Relocate {
+            columns, anchor, ..
+        } = op
+        {
+            assert_eq!(
+                columns,
+                vec![Expr::Function {
+                    name: "starts_with".to_string(),
+                    args: vec![Expr::Literal(LiteralValue::String("meta_".to_string()))],
+                }]
+            );
+            assert_eq!(
+                anchor,
+                Some(RelocateAnchor::After(Expr::Identifier("id".to_string())))
+            );
+        } else {
+            panic!("Expected Relocate operation");
+        }
+    }
+
+    #[test]
+    fn test_relocate_rejects_invalid_arguments() {
+        for input in [
+            "t %>% relocate()",
+            "t %>% relocate(a, .before = b, .after = c)",
+            "t %>% relocate(a, .to = b)",
+        ] {
+            let lexer = Lexer::new(input.to_string());
+            let mut parser = Parser::new(lexer).unwrap();
+            assert!(parser.parse().is_err(), "{input} should fail");
+        }
+    }
+}
+
+// ===== fill() 파싱 테스트 =====
+
+mod fill_parsing_tests {
+    use super::*;
+
+    fn parse_fill(input: &str) -> Result<(Vec<String>, FillDirection), ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        match parser.parse()? {
+            DplyrNode::Pipeline { operations, .. } => match &operations[0] {
+                DplyrOperation::Fill {
+                    columns, direction, ..
+                } => Ok((columns.clone(), *direction)),
+                other => panic!("Expected Fill operation, got {other:?}"),
+            },
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_fill_columns_and_direction() {
+        assert_eq!(
+            parse_fill("t %>% fill(a, b)").unwrap(),
+            (vec!["a".to_string(), "b".to_string()], FillDirection::Down)
+        );
+        assert_eq!(
+            parse_fill("t %>% fill(a, .direction = \"updown\")").unwrap(),
+            (vec!["a".to_string()], FillDirection::UpDown)
+        );
+        assert!(parse_fill("t %>% fill(a, .direction = \"sideways\")").is_err());
+        assert!(parse_fill("t %>% fill()").is_err());
+    }
+}
+
+// ===== distinct() 파싱 테스트 =====
+
+mod distinct_parsing_tests {
+    use super::*;
+
+    fn parse_distinct(input: &str) -> Result<(Vec<String>, bool), ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        match parser.parse()? {
+            DplyrNode::Pipeline { operations, .. } => match operations.last() {
+                Some(DplyrOperation::Distinct {
+                    columns, keep_all, ..
+                }) => Ok((columns.clone(), *keep_all)),
+                other => panic!("Expected Distinct operation, got {other:?}"),
+            },
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_distinct_columns() {
+        assert_eq!(
+            parse_distinct("t %>% select(a, b) %>% distinct()").unwrap(),
+            (Vec::<String>::new(), false)
+        );
+        assert_eq!(
+            parse_distinct("t %>% distinct(a, b)").unwrap(),
+            (vec!["a".to_string(), "b".to_string()], false)
+        );
+        // 지원하지 않는 인자는 거부
+        assert!(parse_distinct("t %>% distinct(a, .by = b)").is_err());
+    }
+
+    #[test]
+    fn test_distinct_keep_all() {
+        assert_eq!(
+            parse_distinct("t %>% distinct(a, .keep_all = TRUE)").unwrap(),
+            (vec!["a".to_string()], true)
+        );
+        assert_eq!(
+            parse_distinct("t %>% distinct(.keep_all = FALSE, a)").unwrap(),
+            (vec!["a".to_string()], false)
+        );
+        assert!(parse_distinct("t %>% distinct(a, .keep_all = 1)").is_err());
+    }
+}
+
+// ===== count() 파싱 테스트 =====
+
+mod count_parsing_tests {
+    use super::*;
+
+    fn parse_count(input: &str) -> Result<(Vec<String>, String, bool), ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        match parser.parse()? {
+            DplyrNode::Pipeline { operations, .. } => match &operations[0] {
+                DplyrOperation::Count {
+                    columns,
+                    name,
+                    sort,
+                    ..
+                } => Ok((columns.clone(), name.clone(), *sort)),
+                other => panic!("Expected Count operation, got {other:?}"),
+            },
+            other => panic!("Expected Pipeline node, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_count_columns_and_arguments() {
+        assert_eq!(
+            parse_count("t %>% count(region)").unwrap(),
+            (vec!["region".to_string()], "n".to_string(), false)
+        );
+        assert_eq!(
+            parse_count("t %>% count(region, year, sort = TRUE, name = \"cnt\")").unwrap(),
+            (
+                vec!["region".to_string(), "year".to_string()],
+                "cnt".to_string(),
+                true
+            )
+        );
+        assert_eq!(
+            parse_count("t %>% count()").unwrap(),
+            (Vec::new(), "n".to_string(), false)
+        );
+        assert!(parse_count("t %>% count(region, wt = amount, .drop = FALSE)").is_err());
+    }
+
+    #[test]
+    fn test_count_weight_argument() {
+        let lexer = Lexer::new("t %>% count(region, wt = sales)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        let DplyrNode::Pipeline { operations, .. } = parser.parse().unwrap() else {
+            panic!("Expected Pipeline node");
+        };
+        assert!(matches!(
+            &operations[0],
+            DplyrOperation::Count { columns, wt: Some(Expr::Identifier(wt)), .. }
+                if columns == &["region".to_string()] && wt == "sales"
+        ));
+    }
+
+    #[test]
+    fn test_count_remains_usable_as_column_name() {
+        // 연산 위치가 아니면 count는 일반 식별자
+        let lexer = Lexer::new("t %>% summarise(count = n()) %>% filter(count > 1)".to_string());
+        let mut parser = Parser::new(lexer).unwrap();
+        assert!(parser.parse().is_ok());
+    }
+}
+
+// ===== tribble() 파싱 테스트 =====
+
+mod tribble_parsing_tests {
+    use super::*;
+
+    fn parse(input: &str) -> Result<DplyrNode, ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer)?;
+        parser.parse()
+    }
+
+    #[test]
+    fn test_tribble_header_and_rows_start_the_pipeline() {
+        let ast = parse("tribble(~k, ~v, \"a\", 1, \"b\", 2) %>% filter(v > 1)").unwrap();
+        let DplyrNode::Pipeline {
+            source, operations, ..
+        } = ast
+        else {
+            panic!("Expected Pipeline node");
+        };
+        assert_eq!(source, None);
+        assert_eq!(operations.len(), 2);
+        let DplyrOperation::Tribble { columns, rows, .. } = &operations[0] else {
+            panic!("Expected Tribble operation, got {:?}", operations[0]);
+        };
+        assert_eq!(columns, &["k", "v"]);
+        assert_eq!(
+            rows,
+            &vec![
+                vec![
+                    LiteralValue::String("a".to_string()),
+                    LiteralValue::Number(1.0)
+                ],
+                vec![
+                    LiteralValue::String("b".to_string()),
+                    LiteralValue::Number(2.0)
+                ],
+            ]
+        );
+        assert!(matches!(operations[1], DplyrOperation::Filter { .. }));
+    }
+
+    #[test]
+    fn test_tribble_rejects_ragged_or_empty_rows() {
+        // 값의 개수가 열 개수의 배수가 아니면 오류
+        assert!(parse("tribble(~k, ~v, \"a\", 1, \"b\")").is_err());
+        assert!(parse("tribble(~k, ~v)").is_err());
+        assert!(parse("tribble(~k, \"a\", ~v, 1)").is_err());
+        assert!(parse("tribble(~k, x)").is_err());
+    }
+}
+
+// ===== rename_with() 파싱 테스트 =====
+
+mod rename_with_parsing_tests {
+    use super::*;
+
+    fn parse(input: &str) -> Result<DplyrNode, ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer)?;
+        parser.parse()
+    }
+
+    #[test]
+    fn test_rename_with_function_name_defaults_to_everything() {
+        let ast = parse("t %>% rename_with(toupper)").unwrap();
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::RenameWith {
+            function, columns, ..
+        } = &operations[0]
+        else {
+            panic!("Expected RenameWith operation, got {:?}", operations[0]);
+        };
+        // 함수 이름은 .x에 대한 호출로 바뀐다
+        assert_eq!(
+            function,
+            &Expr::Function {
+                name: "toupper".to_string(),
+                args: vec![Expr::Identifier(".x".to_string())],
+            }
+        );
+        assert_eq!(
+            columns,
+            &Expr::Function {
+                name: "everything".to_string(),
+                args: Vec::new(),
+            }
+        );
+    }
+
+    #[test]
+    fn test_rename_with_formula_and_columns() {
+        let ast = parse("t %>% rename_with(~ paste0(.x, \"_old\"), .cols = c(a, b))").unwrap();
+        let DplyrNode::Pipeline { operations, .. } = ast else {
+            panic!("Expected Pipeline node");
+        };
+        let DplyrOperation::RenameWith { columns, .. } = &operations[0] else {
+            panic!("Expected RenameWith operation, got {:?}", operations[0]);
+        };
+        assert_eq!(
+            columns,
+            &Expr::Vector(vec![
+                Expr::Identifier("a".to_string()),
+                Expr::Identifier("b".to_string()),
+            ])
+        );
+
+        // 함수가 없으면 오류
+        assert!(parse("t %>% rename_with(.cols = a)").is_err());
+    }
+}
+
+// ===== optimize() 테스트 =====
+mod optimize_tests {
+    use super::*;
+    use crate::parser::optimize;
+
+    fn optimized_operations(input: &str) -> Vec<DplyrOperation> {
+        let lexer = Lexer::new(input.to_string());
+        let ast = Parser::new(lexer).unwrap().parse().unwrap();
+        let DplyrNode::Pipeline { operations, .. } = optimize(ast) else {
+            panic!("Expected Pipeline node");
+        };
+        operations
+    }
+
+    fn column(name: &str, alias: Option<&str>) -> ColumnExpr {
+        ColumnExpr {
+            expr: Expr::Identifier(name.to_string()),
+            alias: alias.map(str::to_string),
+        }
+    }
+
+    #[test]
+    fn test_adjacent_filters_merge_into_and() {
+        let operations = optimized_operations("t %>% filter(a > 1) %>% filter(b > 2)");
+        assert_eq!(operations.len(), 1);
+        let DplyrOperation::Filter { condition, .. } = &operations[0] else {
+            panic!("Expected Filter operation, got {:?}", operations[0]);
+        };
+        let Expr::Binary { operator, .. } = condition else {
+            panic!("Expected binary condition, got {condition:?}");
+        };
+        assert_eq!(operator, &BinaryOp::And);
+    }
+
+    #[test]
+    fn test_filter_with_window_function_is_kept_separate() {
+        // row_number()는 앞선 필터를 통과한 행에 대해 계산되어야 한다
+        let operations = optimized_operations("t %>% filter(a > 1) %>% filter(row_number() == 1)");
+        assert_eq!(operations.len(), 2);
+    }
+
+    #[test]
+    fn test_chained_selects_narrow() {
+        let operations = optimized_operations("t %>% select(a, b) %>% select(a)");
+        assert_eq!(operations.len(), 1);
+        let DplyrOperation::Select { columns, .. } = &operations[0] else {
+            panic!("Expected Select operation, got {:?}", operations[0]);
+        };
+        assert_eq!(columns, &vec![column("a", None)]);
+
+        // 앞 단계의 별칭은 원래 열로 되돌아간다
+        let operations = optimized_operations("t %>% select(x = a, b) %>% select(b, y = x)");
+        let DplyrOperation::Select { columns, .. } = &operations[0] else {
+            panic!("Expected Select operation, got {:?}", operations[0]);
+        };
+        assert_eq!(columns, &vec![column("b", None), column("a", Some("y"))]);
+    }
+
+    #[test]
+    fn test_selects_with_helpers_are_kept_separate() {
+        let operations = optimized_operations("t %>% select(starts_with(\"a\")) %>% select(ab)");
+        assert_eq!(operations.len(), 2);
+    }
+}
+
+// ===== slice_sample() 파싱 테스트 =====
+
+mod slice_sample_parsing_tests {
+    use super::*;
+
+    fn parse(input: &str) -> Result<DplyrNode, ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer)?;
+        parser.parse()
+    }
+
+    fn sample_of(input: &str) -> (SampleSize, Option<Expr>) {
+        let DplyrNode::Pipeline { operations, .. } = parse(input).unwrap() else {
+            panic!("Expected Pipeline node");
+        };
+        match &operations[0] {
+            DplyrOperation::SliceSample {
+                size, weight_by, ..
+            } => (*size, weight_by.clone()),
+            other => panic!("Expected SliceSample operation, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_slice_sample_n_and_prop() {
+        assert_eq!(
+            sample_of("t %>% slice_sample(n = 50)"),
+            (SampleSize::Rows(50), None)
+        );
+        assert_eq!(
+            sample_of("t %>% slice_sample(prop = 0.1, replace = FALSE)"),
+            (SampleSize::Fraction(0.1), None)
+        );
+        // 기본값은 한 행
+        assert_eq!(
+            sample_of("t %>% slice_sample()"),
+            (SampleSize::Rows(1), None)
+        );
+        assert_eq!(
+            sample_of("t %>% slice_sample(n = 3, weight_by = w)"),
+            (SampleSize::Rows(3), Some(Expr::Identifier("w".to_string())))
+        );
+    }
+
+    #[test]
+    fn test_slice_sample_rejects_invalid_arguments() {
+        for input in [
+            "t %>% slice_sample(5)",
+            "t %>% slice_sample(n = 5, prop = 0.5)",
+            "t %>% slice_sample(prop = 1.5)",
+            "t %>% slice_sample(n = 5, replace = TRUE)",
+            "t %>% slice_sample(size = 5)",
+        ] {
+            assert!(parse(input).is_err(), "{input} should fail");
+        }
+    }
+}
+
+// ===== pull() 파싱 테스트 =====
+
+mod pull_parsing_tests {
+    use super::*;
+
+    fn parse(input: &str) -> Result<DplyrNode, ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer)?;
+        parser.parse()
+    }
+
+    fn pulled_columns(input: &str) -> Vec<Expr> {
+        let DplyrNode::Pipeline { operations, .. } = parse(input).unwrap() else {
+            panic!("Expected Pipeline node");
+        };
+        match &operations[0] {
+            DplyrOperation::Select { columns, .. } => {
+                columns.iter().map(|column| column.expr.clone()).collect()
+            }
+            other => panic!("Expected Select operation, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_named_pull_selects_name_then_value() {
+        let expected = vec![
+            Expr::Identifier("key".to_string()),
+            Expr::Identifier("value".to_string()),
+        ];
+        assert_eq!(pulled_columns("t %>% pull(value, name = key)"), expected);
+        assert_eq!(
+            pulled_columns("t %>% pull(name = key, var = value)"),
+            expected
+        );
+        assert_eq!(
+            pulled_columns("t %>% pull(value)"),
+            vec![Expr::Identifier("value".to_string())]
+        );
+    }
+
+    #[test]
+    fn test_pull_rejects_invalid_arguments() {
+        for input in ["t %>% pull(a, b)", "t %>% pull(value, names = key)"] {
+            assert!(parse(input).is_err(), "{input} should fail");
+        }
+    }
+}
+
+// ===== slice() 파싱 테스트 =====
+
+mod slice_position_parsing_tests {
+    use super::*;
+
+    fn parse(input: &str) -> Result<DplyrNode, ParseError> {
+        let lexer = Lexer::new(input.to_string());
+        let mut parser = Parser::new(lexer)?;
+        parser.parse()
+    }
+
+    fn slice_of(input: &str) -> (usize, Option<usize>) {
+        let DplyrNode::Pipeline { operations, .. } = parse(input).unwrap() else {
+            panic!("Expected Pipeline node");
+        };
+        match &operations[0] {
+            DplyrOperation::Slice { offset, limit, .. } => (*offset, *limit),
+            other => panic!("Expected Slice operation, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_slice_positions_resolve_to_range() {
+        assert_eq!(slice_of("t %>% slice(1)"), (0, Some(1)));
+        assert_eq!(slice_of("t %>% slice(2:4)"), (1, Some(3)));
+        assert_eq!(slice_of("t %>% slice(2, 3)"), (1, Some(2)));
+        // 음수 위치는 앞쪽 행을 버린다
+        assert_eq!(slice_of("t %>% slice(-1)"), (1, None));
+        assert_eq!(slice_of("t %>% slice(-(1:3))"), (3, None));
+        assert_eq!(slice_of("t %>% slice(-1:-2, -3)"), (3, None));
+    }
+
+    #[test]
+    fn test_slice_rejects_unrenderable_positions() {
+        for input in [
+            "t %>% slice()",
+            "t %>% slice(0)",
+            "t %>% slice(1, 3)",
+            "t %>% slice(3:1)",
+            "t %>% slice(-2)",
+            "t %>% slice(1, -2)",
+            "t %>% slice(x)",
+        ] {
+            assert!(parse(input).is_err(), "{input} should fail");
+        }
+    }
 }
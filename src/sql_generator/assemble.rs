@@ -2,24 +2,159 @@
 
 use std::collections::HashMap;
 
-use super::{DplyrOperation, GenerationResult, SqlGenerator};
+use super::comment_support::CommentClause;
+use super::{
+    DplyrNode, DplyrOperation, GenerationError, GenerationResult, GroupingSets, SqlGenerator,
+};
 
 /// Struct to store SQL query components
-#[derive(Debug, Default)]
+#[derive(Debug, Default, Clone)]
 pub(super) struct QueryParts {
     pub(super) select_columns: Vec<String>,
-    pub(super) where_clauses: Vec<String>,
+    /// Conditions combined with AND (one per filter())
+    pub(super) where_clauses: Vec<WhereCondition>,
     pub(super) group_by: String,
+    /// Unquoted column names of the current group_by()
+    pub(super) group_columns: Vec<String>,
+    /// `rollup()`/`cube()`/`groupingsets()` of the current group_by()
+    pub(super) grouping_sets: Option<GroupingSets>,
     pub(super) order_by: String,
+    /// Columns of the arrange() keys behind `order_by`
+    pub(super) order_columns: Vec<String>,
+    /// arrange() keys consumed by the window functions of a grouped mutate:
+    /// they still order later windows but not the result rows
+    pub(super) window_order_by: String,
     pub(super) joins: Vec<String>,
     pub(super) mutated_columns: HashMap<String, String>,
+    /// Names of mutated columns in creation order
+    pub(super) mutated_order: Vec<String>,
+    /// Output column names once a verb narrowed, reordered or renamed the
+    /// projection (select(), relocate(), rename(), summarise(), ...); `None`
+    /// while it is every source column followed by `mutated_order`
+    pub(super) columns: Option<Vec<String>>,
     pub(super) set_operation: Option<(String, String)>, // (operation, right_table)
+    /// Columns dropped from an implicit `*` projection (rename under `no_select_star`)
+    pub(super) star_exclusions: Vec<String>,
+    /// Tables whose columns join the implicit `*` projection
+    pub(super) joined_tables: Vec<String>,
+    /// GROUP BY in effect at the last summarise (None until a summarise is seen)
+    pub(super) aggregation_group_by: Option<String>,
+    /// Query used as the FROM source instead of the base table (e.g. after bind_rows)
+    pub(super) from_subquery: Option<String>,
+    /// Row limit (slice_min/slice_max)
+    pub(super) limit: Option<usize>,
+    /// Rows skipped before the limit (slice())
+    pub(super) offset: Option<usize>,
+    /// `SELECT DISTINCT` over the projection (distinct())
+    pub(super) distinct: bool,
+    /// Quoted keys of `SELECT DISTINCT ON (...)` (`distinct(.keep_all =
+    /// TRUE)`); every ORDER BY must start with them
+    pub(super) distinct_on: String,
+    /// Source comments to emit above their clause (preserve_comments)
+    pub(super) comments: Vec<(CommentClause, String)>,
+}
+
+/// One condition of the WHERE clause.
+#[derive(Debug, Clone)]
+pub(super) struct WhereCondition {
+    pub(super) sql: String,
+    /// The SQL is one parenthesized term, as every comparison and logical
+    /// operation is rendered (`("a" > 1)`), and can be combined with AND
+    /// as is
+    pub(super) enclosed: bool,
+}
+
+impl WhereCondition {
+    /// A condition that is parenthesized when combined with others, e.g.
+    /// `"rn" = 1`.
+    pub(super) fn new(sql: String) -> Self {
+        Self {
+            sql,
+            enclosed: false,
+        }
+    }
+
+    /// A condition already rendered as one parenthesized term.
+    pub(super) fn enclosed(sql: String) -> Self {
+        Self {
+            sql,
+            enclosed: true,
+        }
+    }
+}
+
+/// Rendered clauses of one SELECT, without their keywords.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct ClauseFragments {
+    /// Projection, including a leading `DISTINCT`
+    pub select: String,
+    /// Table or derived table the query reads from
+    pub from: String,
+    /// Complete JOIN clauses, in order
+    pub joins: Vec<String>,
+    /// WHERE conditions, combined with AND; each one is parenthesized
+    /// when there are several
+    pub where_conditions: Vec<String>,
+    /// GROUP BY list, or empty
+    pub group_by: String,
+    /// ORDER BY list, or empty
+    pub order_by: String,
+    /// Row limit clause (`LIMIT 10`, `LIMIT 10 OFFSET 5`), if any
+    pub limit: Option<String>,
+    /// Trailing set operation (`UNION SELECT * FROM "t2"`), if any
+    pub set_operation: Option<String>,
 }
 
 impl QueryParts {
     pub(super) fn new() -> Self {
         Self::default()
     }
+
+    /// Starts a fresh set of parts reading from `subquery`.
+    pub(super) fn from_subquery(subquery: String) -> Self {
+        Self {
+            from_subquery: Some(subquery),
+            ..Self::default()
+        }
+    }
+
+    /// Keeps GROUP BY only when a summarise consumed it; a trailing
+    /// group_by() without summarise does not change the result rows.
+    pub(super) fn finalize_group_by(&mut self) {
+        self.group_by = self.aggregation_group_by.clone().unwrap_or_default();
+    }
+
+    /// WHERE conditions that render to something; empty fragments are
+    /// skipped so they cannot leave stray `AND`s or spaces behind.
+    pub(super) fn where_conditions(&self) -> impl Iterator<Item = &WhereCondition> {
+        self.where_clauses
+            .iter()
+            .filter(|condition| !condition.sql.trim().is_empty())
+    }
+
+    /// True when nothing has been recorded yet (a plain `SELECT * FROM table`).
+    pub(super) fn is_bare_table(&self) -> bool {
+        self.from_subquery.is_none() && self.is_passthrough()
+    }
+
+    /// True when the parts add nothing on top of `from_subquery`.
+    fn is_passthrough(&self) -> bool {
+        self.select_columns.is_empty()
+            && self.where_conditions().next().is_none()
+            && self.group_by.is_empty()
+            && self.order_by.is_empty()
+            && self.joins.is_empty()
+            && self.set_operation.is_none()
+            && self.limit.is_none()
+            && self.offset.is_none()
+            && !self.distinct
+            && self.distinct_on.is_empty()
+    }
+
+    /// True while a group_by() is pending, i.e. not yet consumed by summarise.
+    pub(super) fn is_grouped(&self) -> bool {
+        !self.group_by.is_empty() && self.aggregation_group_by.is_none()
+    }
 }
 
 impl SqlGenerator {
@@ -46,6 +181,71 @@ impl SqlGenerator {
         self.assemble_query(&None, &nested_parts)
     }
 
+    /// Renders a nested source (table or pipeline) as a standalone SELECT.
+    pub(super) fn generate_nested_source(&self, node: &DplyrNode) -> GenerationResult<String> {
+        match node {
+            DplyrNode::DataSource { name, .. } => self.select_all_from(name),
+            DplyrNode::Pipeline {
+                source, operations, ..
+            } => {
+                let source_table = source.as_deref().unwrap_or("data");
+                let parts = self.build_query_parts(source_table, operations)?;
+                self.assemble_query(source, &parts)
+            }
+        }
+    }
+
+    /// Turns the parts built so far into a derived table, so that following
+    /// operations apply to its result rows (e.g. a filter after a LIMIT).
+    pub(super) fn wrap_in_subquery(
+        &self,
+        source_table: &str,
+        parts: &mut QueryParts,
+    ) -> GenerationResult<()> {
+        let subquery = self.assemble_current(source_table, parts)?;
+        // The derived table exposes the projection's columns by name.
+        let columns = self.known_columns(parts, source_table);
+        *parts = QueryParts::from_subquery(subquery);
+        parts.columns = columns;
+        Ok(())
+    }
+
+    /// Like `wrap_in_subquery`, but the ordering and a pending grouping stay
+    /// in effect for the operations reading from the derived table.
+    pub(super) fn wrap_in_subquery_keeping_order(
+        &self,
+        source_table: &str,
+        parts: &mut QueryParts,
+    ) -> GenerationResult<()> {
+        let order_by = parts.order_by.clone();
+        let order_columns = parts.order_columns.clone();
+        let window_order_by = parts.window_order_by.clone();
+        let grouping = parts
+            .is_grouped()
+            .then(|| (parts.group_by.clone(), parts.group_columns.clone()));
+        self.wrap_in_subquery(source_table, parts)?;
+        parts.order_by = order_by;
+        parts.order_columns = order_columns;
+        parts.window_order_by = window_order_by;
+        if let Some((group_by, group_columns)) = grouping {
+            parts.group_by = group_by;
+            parts.group_columns = group_columns;
+        }
+        Ok(())
+    }
+
+    /// Assembles the parts built so far as a standalone query, as if the
+    /// pipeline ended here.
+    pub(super) fn assemble_current(
+        &self,
+        source_table: &str,
+        parts: &QueryParts,
+    ) -> GenerationResult<String> {
+        let mut finished = parts.clone();
+        finished.finalize_group_by();
+        self.assemble_query(&Some(source_table.to_string()), &finished)
+    }
+
     /// Assembles the final SQL query.
     pub(super) fn assemble_query(
         &self,
@@ -54,51 +254,244 @@ impl SqlGenerator {
     ) -> GenerationResult<String> {
         let mut query = String::new();
 
+        let table_name = source.as_deref().unwrap_or("data");
+
+        if let Some(subquery) = &parts.from_subquery {
+            if parts.is_passthrough() {
+                self.push_clause_comments(&mut query, parts, CommentClause::Select);
+                query.push_str(subquery);
+                return Ok(query);
+            }
+        }
+
+        let clauses = self.clause_fragments(table_name, parts)?;
+
         // SELECT clause
+        self.push_clause_comments(&mut query, parts, CommentClause::Select);
         query.push_str("SELECT ");
-        if parts.select_columns.is_empty() {
-            query.push('*');
-        } else {
-            query.push_str(&parts.select_columns.join(", "));
-        }
+        query.push_str(&clauses.select);
 
         // FROM clause (using default table name)
         query.push_str("\nFROM ");
-        let table_name = source.as_deref().unwrap_or("data");
-        query.push_str(&self.dialect.quote_identifier(table_name));
+        query.push_str(&clauses.from);
 
         // JOIN clauses
-        for join in &parts.joins {
+        self.push_clause_comments(&mut query, parts, CommentClause::Join);
+        for join in &clauses.joins {
             query.push('\n');
             query.push_str(join);
         }
 
         // WHERE clause
-        if !parts.where_clauses.is_empty() {
+        if !clauses.where_conditions.is_empty() {
+            self.push_clause_comments(&mut query, parts, CommentClause::Where);
             query.push_str("\nWHERE ");
-            query.push_str(&parts.where_clauses.join(" "));
+            query.push_str(&clauses.where_conditions.join(" AND "));
         }
 
         // GROUP BY clause
-        if !parts.group_by.is_empty() {
+        if !clauses.group_by.is_empty() {
+            self.push_clause_comments(&mut query, parts, CommentClause::GroupBy);
             query.push_str("\nGROUP BY ");
-            query.push_str(&parts.group_by);
+            query.push_str(&clauses.group_by);
         }
 
         // ORDER BY clause
-        if !parts.order_by.is_empty() {
+        if !clauses.order_by.is_empty() {
+            self.push_clause_comments(&mut query, parts, CommentClause::OrderBy);
             query.push_str("\nORDER BY ");
-            query.push_str(&parts.order_by);
+            query.push_str(&clauses.order_by);
+        }
+
+        // LIMIT clause
+        if let Some(limit) = &clauses.limit {
+            self.push_clause_comments(&mut query, parts, CommentClause::Limit);
+            query.push('\n');
+            query.push_str(limit);
         }
 
         // Set operation (INTERSECT, UNION, EXCEPT)
-        if let Some((op, right_table)) = &parts.set_operation {
-            query.push_str(&format!(
-                "\n{op} SELECT * FROM {}",
-                self.dialect.quote_identifier(right_table)
-            ));
+        if let Some(set_operation) = &clauses.set_operation {
+            query.push('\n');
+            query.push_str(set_operation);
         }
 
         Ok(query)
     }
+
+    /// Renders the clauses of the query described by `parts`, reading from
+    /// `table_name`.
+    pub(super) fn clause_fragments(
+        &self,
+        table_name: &str,
+        parts: &QueryParts,
+    ) -> GenerationResult<ClauseFragments> {
+        let mut select = String::new();
+        if parts.distinct {
+            select.push_str("DISTINCT ");
+        }
+        if !parts.distinct_on.is_empty() {
+            select.push_str(&format!("DISTINCT ON ({}) ", parts.distinct_on));
+        }
+        select.push_str(&self.select_list(table_name, parts)?);
+
+        let from = match &parts.from_subquery {
+            // The derived table keeps the source name so qualified references still resolve.
+            Some(subquery) => format!(
+                "({subquery}) AS {}",
+                self.dialect.quote_identifier(table_name)
+            ),
+            None => self.dialect.quote_identifier(table_name),
+        };
+
+        let limit = match (parts.limit, parts.offset) {
+            (limit, Some(offset)) => Some(self.dialect.offset_clause(offset, limit)),
+            (Some(limit), None) => Some(self.dialect.limit_clause(limit)),
+            (None, None) => None,
+        };
+        let order_by = if parts.order_by.is_empty()
+            && limit.is_some()
+            && self.dialect.limit_requires_order_by()
+        {
+            // The limit keeps whichever rows come first, so any order will
+            // do; `(SELECT NULL)` asks for none in particular.
+            "(SELECT NULL)".to_string()
+        } else {
+            parts.order_by.clone()
+        };
+
+        let set_operation = match &parts.set_operation {
+            Some((op, right_table)) => Some(format!("{op} {}", self.select_all_from(right_table)?)),
+            None => None,
+        };
+
+        Ok(ClauseFragments {
+            select,
+            from,
+            joins: non_empty(&parts.joins).map(str::to_string).collect(),
+            where_conditions: where_conditions(parts),
+            group_by: parts.group_by.clone(),
+            order_by,
+            limit,
+            set_operation,
+        })
+    }
+
+    /// Renders `SELECT * FROM table`, expanding the star when `no_select_star` is set.
+    pub(super) fn select_all_from(&self, table: &str) -> GenerationResult<String> {
+        let projection = if self.options.no_select_star {
+            self.expand_star(table, &[], false)?.join(", ")
+        } else {
+            "*".to_string()
+        };
+        Ok(format!(
+            "SELECT {projection} FROM {}",
+            self.dialect.quote_identifier(table)
+        ))
+    }
+
+    /// Renders the projection list of the main query.
+    ///
+    /// With `no_select_star`, every implicit `*` item is replaced by the
+    /// schema columns of the source table (and of joined tables).
+    fn select_list(&self, table: &str, parts: &QueryParts) -> GenerationResult<String> {
+        let mut items: Vec<&str> = non_empty(&parts.select_columns).collect();
+        if items.is_empty() {
+            items.push("*");
+        }
+
+        if !self.options.no_select_star {
+            return Ok(self.join_select_items(table, parts, &items));
+        }
+
+        let mut expanded = Vec::with_capacity(items.len());
+        for item in items {
+            if item != "*" {
+                expanded.push(item.to_string());
+                continue;
+            }
+            let qualify = !parts.joined_tables.is_empty();
+            expanded.extend(self.expand_star(table, &parts.star_exclusions, qualify)?);
+            for joined in &parts.joined_tables {
+                expanded.extend(self.expand_star(joined, &[], true)?);
+            }
+        }
+        let expanded: Vec<&str> = expanded.iter().map(String::as_str).collect();
+        Ok(self.join_select_items(table, parts, &expanded))
+    }
+
+    /// Joins the projection; with `emit_column_comments`, one item per line,
+    /// each described column followed by its `--` comment.
+    fn join_select_items(&self, table: &str, parts: &QueryParts, items: &[&str]) -> String {
+        if !self.options.emit_column_comments {
+            return items.join(", ");
+        }
+        let last = items.len().saturating_sub(1);
+        items
+            .iter()
+            .enumerate()
+            .map(|(index, item)| {
+                let separator = if index < last { "," } else { "" };
+                match self.column_comment(item, table, parts) {
+                    Some(comment) => format!("{item}{separator} {comment}"),
+                    None => format!("{item}{separator}"),
+                }
+            })
+            .collect::<Vec<_>>()
+            .join("\n  ")
+    }
+
+    /// Expands `*` for `table` using the configured schema.
+    fn expand_star(
+        &self,
+        table: &str,
+        excluded: &[String],
+        qualify: bool,
+    ) -> GenerationResult<Vec<String>> {
+        let columns =
+            self.options
+                .table_columns(table)
+                .ok_or_else(|| GenerationError::SchemaRequired {
+                    operation: "SELECT * expansion (no_select_star)".to_string(),
+                    table: table.to_string(),
+                })?;
+
+        Ok(columns
+            .into_iter()
+            .filter(|col| !excluded.iter().any(|ex| ex == col))
+            .map(|col| {
+                if qualify {
+                    self.dialect.quote_identifier_path(&[table, col])
+                } else {
+                    self.dialect.quote_identifier(col)
+                }
+            })
+            .collect())
+    }
+}
+
+/// Skips fragments that render to nothing (e.g. an empty projection item).
+fn non_empty(fragments: &[String]) -> impl Iterator<Item = &str> {
+    fragments
+        .iter()
+        .map(String::as_str)
+        .filter(|fragment| !fragment.trim().is_empty())
+}
+
+/// The WHERE conditions of `parts`; when there are several, each one is
+/// parenthesized unless already enclosed, so that an OR in any of them stays
+/// grouped: `WHERE ("a" OR "b") AND ("c")`.
+fn where_conditions(parts: &QueryParts) -> Vec<String> {
+    let conditions: Vec<&WhereCondition> = parts.where_conditions().collect();
+    let several = conditions.len() > 1;
+    conditions
+        .into_iter()
+        .map(|condition| {
+            if several && !condition.enclosed {
+                format!("({})", condition.sql)
+            } else {
+                condition.sql.clone()
+            }
+        })
+        .collect()
 }
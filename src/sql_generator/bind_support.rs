@@ -0,0 +1,146 @@
+// Row/column binding helpers (bind_rows, bind_cols).
+
+use super::assemble::QueryParts;
+use super::{DplyrNode, GenerationError, GenerationResult, SqlGenerator};
+
+/// Helper column carrying the row position for bind_cols().
+const BIND_COLS_ROW_ID: &str = "__row_id";
+
+impl SqlGenerator {
+    /// Processes `bind_rows(other)`: the pipeline so far and `other` are
+    /// combined with `UNION ALL`, and later operations read from the result.
+    ///
+    /// When the schema knows the columns of both sides and they differ, each
+    /// side selects the union of the columns (in left-then-right order) and
+    /// pads the missing ones with `NULL`, mirroring dplyr's fill behaviour.
+    pub(super) fn process_bind_rows_operation(
+        &self,
+        other: &DplyrNode,
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        let mut left_parts = query_parts.clone();
+        let mut right_sql = self.generate_nested_source(other)?;
+
+        if let Some((left_columns, right_table, right_columns)) =
+            self.bind_rows_known_columns(&left_parts, source_table, other)
+        {
+            if left_columns != right_columns {
+                let mut all_columns = left_columns.clone();
+                for col in &right_columns {
+                    if !all_columns.contains(col) {
+                        all_columns.push(col.clone());
+                    }
+                }
+
+                left_parts.select_columns = self.padded_projection(&all_columns, &left_columns);
+                right_sql = format!(
+                    "SELECT {} FROM {}",
+                    self.padded_projection(&all_columns, &right_columns)
+                        .join(", "),
+                    self.dialect.quote_identifier(right_table)
+                );
+            }
+        }
+
+        let left_sql = self.assemble_current(source_table, &left_parts)?;
+        *query_parts = QueryParts::from_subquery(format!("{left_sql}\nUNION ALL\n{right_sql}"));
+        Ok(())
+    }
+
+    /// Processes `bind_cols(other)` as a positional join.
+    ///
+    /// SQL has no row positions, so both inputs are numbered with
+    /// `ROW_NUMBER() OVER ()` and joined on that key, which is then dropped
+    /// with `* EXCLUDE`. This is best effort: without an explicit ordering the
+    /// engine decides the numbering, which in practice follows the scan order
+    /// for plain tables (DuckDB preserves insertion order). Dialects without a
+    /// star-exclude projection report `bind_cols` as unsupported.
+    pub(super) fn process_bind_cols_operation(
+        &self,
+        other: &DplyrNode,
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        self.ensure_exact_rendering(
+            "bind_cols",
+            "relies on the engine's row numbering without an explicit order",
+        )?;
+        let row_id = self.dialect.quote_identifier(BIND_COLS_ROW_ID);
+        let projection = self
+            .dialect
+            .select_star_exclude(&[BIND_COLS_ROW_ID.to_string()])
+            .ok_or_else(|| GenerationError::UnsupportedOperation {
+                operation: "bind_cols".to_string(),
+                dialect: self.dialect.dialect_name().to_string(),
+            })?;
+
+        let left_from = if query_parts.is_bare_table() {
+            self.dialect.quote_identifier(source_table)
+        } else {
+            format!(
+                "({}) AS {}",
+                self.assemble_current(source_table, query_parts)?,
+                self.dialect.quote_identifier(source_table)
+            )
+        };
+        let right_from = match other {
+            DplyrNode::DataSource { name, .. } => self.dialect.quote_identifier(name),
+            DplyrNode::Pipeline { source, .. } => format!(
+                "({}) AS {}",
+                self.generate_nested_source(other)?,
+                self.dialect
+                    .quote_identifier(source.as_deref().unwrap_or("data"))
+            ),
+        };
+        let row_number = self.column_alias("ROW_NUMBER() OVER ()", BIND_COLS_ROW_ID);
+        let numbered = |from: String| format!("(SELECT *, {row_number} FROM {from})");
+        let left_sql = numbered(left_from);
+        let right_sql = numbered(right_from);
+
+        *query_parts = QueryParts::from_subquery(format!(
+            "SELECT {projection}\nFROM {left_sql} AS {}\nINNER JOIN {right_sql} AS {} USING ({row_id})",
+            self.dialect.quote_identifier("lhs"),
+            self.dialect.quote_identifier("rhs"),
+        ));
+        Ok(())
+    }
+
+    /// Returns the column lists of both bind_rows inputs when the schema
+    /// describes them (plain tables without a projection only).
+    fn bind_rows_known_columns<'a>(
+        &self,
+        left_parts: &QueryParts,
+        source_table: &str,
+        other: &'a DplyrNode,
+    ) -> Option<(Vec<String>, &'a str, Vec<String>)> {
+        let left_is_plain = left_parts.select_columns.is_empty()
+            && left_parts.joins.is_empty()
+            && left_parts.from_subquery.is_none();
+        let DplyrNode::DataSource { name, .. } = other else {
+            return None;
+        };
+        if !left_is_plain {
+            return None;
+        }
+
+        let owned = |cols: Vec<&str>| cols.into_iter().map(str::to_string).collect::<Vec<_>>();
+        let left_columns = owned(self.options.table_columns(source_table)?);
+        let right_columns = owned(self.options.table_columns(name)?);
+        Some((left_columns, name, right_columns))
+    }
+
+    /// Projects `all_columns`, substituting `NULL` for columns not in `present`.
+    fn padded_projection(&self, all_columns: &[String], present: &[String]) -> Vec<String> {
+        all_columns
+            .iter()
+            .map(|col| {
+                if present.contains(col) {
+                    self.dialect.quote_identifier(col)
+                } else {
+                    self.column_alias("NULL", col)
+                }
+            })
+            .collect()
+    }
+}
@@ -0,0 +1,175 @@
+// Comment placement (TranspileOptions::preserve_comments and
+// TranspileOptions::emit_column_comments).
+
+use super::assemble::QueryParts;
+use super::{DplyrNode, DplyrOperation, GenerationResult, JoinType, SqlGenerator};
+use crate::lexer::SourceComment;
+use crate::parser::SourceLocation;
+
+/// Clause a `--` comment is emitted above.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub(super) enum CommentClause {
+    Select,
+    Join,
+    Where,
+    GroupBy,
+    OrderBy,
+    Limit,
+}
+
+impl CommentClause {
+    /// Clause rendered for `operation`.
+    fn of(operation: &DplyrOperation) -> Self {
+        match operation {
+            DplyrOperation::Filter { .. } => Self::Where,
+            DplyrOperation::Arrange { .. } => Self::OrderBy,
+            DplyrOperation::GroupBy { .. } => Self::GroupBy,
+            DplyrOperation::Join { join_type, .. } => match join_type {
+                // Semi/anti joins render as WHERE (NOT) EXISTS.
+                JoinType::Semi | JoinType::Anti => Self::Where,
+                _ => Self::Join,
+            },
+            DplyrOperation::TopN { .. } | DplyrOperation::Slice { .. } => Self::Limit,
+            _ => Self::Select,
+        }
+    }
+
+    /// True when `parts` renders this clause; comments of missing clauses
+    /// (e.g. a group_by() without summarise) move up to the SELECT.
+    pub(super) fn is_rendered(self, parts: &QueryParts) -> bool {
+        match self {
+            Self::Select => true,
+            Self::Join => !parts.joins.is_empty(),
+            Self::Where => parts.where_conditions().next().is_some(),
+            Self::GroupBy => !parts.group_by.is_empty(),
+            Self::OrderBy => !parts.order_by.is_empty(),
+            Self::Limit => parts.limit.is_some() || parts.offset.is_some(),
+        }
+    }
+}
+
+impl SqlGenerator {
+    /// Converts AST to SQL, emitting `comments` as `--` comments above the
+    /// clause of the operation they belong to.
+    ///
+    /// A comment belongs to the first operation starting on its line or
+    /// below it; comments after the last operation belong to that operation.
+    pub fn generate_with_comments(
+        &self,
+        ast: &DplyrNode,
+        comments: &[SourceComment],
+    ) -> GenerationResult<String> {
+        let mut locations = Vec::new();
+        collect_operation_locations(ast, &mut locations);
+        locations.sort_by_key(|location| (location.line, location.column));
+
+        let mut attached = Vec::new();
+        for comment in comments {
+            let owner = locations
+                .iter()
+                .find(|location| location.line >= comment.line)
+                .or_else(|| locations.last());
+            if let Some(owner) = owner {
+                attached.push((owner.clone(), comment.text.clone()));
+            }
+        }
+
+        let generator = Self {
+            dialect: self.dialect.clone_box(),
+            options: self.options.clone(),
+            comments: attached,
+        };
+        generator.generate(ast)
+    }
+
+    /// Records the comments of `operation` for the clause it renders.
+    pub(super) fn record_operation_comments(
+        &self,
+        operation: &DplyrOperation,
+        parts: &mut QueryParts,
+    ) {
+        let clause = CommentClause::of(operation);
+        for (location, text) in &self.comments {
+            if location == operation.location() {
+                parts.comments.push((clause, text.clone()));
+            }
+        }
+    }
+
+    /// Appends the `--` lines of `clause` to `query`; the SELECT clause also
+    /// takes the comments of clauses that are not rendered.
+    pub(super) fn push_clause_comments(
+        &self,
+        query: &mut String,
+        parts: &QueryParts,
+        clause: CommentClause,
+    ) {
+        for (owner, text) in &parts.comments {
+            let target = if owner.is_rendered(parts) {
+                *owner
+            } else {
+                CommentClause::Select
+            };
+            if target != clause {
+                continue;
+            }
+            // A bare `#` renders as `--` without a dangling space.
+            let line = format!("-- {text}");
+            let line = line.trim_end();
+            if query.is_empty() {
+                query.push_str(line);
+                query.push('\n');
+            } else {
+                query.push('\n');
+                query.push_str(line);
+            }
+        }
+    }
+}
+
+impl SqlGenerator {
+    /// The `-- description` of the schema column that the SELECT item
+    /// `item` projects as is (`"c"`, `"t"."c"`) or renamed (`"c" AS "d"`),
+    /// reading from `table` or one of the joined tables.
+    pub(super) fn column_comment(
+        &self,
+        item: &str,
+        table: &str,
+        parts: &QueryParts,
+    ) -> Option<String> {
+        let projects = |reference: String| {
+            item == reference || item.starts_with(&format!("{reference}{}", self.alias_separator()))
+        };
+        let description = std::iter::once(table)
+            .chain(parts.joined_tables.iter().map(String::as_str))
+            .find_map(|source| {
+                self.options
+                    .table_columns(source)?
+                    .into_iter()
+                    .find(|column| {
+                        projects(self.dialect.quote_identifier(column))
+                            || projects(self.dialect.quote_identifier_path(&[source, column]))
+                    })
+                    .and_then(|column| self.options.column_description(source, column))
+            })?;
+        // Keep the comment on one line so it cannot swallow the SQL after it.
+        let description = description.split_whitespace().collect::<Vec<_>>().join(" ");
+        Some(format!("-- {description}"))
+    }
+}
+
+/// Collects the locations of all operations, including nested pipelines.
+fn collect_operation_locations(node: &DplyrNode, locations: &mut Vec<SourceLocation>) {
+    let DplyrNode::Pipeline { operations, .. } = node else {
+        return;
+    };
+    for operation in operations {
+        locations.push(operation.location().clone());
+        if let DplyrOperation::BindRows { source, .. }
+        | DplyrOperation::BindCols { source, .. }
+        | DplyrOperation::Subquery { source, .. } = operation
+        {
+            collect_operation_locations(source, locations);
+        }
+    }
+}
@@ -0,0 +1,124 @@
+// Staged CTE assembly (TranspileOptions::staged_cte).
+
+use super::assemble::QueryParts;
+use super::{DplyrOperation, GenerationResult, SqlGenerator};
+
+/// Name prefix of the per-verb CTEs (`step1`, `step2`, ...).
+const STAGE_PREFIX: &str = "step";
+
+impl SqlGenerator {
+    /// Renders a pipeline as one CTE per verb so intermediate results can be
+    /// inspected: `WITH step1 AS (...), step2 AS (SELECT ... FROM step1) ...`,
+    /// followed by a closing `SELECT * FROM stepN`.
+    ///
+    /// A `group_by()` has no rows of its own: it is repeated in every
+    /// following stage until a summarise() consumes it. The order set by an
+    /// arrange() is carried the same way: it orders the window functions of
+    /// later stages, stays next to the LIMIT of a stage that keeps the first
+    /// rows, and is applied again by the closing SELECT. A CTE without a limit
+    /// has no ORDER BY, as it would not order anything (and T-SQL rejects it).
+    /// The order ends where its keys leave the projection, as after
+    /// summarise().
+    pub(super) fn generate_staged_pipeline(
+        &self,
+        source_table: &str,
+        operations: &[DplyrOperation],
+    ) -> GenerationResult<String> {
+        let mut stages: Vec<Vec<DplyrOperation>> = Vec::new();
+        // group_by() calls in effect; `.add = TRUE` extends the previous ones.
+        let mut grouping: Vec<&DplyrOperation> = Vec::new();
+        for operation in operations {
+            match operation {
+                DplyrOperation::GroupBy { add, .. } => {
+                    if !add {
+                        grouping.clear();
+                    }
+                    grouping.push(operation);
+                }
+                _ => {
+                    stages.push(
+                        grouping
+                            .iter()
+                            .copied()
+                            .chain([operation])
+                            .cloned()
+                            .collect(),
+                    );
+                    if matches!(operation, DplyrOperation::Summarise { .. }) {
+                        grouping.clear();
+                    }
+                }
+            }
+        }
+
+        if stages.is_empty() {
+            // Only group_by(): nothing to stage.
+            let parts = self.build_query_parts(source_table, operations)?;
+            return self.assemble_query(&Some(source_table.to_string()), &parts);
+        }
+
+        let mut ctes = Vec::with_capacity(stages.len());
+        let mut input = source_table.to_string();
+        let mut order = StageOrder::default();
+        for (index, stage) in stages.iter().enumerate() {
+            let mut parts = QueryParts::new();
+            order.apply_to(&mut parts);
+            for operation in stage {
+                self.process_operation(operation, &mut parts, &input)?;
+            }
+            parts.finalize_group_by();
+            order = StageOrder::left_by(&parts);
+            if parts.limit.is_none() && parts.offset.is_none() {
+                parts.order_by.clear();
+            }
+
+            let sql = self.assemble_query(&Some(input.clone()), &parts)?;
+            let name = format!("{STAGE_PREFIX}{}", index + 1);
+            ctes.push(format!(
+                "{} AS (\n{sql}\n)",
+                self.dialect.quote_identifier(&name)
+            ));
+            input = name;
+        }
+
+        let mut parts = self.build_query_parts(&input, &[])?;
+        parts.order_by = order.order_by;
+        let final_sql = self.assemble_query(&Some(input), &parts)?;
+        Ok(format!("WITH {}\n{final_sql}", ctes.join(",\n")))
+    }
+}
+
+/// The arrange() order in effect after a stage, carried into the next one.
+#[derive(Debug, Clone, Default)]
+struct StageOrder {
+    order_by: String,
+    order_columns: Vec<String>,
+    window_order_by: String,
+}
+
+impl StageOrder {
+    /// Takes the order left by `parts`, unless a key is no longer one of
+    /// its output columns.
+    fn left_by(parts: &QueryParts) -> Self {
+        let projected = parts.columns.as_ref().is_none_or(|columns| {
+            parts
+                .order_columns
+                .iter()
+                .all(|column| columns.contains(column))
+        });
+        if parts.order_columns.is_empty() || !projected {
+            return Self::default();
+        }
+        Self {
+            order_by: parts.order_by.clone(),
+            order_columns: parts.order_columns.clone(),
+            window_order_by: parts.window_order_by.clone(),
+        }
+    }
+
+    fn apply_to(&self, parts: &mut QueryParts) {
+        parts.order_by = self.order_by.clone();
+        parts.order_columns = self.order_columns.clone();
+        parts.window_order_by = self.window_order_by.clone();
+    }
+}
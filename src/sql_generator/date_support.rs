@@ -0,0 +1,91 @@
+// Date/time helpers (lubridate extractors and floor_date()).
+
+use super::{Expr, GenerationError, GenerationResult, LiteralValue, SqlGenerator, WindowContext};
+
+/// lubridate extractors and the date part each one reads.
+const DATE_PARTS: &[(&str, &str)] = &[
+    ("year", "YEAR"),
+    ("month", "MONTH"),
+    ("day", "DAY"),
+    ("mday", "DAY"),
+    ("hour", "HOUR"),
+    ("minute", "MINUTE"),
+    ("second", "SECOND"),
+];
+
+/// Units accepted by `floor_date()`.
+const TRUNCATION_UNITS: &[&str] = &[
+    "second", "minute", "hour", "day", "week", "month", "quarter", "year",
+];
+
+impl SqlGenerator {
+    /// Renders a lubridate extractor (`year(ts)` as `EXTRACT(YEAR FROM ts)`)
+    /// or `floor_date(ts, "day")` as the dialect's truncation.
+    ///
+    /// With `time_zone` set the timestamp is first converted to that zone,
+    /// e.g. `DATE_TRUNC('day', ts AT TIME ZONE 'UTC')`, so parts and day
+    /// boundaries follow its wall clock.
+    pub(super) fn generate_date_function_expression(
+        &self,
+        name: &str,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<String> {
+        let function = name.to_ascii_lowercase();
+        let (value, unit) = match (function.as_str(), args) {
+            ("floor_date", [value, Expr::Literal(LiteralValue::String(unit))]) => {
+                let unit = unit.trim().to_ascii_lowercase();
+                if !TRUNCATION_UNITS.contains(&unit.as_str()) {
+                    return Err(GenerationError::InvalidAst {
+                        reason: format!("floor_date() unit '{unit}' is not supported"),
+                    });
+                }
+                (value, Some(unit))
+            }
+            ("floor_date", _) => {
+                return Err(GenerationError::InvalidAst {
+                    reason: "floor_date() expects a value and a unit string".to_string(),
+                });
+            }
+            (_, [value]) => (value, None),
+            _ => {
+                return Err(GenerationError::InvalidAst {
+                    reason: format!("{function}() expects a single date-time value"),
+                });
+            }
+        };
+
+        let mut value_sql = self.generate_expression_with_window_partition(value, window)?;
+        if let Some(zone) = &self.options.time_zone {
+            value_sql = self
+                .dialect
+                .at_time_zone(&value_sql, &self.dialect.quote_string(zone))
+                .ok_or_else(|| GenerationError::UnsupportedOperation {
+                    operation: "time_zone".to_string(),
+                    dialect: self.dialect.dialect_name().to_string(),
+                })?;
+        }
+
+        let sql = match unit {
+            Some(unit) => self.dialect.date_trunc(&unit, &value_sql),
+            None => date_part(&function).and_then(|part| self.dialect.date_part(part, &value_sql)),
+        };
+        sql.ok_or_else(|| GenerationError::UnsupportedFunction {
+            function: name.to_string(),
+            dialect: self.dialect.dialect_name().to_string(),
+        })
+    }
+}
+
+/// Whether `name` is a date/time function rendered by
+/// `generate_date_function_expression`.
+pub(super) fn is_date_function(name: &str) -> bool {
+    name.eq_ignore_ascii_case("floor_date") || date_part(&name.to_ascii_lowercase()).is_some()
+}
+
+fn date_part(function: &str) -> Option<&'static str> {
+    DATE_PARTS
+        .iter()
+        .find(|(name, _)| *name == function)
+        .map(|(_, part)| *part)
+}
@@ -0,0 +1,54 @@
+// Transpilation diagnostics (intermediate representations).
+
+use super::assemble::{ClauseFragments, QueryParts};
+use super::{optimize, DplyrNode, GenerationResult, SqlGenerator};
+
+/// Intermediate representations behind a generated query, to explain why
+/// the SQL looks the way it does.
+#[derive(Debug, Clone, PartialEq)]
+pub struct DebugInfo {
+    /// Pipeline the SQL was generated from (after the optimization pass,
+    /// when enabled)
+    pub pipeline: DplyrNode,
+    /// Table the pipeline reads from (`data` when the source is implicit)
+    pub source_table: String,
+    /// dplyr grouping in effect at the end of the pipeline, e.g. the keys a
+    /// `summarise()` kept; empty when ungrouped
+    pub group_columns: Vec<String>,
+    /// Clauses of the outermost SELECT
+    pub clauses: ClauseFragments,
+}
+
+impl SqlGenerator {
+    /// Builds the intermediate representations of `ast`.
+    ///
+    /// The clauses are those of the single-query form; `staged_cte` output
+    /// splits the same steps into CTEs.
+    pub fn debug_info(&self, ast: &DplyrNode) -> GenerationResult<DebugInfo> {
+        self.ensure_pipeline_depth(ast)?;
+        let pipeline = if self.options.optimize {
+            optimize(ast.clone())
+        } else {
+            ast.clone()
+        };
+        let (source_table, parts) = match &pipeline {
+            DplyrNode::Pipeline {
+                source, operations, ..
+            } => {
+                let source_table = source.as_deref().unwrap_or("data");
+                (
+                    source_table.to_string(),
+                    self.build_query_parts(source_table, operations)?,
+                )
+            }
+            DplyrNode::DataSource { name, .. } => (name.clone(), QueryParts::new()),
+        };
+        let clauses = self.clause_fragments(&source_table, &parts)?;
+        Ok(DebugInfo {
+            source_table,
+            group_columns: parts.group_columns,
+            clauses,
+            pipeline,
+        })
+    }
+}
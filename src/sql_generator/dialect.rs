@@ -1,24 +1,36 @@
 //! SQL dialects.
 
+use crate::parser::{BinaryOp, GroupingSets, SampleSize};
+
 fn quote_with_escape(name: &str, quote: char) -> String {
     let escaped = name.replace(quote, &quote.to_string().repeat(2));
     format!("{quote}{escaped}{quote}")
 }
 
 /// Translates a common R/tidyverse function to dialect-specific SQL.
+/// Contents of the `OVER (...)` clause shared by the window functions of
+/// one expression.
+#[derive(Debug, Clone, Copy, Default)]
+struct WindowClause<'a> {
+    /// `PARTITION BY ...`, or empty
+    partition: &'a str,
+    /// ORDER BY used by functions without an `order_by` of their own
+    default_order: Option<&'a str>,
+}
+
 fn translate_common_function<D: SqlDialect + ?Sized>(
     dialect: &D,
     function: &str,
     args: &[String],
 ) -> Option<String> {
-    translate_common_function_with_window_clause(dialect, function, args, "")
+    translate_common_function_with_window_clause(dialect, function, args, WindowClause::default())
 }
 
 fn translate_common_function_with_window_clause<D: SqlDialect + ?Sized>(
     dialect: &D,
     function: &str,
     args: &[String],
-    window_clause: &str,
+    window: WindowClause<'_>,
 ) -> Option<String> {
     let fn_lower = function.to_lowercase();
     match fn_lower.as_str() {
@@ -46,6 +58,14 @@ fn translate_common_function_with_window_clause<D: SqlDialect + ?Sized>(
                 None
             }
         }
+        // Exponentiation
+        "power" | "^" => {
+            if args.len() == 2 {
+                Some(format!("POWER({}, {})", args[0], args[1]))
+            } else {
+                None
+            }
+        }
         // Modulo
         "mod" | "%%" => {
             if args.len() == 2 {
@@ -140,7 +160,30 @@ fn translate_common_function_with_window_clause<D: SqlDialect + ?Sized>(
                 None
             }
         }
+        "pmax" | "pmin" => {
+            if args.is_empty() {
+                None
+            } else {
+                let (greatest, least) = dialect.greatest_least();
+                let function = if fn_lower == "pmax" { greatest } else { least };
+                Some(format!("{function}({})", args.join(", ")))
+            }
+        }
+        "between" => {
+            if args.len() == 3 {
+                Some(format!("({} BETWEEN {} AND {})", args[0], args[1], args[2]))
+            } else {
+                None
+            }
+        }
         // NULL checks
+        "!" => {
+            if args.len() == 1 {
+                Some(format!("(NOT {})", args[0]))
+            } else {
+                None
+            }
+        }
         "is.na" => {
             if args.len() == 1 {
                 Some(format!("({} IS NULL)", args[0]))
@@ -148,6 +191,18 @@ fn translate_common_function_with_window_clause<D: SqlDialect + ?Sized>(
                 None
             }
         }
+        // Exactly one of two conditions: unequal truth values, spelled out
+        // where conditions are not values.
+        "xor" => match args {
+            [left, right] if dialect.supports_boolean_values() => Some(format!(
+                "({left} {} {right})",
+                dialect.binary_operator(&BinaryOp::NotEqual)
+            )),
+            [left, right] => Some(format!(
+                "(({left} AND NOT {right}) OR (NOT {left} AND {right}))"
+            )),
+            _ => None,
+        },
         "coalesce" => {
             if !args.is_empty() {
                 Some(format!("COALESCE({})", args.join(", ")))
@@ -168,8 +223,7 @@ fn translate_common_function_with_window_clause<D: SqlDialect + ?Sized>(
                 None
             } else {
                 let n = args.get(1).map(String::as_str).unwrap_or("1");
-                let over =
-                    window_over_clause_with_order(window_clause, args.get(3).map(String::as_str));
+                let over = window_over_clause_with_order(window, args.get(3).map(String::as_str));
                 match args.get(2) {
                     Some(default) => Some(format!("LEAD({}, {}, {}) {over}", args[0], n, default)),
                     None => Some(format!("LEAD({}, {}) {over}", args[0], n)),
@@ -181,37 +235,32 @@ fn translate_common_function_with_window_clause<D: SqlDialect + ?Sized>(
                 None
             } else {
                 let n = args.get(1).map(String::as_str).unwrap_or("1");
-                let over =
-                    window_over_clause_with_order(window_clause, args.get(3).map(String::as_str));
+                let over = window_over_clause_with_order(window, args.get(3).map(String::as_str));
                 match args.get(2) {
                     Some(default) => Some(format!("LAG({}, {}, {}) {over}", args[0], n, default)),
                     None => Some(format!("LAG({}, {}) {over}", args[0], n)),
                 }
             }
         }
-        "rank" => ranking_window_function("RANK", args, window_clause),
-        "dense_rank" => ranking_window_function("DENSE_RANK", args, window_clause),
-        "row_number" => ranking_window_function("ROW_NUMBER", args, window_clause),
+        "rank" => ranking_window_function("RANK", args, window),
+        "dense_rank" => ranking_window_function("DENSE_RANK", args, window),
+        "row_number" => ranking_window_function("ROW_NUMBER", args, window),
         "ntile" => {
             if !args.is_empty() {
-                Some(format!(
-                    "NTILE({}) {}",
-                    args[0],
-                    window_over_clause(window_clause)
-                ))
+                Some(format!("NTILE({}) {}", args[0], window_over_clause(window)))
             } else {
                 None
             }
         }
-        "first" | "first_value" => value_window_function("FIRST_VALUE", args, window_clause),
-        "last" | "last_value" => last_value_window_function(args, window_clause),
+        "first" | "first_value" => value_window_function("FIRST_VALUE", args, window),
+        "last" | "last_value" => last_value_window_function(args, window),
         "nth_value" => {
             if args.len() >= 2 {
                 Some(format!(
                     "NTH_VALUE({}, {}) {}",
                     args[0],
                     args[1],
-                    window_over_clause(window_clause)
+                    window_over_clause(window)
                 ))
             } else {
                 None
@@ -240,12 +289,12 @@ fn one_or_two_arg_sql_function(sql_function: &str, args: &[String]) -> Option<St
 fn ranking_window_function(
     sql_function: &str,
     args: &[String],
-    window_clause: &str,
+    window: WindowClause<'_>,
 ) -> Option<String> {
     if args.len() <= 1 {
         Some(format!(
             "{sql_function}() {}",
-            window_over_clause_with_order(window_clause, args.first().map(String::as_str))
+            window_over_clause_with_order(window, args.first().map(String::as_str))
         ))
     } else {
         None
@@ -255,38 +304,41 @@ fn ranking_window_function(
 fn value_window_function(
     sql_function: &str,
     args: &[String],
-    window_clause: &str,
+    window: WindowClause<'_>,
 ) -> Option<String> {
     if (1..=2).contains(&args.len()) {
         Some(format!(
             "{sql_function}({}) {}",
             args[0],
-            window_over_clause_with_order(window_clause, args.get(1).map(String::as_str))
+            window_over_clause_with_order(window, args.get(1).map(String::as_str))
         ))
     } else {
         None
     }
 }
 
-fn last_value_window_function(args: &[String], window_clause: &str) -> Option<String> {
+fn last_value_window_function(args: &[String], window: WindowClause<'_>) -> Option<String> {
     if (1..=2).contains(&args.len()) {
         Some(format!(
             "LAST_VALUE({}) {}",
             args[0],
-            window_over_clause_with_full_frame(window_clause, args.get(1).map(String::as_str))
+            window_over_clause_with_full_frame(window, args.get(1).map(String::as_str))
         ))
     } else {
         None
     }
 }
 
-fn window_over_clause(window_clause: &str) -> String {
-    window_over_clause_with_order(window_clause, None)
+fn window_over_clause(window: WindowClause<'_>) -> String {
+    window_over_clause_with_order(window, None)
 }
 
-fn window_over_clause_with_order(window_clause: &str, order_by: Option<&str>) -> String {
-    let trimmed = window_clause.trim();
-    let order_by = order_by.map(str::trim).filter(|value| !value.is_empty());
+fn window_over_clause_with_order(window: WindowClause<'_>, order_by: Option<&str>) -> String {
+    let trimmed = window.partition.trim();
+    let order_by = order_by
+        .or(window.default_order)
+        .map(str::trim)
+        .filter(|value| !value.is_empty());
 
     match (trimmed.is_empty(), order_by) {
         (true, None) => "OVER ()".to_string(),
@@ -296,9 +348,12 @@ fn window_over_clause_with_order(window_clause: &str, order_by: Option<&str>) ->
     }
 }
 
-fn window_over_clause_with_full_frame(window_clause: &str, order_by: Option<&str>) -> String {
-    let trimmed = window_clause.trim();
-    let order_by = order_by.map(str::trim).filter(|value| !value.is_empty());
+fn window_over_clause_with_full_frame(window: WindowClause<'_>, order_by: Option<&str>) -> String {
+    let trimmed = window.partition.trim();
+    let order_by = order_by
+        .or(window.default_order)
+        .map(str::trim)
+        .filter(|value| !value.is_empty());
 
     match (trimmed.is_empty(), order_by) {
         (true, None) => "OVER ()".to_string(),
@@ -326,6 +381,8 @@ fn is_supported_common_function(function: &str) -> bool {
             | "exp"
             | "log"
             | "log10"
+            | "power"
+            | "^"
             | "mod"
             | "%%"
             | "sin"
@@ -347,6 +404,16 @@ fn is_supported_common_function(function: &str) -> bool {
             | "touppercase"
             | "upper"
             | "str_detect"
+            | "str_starts"
+            | "str_ends"
+            | "year"
+            | "month"
+            | "day"
+            | "mday"
+            | "hour"
+            | "minute"
+            | "second"
+            | "floor_date"
             | "str_length"
             | "str_to_lower"
             | "str_to_upper"
@@ -362,7 +429,14 @@ fn is_supported_common_function(function: &str) -> bool {
             | "as.logical"
             | "ifelse"
             | "if_else"
+            | "between"
+            | "pmax"
+            | "pmin"
+            | "case_match"
+            | "cast"
+            | "!"
             | "is.na"
+            | "xor"
             | "lead"
             | "lag"
             | "rank"
@@ -380,6 +454,21 @@ fn is_supported_common_function(function: &str) -> bool {
     )
 }
 
+/// Default `cast()` type mapping (see `SqlDialect::cast_type`).
+fn common_cast_type<D: SqlDialect + ?Sized>(dialect: &D, type_name: &str) -> String {
+    let r_function = match type_name.to_ascii_lowercase().as_str() {
+        "integer" | "int" => "as.integer",
+        "numeric" | "double" | "float" | "real" => "as.numeric",
+        "character" | "varchar" | "string" | "text" => "as.character",
+        "logical" | "boolean" | "bool" => "as.logical",
+        _ => "",
+    };
+    dialect
+        .r_cast_type(r_function)
+        .map(str::to_string)
+        .unwrap_or_else(|| type_name.to_ascii_uppercase())
+}
+
 fn sqlite_requires_math_extension(function: &str) -> bool {
     matches!(
         function.to_ascii_lowercase().as_str(),
@@ -391,6 +480,8 @@ fn sqlite_requires_math_extension(function: &str) -> bool {
             | "exp"
             | "log"
             | "log10"
+            | "power"
+            | "^"
             | "sin"
             | "cos"
             | "tan"
@@ -409,7 +500,7 @@ fn translate_common_aggregate_function(function: &str) -> Option<String> {
     match function.to_lowercase().as_str() {
         "mean" | "avg" => Some("AVG".to_string()),
         "sum" => Some("SUM".to_string()),
-        "count" => Some("COUNT".to_string()),
+        "count" | "length" => Some("COUNT".to_string()),
         "min" => Some("MIN".to_string()),
         "max" => Some("MAX".to_string()),
         "n" => Some("COUNT".to_string()),
@@ -417,6 +508,32 @@ fn translate_common_aggregate_function(function: &str) -> Option<String> {
     }
 }
 
+/// Renders binary operators with their common SQL spelling; dplyr's `==`
+/// becomes `=` everywhere.
+const fn translate_common_binary_operator(operator: &BinaryOp) -> &'static str {
+    match operator {
+        BinaryOp::Equal => "=",
+        BinaryOp::NotEqual => "!=",
+        BinaryOp::LessThan => "<",
+        BinaryOp::LessThanOrEqual => "<=",
+        BinaryOp::GreaterThan => ">",
+        BinaryOp::GreaterThanOrEqual => ">=",
+        BinaryOp::Like => "LIKE",
+        BinaryOp::ILike => "ILIKE",
+        BinaryOp::In => "IN",
+        BinaryOp::And => "AND",
+        BinaryOp::Or => "OR",
+        BinaryOp::Plus => "+",
+        BinaryOp::Minus => "-",
+        BinaryOp::Multiply => "*",
+        BinaryOp::Divide => "/",
+        // Rendered by `SqlDialect::integer_divide`; plain `/` only truncates
+        // integer operands, and toward zero.
+        BinaryOp::IntegerDivide => "/",
+        BinaryOp::Modulo => "%",
+    }
+}
+
 fn concat_with_operator(args: &[String]) -> Option<String> {
     if args.is_empty() {
         None
@@ -535,6 +652,21 @@ pub trait SqlDialect {
     /// The LIMIT clause string
     fn limit_clause(&self, limit: usize) -> String;
 
+    /// Generates the clause skipping `offset` rows, then keeping `limit`
+    /// of the rest (all of them when `None`).
+    fn offset_clause(&self, offset: usize, limit: Option<usize>) -> String {
+        match limit {
+            Some(limit) => format!("{} OFFSET {offset}", self.limit_clause(limit)),
+            None => format!("OFFSET {offset}"),
+        }
+    }
+
+    /// Whether the limit clause is only valid after an `ORDER BY` (T-SQL's
+    /// `OFFSET ... FETCH`).
+    fn limit_requires_order_by(&self) -> bool {
+        false
+    }
+
     /// Generates string concatenation operation.
     ///
     /// Different databases have different ways to concatenate strings:
@@ -589,6 +721,151 @@ pub trait SqlDialect {
         None
     }
 
+    /// Returns `* REPLACE (...)`-style projection if supported by the dialect;
+    /// each replacement is a rendered `expr AS "column"` item.
+    fn select_star_replace(&self, _replacements: &[String]) -> Option<String> {
+        None
+    }
+
+    /// Whether window value functions accept `IGNORE NULLS`
+    /// (`LAST_VALUE(x IGNORE NULLS) OVER (...)`).
+    fn supports_ignore_nulls(&self) -> bool {
+        false
+    }
+
+    /// Whether a condition can be selected as a value (`("x" > 1) AS "flag"`).
+    fn supports_boolean_values(&self) -> bool {
+        true
+    }
+
+    /// Renders a logical literal (`TRUE`/`FALSE`).
+    fn boolean_literal(&self, value: bool) -> &'static str {
+        if value {
+            "TRUE"
+        } else {
+            "FALSE"
+        }
+    }
+
+    /// Whether aggregates accept a `FILTER (WHERE ...)` clause
+    /// (`COUNT(*) FILTER (WHERE "x" > 1)`).
+    fn supports_aggregate_filter(&self) -> bool {
+        false
+    }
+
+    /// Whether `SELECT DISTINCT ON (keys)` is available to keep the first
+    /// row per key (`distinct(key, .keep_all = TRUE)`).
+    fn supports_distinct_on(&self) -> bool {
+        false
+    }
+
+    /// Combines several values into one key for `COUNT(DISTINCT ...)`, as
+    /// `n_distinct(a, b)` counts distinct combinations. The default
+    /// concatenates them around a `'|'` separator; dialects with row values
+    /// count `(a, b)` instead, and MySQL lists them (`COUNT(DISTINCT a, b)`).
+    fn distinct_key(&self, values: &[String]) -> Option<String> {
+        let separator = self.quote_string("|");
+        let mut parts = Vec::with_capacity(values.len() * 2);
+        for value in values {
+            if !parts.is_empty() {
+                parts.push(separator.clone());
+            }
+            parts.push(value.clone());
+        }
+        self.concat_no_separator(&parts)
+    }
+
+    /// Expression drawing a uniform random number in `[0, 1)` per row.
+    fn random_fraction(&self) -> &'static str {
+        "RANDOM()"
+    }
+
+    /// Random number in `[0, 1)` per row drawn from a generator seeded with
+    /// `seed`, if the dialect can seed it within the query.
+    fn seeded_random_fraction(&self, _seed: u64) -> Option<String> {
+        None
+    }
+
+    /// Native sampling clause placed after `FROM` (`USING SAMPLE ...`), if
+    /// the dialect has one; `seed` makes the sample repeatable.
+    fn sample_clause(&self, _size: SampleSize, _seed: Option<u64>) -> Option<String> {
+        None
+    }
+
+    /// GROUP BY list for a `rollup()`/`cube()`/`groupingsets()` grouping of
+    /// `columns`, or `None` when the dialect lacks the construct.
+    fn grouping_sets_clause(&self, sets: &GroupingSets, columns: &[String]) -> Option<String> {
+        let list = |columns: &[String]| {
+            columns
+                .iter()
+                .map(|column| self.quote_identifier(column))
+                .collect::<Vec<_>>()
+                .join(", ")
+        };
+        Some(match sets {
+            GroupingSets::Rollup => format!("ROLLUP ({})", list(columns)),
+            GroupingSets::Cube => format!("CUBE ({})", list(columns)),
+            GroupingSets::Sets(sets) => format!(
+                "GROUPING SETS ({})",
+                sets.iter()
+                    .map(|set| format!("({})", list(set)))
+                    .collect::<Vec<_>>()
+                    .join(", ")
+            ),
+        })
+    }
+
+    /// Keyword prefix that returns the query plan; with `analyze` the query
+    /// is also executed to report actual costs. `None` when unsupported.
+    fn explain_prefix(&self, analyze: bool) -> Option<&'static str> {
+        Some(if analyze {
+            "EXPLAIN ANALYZE"
+        } else {
+            "EXPLAIN"
+        })
+    }
+
+    /// Whether `FULL JOIN` is available.
+    fn supports_full_join(&self) -> bool {
+        true
+    }
+
+    /// Whether sort keys accept `NULLS FIRST` / `NULLS LAST`.
+    fn supports_nulls_ordering(&self) -> bool {
+        true
+    }
+
+    /// Row-wise maximum and minimum functions (pmax/pmin).
+    fn greatest_least(&self) -> (&'static str, &'static str) {
+        ("GREATEST", "LEAST")
+    }
+
+    /// Whether the row-wise maximum/minimum skip NULL arguments instead of
+    /// returning NULL.
+    fn greatest_ignores_nulls(&self) -> bool {
+        false
+    }
+
+    /// Whether `INTERSECT` and `EXCEPT` are available.
+    fn supports_intersect_except(&self) -> bool {
+        true
+    }
+
+    /// Whether `LIKE` compares case-sensitively.
+    fn like_is_case_sensitive(&self) -> bool {
+        true
+    }
+
+    /// Characters with a special meaning in a `LIKE` pattern.
+    fn like_wildcards(&self) -> &'static str {
+        "%_"
+    }
+
+    /// Whether `SELECT DISTINCT` may only be sorted by selected columns.
+    fn requires_distinct_order_in_select(&self) -> bool {
+        true
+    }
+
     /// Translates R/dplyr function names to SQL equivalents.
     ///
     /// Maps common R functions to their SQL counterparts. Override this
@@ -610,11 +887,43 @@ pub trait SqlDialect {
             return self.translate_function(function, args);
         }
 
-        let window_clause = format!("PARTITION BY {partition_by}");
-        translate_common_function_with_window_clause(self, function, args, &window_clause)
+        let partition = format!("PARTITION BY {partition_by}");
+        let window = WindowClause {
+            partition: &partition,
+            default_order: None,
+        };
+        translate_common_function_with_window_clause(self, function, args, window)
             .or_else(|| self.translate_unknown_function(function, args))
     }
 
+    /// Translates a function inside a grouped and/or arranged pipeline:
+    /// window functions get `PARTITION BY partition_by` and, unless they
+    /// carry their own `order_by`, `ORDER BY order_by` (e.g. `"ts" DESC`).
+    fn translate_function_in_window(
+        &self,
+        function: &str,
+        args: &[String],
+        partition_by: &str,
+        order_by: &str,
+    ) -> Option<String> {
+        let order_by = order_by.trim();
+        if order_by.is_empty() || !self.is_supported_function(function) {
+            return self.translate_function_with_window_partition(function, args, partition_by);
+        }
+
+        let partition_by = partition_by.trim();
+        let partition = if partition_by.is_empty() {
+            String::new()
+        } else {
+            format!("PARTITION BY {partition_by}")
+        };
+        let window = WindowClause {
+            partition: &partition,
+            default_order: Some(order_by),
+        };
+        translate_common_function_with_window_clause(self, function, args, window)
+    }
+
     /// Returns whether this dialect allows the function to be called.
     fn is_supported_function(&self, function: &str) -> bool {
         is_supported_common_function(function)
@@ -625,6 +934,20 @@ pub trait SqlDialect {
         translate_common_aggregate_function(function)
     }
 
+    /// Renders R's `left %/% right`, which rounds the quotient down for any
+    /// numeric operands (`7.5 %/% 2` is 3, `-7 %/% 2` is -4). The `* 1.0`
+    /// keeps integer operands from truncating before `FLOOR`.
+    fn integer_divide(&self, left: &str, right: &str) -> String {
+        format!("FLOOR(({left} * 1.0) / {right})")
+    }
+
+    /// Returns the SQL spelling of a binary operator.
+    ///
+    /// Dialects that prefer the ANSI `<>` over `!=` override this.
+    fn binary_operator(&self, operator: &BinaryOp) -> &'static str {
+        translate_common_binary_operator(operator)
+    }
+
     /// Late-bound translation hook for dialects that can resolve functions later.
     fn translate_unknown_function(&self, _function: &str, _args: &[String]) -> Option<String> {
         None
@@ -651,6 +974,29 @@ pub trait SqlDialect {
         }
     }
 
+    /// SQL type for a `cast()` type name. Common names reuse the R cast
+    /// helper types (`"integer"` is `as.integer`'s type); others are
+    /// uppercased as written.
+    fn cast_type(&self, type_name: &str) -> String {
+        common_cast_type(self, type_name)
+    }
+
+    /// Extracts a date part (`YEAR`, `MONTH`, ..., `SECOND`) as a number.
+    fn date_part(&self, part: &str, value: &str) -> Option<String> {
+        Some(format!("EXTRACT({part} FROM {value})"))
+    }
+
+    /// Truncates a timestamp to `unit` (`day`, `month`, ...) for floor_date().
+    fn date_trunc(&self, unit: &str, value: &str) -> Option<String> {
+        Some(format!("DATE_TRUNC('{unit}', {value})"))
+    }
+
+    /// Converts a timestamp to the wall clock of the quoted `zone`; `None`
+    /// when the dialect has no time zone support.
+    fn at_time_zone(&self, value: &str, zone: &str) -> Option<String> {
+        Some(format!("{value} AT TIME ZONE {zone}"))
+    }
+
     /// Dialect-specific base-10 logarithm function.
     fn log10(&self, value: &str) -> String {
         format!("LOG10({value})")
@@ -764,7 +1110,7 @@ impl SqlDialect for PostgreSqlDialect {
         match function.to_lowercase().as_str() {
             "mean" | "avg" => "AVG".to_string(),
             "sum" => "SUM".to_string(),
-            "count" => "COUNT".to_string(),
+            "count" | "length" => "COUNT".to_string(),
             "min" => "MIN".to_string(),
             "max" => "MAX".to_string(),
             "n" => "COUNT".to_string(),
@@ -790,6 +1136,23 @@ impl SqlDialect for PostgreSqlDialect {
         format!("LOG({value})")
     }
 
+    // GREATEST/LEAST ignore NULLs; the result is NULL only if all are.
+    fn greatest_ignores_nulls(&self) -> bool {
+        true
+    }
+
+    fn supports_aggregate_filter(&self) -> bool {
+        true
+    }
+
+    fn supports_distinct_on(&self) -> bool {
+        true
+    }
+
+    fn distinct_key(&self, values: &[String]) -> Option<String> {
+        Some(format!("({})", values.join(", ")))
+    }
+
     fn is_case_sensitive(&self) -> bool {
         false
     }
@@ -866,10 +1229,74 @@ impl SqlDialect for MySqlDialect {
         "mysql"
     }
 
+    fn supports_full_join(&self) -> bool {
+        false
+    }
+
+    fn supports_nulls_ordering(&self) -> bool {
+        false
+    }
+
+    fn random_fraction(&self) -> &'static str {
+        "RAND()"
+    }
+
+    fn seeded_random_fraction(&self, seed: u64) -> Option<String> {
+        Some(format!("RAND({seed})"))
+    }
+
+    // Only the `WITH ROLLUP` modifier; no CUBE or GROUPING SETS.
+    fn grouping_sets_clause(&self, sets: &GroupingSets, columns: &[String]) -> Option<String> {
+        let GroupingSets::Rollup = sets else {
+            return None;
+        };
+        let list = columns
+            .iter()
+            .map(|column| self.quote_identifier(column))
+            .collect::<Vec<_>>()
+            .join(", ");
+        Some(format!("{list} WITH ROLLUP"))
+    }
+
+    // Only available from MySQL 8.0.31.
+    fn supports_intersect_except(&self) -> bool {
+        false
+    }
+
+    // Follows the column collation, which is case-insensitive by default.
+    fn like_is_case_sensitive(&self) -> bool {
+        false
+    }
+
+    // `/` always returns a decimal in MySQL.
+    fn integer_divide(&self, left: &str, right: &str) -> String {
+        format!("FLOOR({left} / {right})")
+    }
+
+    fn binary_operator(&self, operator: &BinaryOp) -> &'static str {
+        match operator {
+            BinaryOp::NotEqual => "<>",
+            // No ILIKE; LIKE already compares case-insensitively under the
+            // default (_ci) collations.
+            BinaryOp::ILike => "LIKE",
+            BinaryOp::Modulo => "MOD",
+            _ => translate_common_binary_operator(operator),
+        }
+    }
+
     fn limit_clause(&self, limit: usize) -> String {
         format!("LIMIT {limit}")
     }
 
+    // OFFSET is only valid after a LIMIT; the documented "all rows" limit is
+    // the largest unsigned BIGINT.
+    fn offset_clause(&self, offset: usize, limit: Option<usize>) -> String {
+        match limit {
+            Some(limit) => format!("LIMIT {limit} OFFSET {offset}"),
+            None => format!("LIMIT 18446744073709551615 OFFSET {offset}"),
+        }
+    }
+
     fn string_concat(&self, left: &str, right: &str) -> String {
         format!("CONCAT({left}, {right})")
     }
@@ -878,7 +1305,7 @@ impl SqlDialect for MySqlDialect {
         match function.to_lowercase().as_str() {
             "mean" | "avg" => "AVG".to_string(),
             "sum" => "SUM".to_string(),
-            "count" => "COUNT".to_string(),
+            "count" | "length" => "COUNT".to_string(),
             "min" => "MIN".to_string(),
             "max" => "MAX".to_string(),
             "n" => "COUNT".to_string(),
@@ -899,11 +1326,46 @@ impl SqlDialect for MySqlDialect {
             "as.numeric" | "as.double" => Some("DOUBLE"),
             "as.integer" => Some("SIGNED"),
             "as.character" => Some("CHAR"),
-            "as.logical" => Some("BOOLEAN"),
+            // CAST has no BOOLEAN target; MySQL booleans are integers.
+            "as.logical" => Some("SIGNED"),
             _ => None,
         }
     }
 
+    // CAST only targets a few types: strings are CHAR(n), integers SIGNED
+    // and exact numerics DECIMAL(p, s).
+    fn cast_type(&self, type_name: &str) -> String {
+        let upper = type_name.to_ascii_uppercase();
+        let (base, size) = match upper.split_once('(') {
+            Some((base, rest)) => (base.trim(), format!("({rest}")),
+            None => (upper.trim(), String::new()),
+        };
+        match base {
+            "VARCHAR" | "CHARACTER VARYING" | "NVARCHAR" | "CHARACTER" | "TEXT" | "STRING" => {
+                format!("CHAR{size}")
+            }
+            "TINYINT" | "SMALLINT" | "MEDIUMINT" | "BIGINT" | "INT" | "INTEGER" => {
+                "SIGNED".to_string()
+            }
+            "NUMERIC" if !size.is_empty() => format!("DECIMAL{size}"),
+            _ => common_cast_type(self, type_name),
+        }
+    }
+
+    fn date_trunc(&self, _unit: &str, _value: &str) -> Option<String> {
+        None
+    }
+
+    // Named zones need the time zone tables to be loaded.
+    fn at_time_zone(&self, value: &str, zone: &str) -> Option<String> {
+        Some(format!("CONVERT_TZ({value}, @@session.time_zone, {zone})"))
+    }
+
+    // COUNT(DISTINCT a, b) takes the columns as they are.
+    fn distinct_key(&self, values: &[String]) -> Option<String> {
+        Some(values.join(", "))
+    }
+
     fn is_case_sensitive(&self) -> bool {
         false
     }
@@ -1051,7 +1513,7 @@ impl SqlDialect for DuckDbDialect {
         match function.to_lowercase().as_str() {
             "mean" | "avg" => "AVG".to_string(),
             "sum" => "SUM".to_string(),
-            "count" => "COUNT".to_string(),
+            "count" | "length" => "COUNT".to_string(),
             "min" => "MIN".to_string(),
             "max" => "MAX".to_string(),
             "n" => "COUNT".to_string(),
@@ -1075,6 +1537,10 @@ impl SqlDialect for DuckDbDialect {
         Some(format!("regexp_matches({value}, {pattern})"))
     }
 
+    fn greatest_ignores_nulls(&self) -> bool {
+        true
+    }
+
     fn is_case_sensitive(&self) -> bool {
         false
     }
@@ -1091,6 +1557,50 @@ impl SqlDialect for DuckDbDialect {
         Some(format!("* EXCLUDE ({list})"))
     }
 
+    fn select_star_replace(&self, replacements: &[String]) -> Option<String> {
+        Some(format!("* REPLACE ({})", replacements.join(", ")))
+    }
+
+    fn supports_ignore_nulls(&self) -> bool {
+        true
+    }
+
+    fn supports_aggregate_filter(&self) -> bool {
+        true
+    }
+
+    fn supports_distinct_on(&self) -> bool {
+        true
+    }
+
+    fn distinct_key(&self, values: &[String]) -> Option<String> {
+        Some(format!("({})", values.join(", ")))
+    }
+
+    fn sample_clause(&self, size: SampleSize, seed: Option<u64>) -> Option<String> {
+        Some(match (size, seed) {
+            (SampleSize::Rows(n), None) => format!("USING SAMPLE {n} ROWS"),
+            // Reservoir is the default method for row counts.
+            (SampleSize::Rows(n), Some(seed)) => {
+                format!("USING SAMPLE {n} ROWS (reservoir, {seed})")
+            }
+            // Bernoulli draws each row independently; the default system
+            // sampling picks whole vectors of rows.
+            (SampleSize::Fraction(prop), seed) => {
+                let percent = (prop * 100.0 * 1e6).round() / 1e6;
+                match seed {
+                    Some(seed) => format!("USING SAMPLE {percent}% (bernoulli, {seed})"),
+                    None => format!("USING SAMPLE {percent}% (bernoulli)"),
+                }
+            }
+        })
+    }
+
+    // `/` is float division in DuckDB.
+    fn integer_divide(&self, left: &str, right: &str) -> String {
+        format!("FLOOR({left} / {right})")
+    }
+
     fn clone_box(&self) -> Box<dyn SqlDialect> {
         Box::new(self.clone())
     }
@@ -1120,10 +1630,30 @@ impl SqlDialect for SqliteDialect {
         "sqlite"
     }
 
+    fn like_is_case_sensitive(&self) -> bool {
+        false
+    }
+
+    fn binary_operator(&self, operator: &BinaryOp) -> &'static str {
+        match operator {
+            // No ILIKE; LIKE is case-insensitive for ASCII characters.
+            BinaryOp::ILike => "LIKE",
+            _ => translate_common_binary_operator(operator),
+        }
+    }
+
     fn limit_clause(&self, limit: usize) -> String {
         format!("LIMIT {limit}")
     }
 
+    // OFFSET is only valid after a LIMIT; -1 means no limit.
+    fn offset_clause(&self, offset: usize, limit: Option<usize>) -> String {
+        match limit {
+            Some(limit) => format!("LIMIT {limit} OFFSET {offset}"),
+            None => format!("LIMIT -1 OFFSET {offset}"),
+        }
+    }
+
     fn string_concat(&self, left: &str, right: &str) -> String {
         format!("{left} || {right}")
     }
@@ -1132,7 +1662,7 @@ impl SqlDialect for SqliteDialect {
         match function.to_lowercase().as_str() {
             "mean" | "avg" => "AVG".to_string(),
             "sum" => "SUM".to_string(),
-            "count" => "COUNT".to_string(),
+            "count" | "length" => "COUNT".to_string(),
             "min" => "MIN".to_string(),
             "max" => "MAX".to_string(),
             "n" => "COUNT".to_string(),
@@ -1163,14 +1693,30 @@ impl SqlDialect for SqliteDialect {
             return self.translate_function(function, args);
         }
 
-        let window_clause = format!("PARTITION BY {partition_by}");
-        translate_common_function_with_window_clause(self, function, args, &window_clause)
+        let partition = format!("PARTITION BY {partition_by}");
+        let window = WindowClause {
+            partition: &partition,
+            default_order: None,
+        };
+        translate_common_function_with_window_clause(self, function, args, window)
     }
 
     fn is_supported_function(&self, function: &str) -> bool {
         !sqlite_requires_math_extension(function) && is_supported_common_function(function)
     }
 
+    // FLOOR() needs the math extension; CAST truncates toward zero, so a
+    // negative quotient with a fraction takes one off.
+    fn integer_divide(&self, left: &str, right: &str) -> String {
+        let quotient = format!("(({left} * 1.0) / {right})");
+        format!("(CAST({quotient} AS INTEGER) - ({quotient} < CAST({quotient} AS INTEGER)))")
+    }
+
+    // Plain EXPLAIN lists VDBE bytecode; there is no ANALYZE variant.
+    fn explain_prefix(&self, analyze: bool) -> Option<&'static str> {
+        (!analyze).then_some("EXPLAIN QUERY PLAN")
+    }
+
     fn r_cast_type(&self, function: &str) -> Option<&'static str> {
         match function {
             "as.numeric" | "as.double" => Some("REAL"),
@@ -1181,6 +1727,28 @@ impl SqlDialect for SqliteDialect {
         }
     }
 
+    fn date_part(&self, part: &str, value: &str) -> Option<String> {
+        let format = match part {
+            "YEAR" => "%Y",
+            "MONTH" => "%m",
+            "DAY" => "%d",
+            "HOUR" => "%H",
+            "MINUTE" => "%M",
+            "SECOND" => "%S",
+            _ => return None,
+        };
+        Some(format!("CAST(STRFTIME('{format}', {value}) AS INTEGER)"))
+    }
+
+    fn date_trunc(&self, _unit: &str, _value: &str) -> Option<String> {
+        None
+    }
+
+    // Date functions only know UTC and the host's local time.
+    fn at_time_zone(&self, _value: &str, _zone: &str) -> Option<String> {
+        None
+    }
+
     fn concat_no_separator(&self, args: &[String]) -> Option<String> {
         concat_with_operator(args)
     }
@@ -1189,6 +1757,205 @@ impl SqlDialect for SqliteDialect {
         concat_with_separator_operator(separator, args)
     }
 
+    // Sorts by any input column, picking an arbitrary row per group.
+    fn requires_distinct_order_in_select(&self) -> bool {
+        false
+    }
+
+    fn grouping_sets_clause(&self, _sets: &GroupingSets, _columns: &[String]) -> Option<String> {
+        None
+    }
+
+    // The multi-argument scalar MAX()/MIN() act as GREATEST/LEAST.
+    fn greatest_least(&self) -> (&'static str, &'static str) {
+        ("MAX", "MIN")
+    }
+
+    // RANDOM() is a signed 64-bit integer; scale it into [0, 1).
+    fn random_fraction(&self) -> &'static str {
+        "(RANDOM() / 18446744073709551616.0 + 0.5)"
+    }
+
+    fn is_case_sensitive(&self) -> bool {
+        false
+    }
+
+    fn clone_box(&self) -> Box<dyn SqlDialect> {
+        Box::new(self.clone())
+    }
+}
+
+/// SQL Server (T-SQL) dialect implementation
+///
+/// Implements SQL generation for Microsoft SQL Server 2012 and later.
+/// Identifiers are bracket-quoted, strings concatenate with `+` and row
+/// limits use `OFFSET ... FETCH`, which T-SQL only accepts after an
+/// `ORDER BY`.
+///
+/// # Features
+///
+/// - Bracket-quoted identifiers: `[column_name]`
+/// - String concatenation with the `+` operator
+/// - `OFFSET 0 ROWS FETCH NEXT n ROWS ONLY` row limits
+///
+/// # Examples
+///
+/// ```rust
+/// use libdplyr::{Transpiler, SqlServerDialect};
+///
+/// let transpiler = Transpiler::new(Box::new(SqlServerDialect::new()));
+/// let sql = transpiler.transpile("data %>% arrange(age) %>% head(10)").unwrap();
+///
+/// // Generated SQL:
+/// // SELECT * FROM [data] ORDER BY [age] ASC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY
+/// assert!(sql.contains("OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"));
+/// ```
+#[derive(Debug, Clone)]
+pub struct SqlServerDialect;
+
+impl SqlServerDialect {
+    /// Creates a new SQL Server dialect instance.
+    ///
+    /// # Returns
+    ///
+    /// A new `SqlServerDialect` configured for SQL Server databases.
+    ///
+    /// # Examples
+    ///
+    /// ```rust
+    /// use libdplyr::{SqlServerDialect, SqlDialect};
+    ///
+    /// let dialect = SqlServerDialect::new();
+    /// assert_eq!(dialect.quote_identifier("user"), "[user]");
+    /// assert_eq!(dialect.string_concat("'a'", "'b'"), "'a' + 'b'");
+    /// ```
+    pub const fn new() -> Self {
+        Self
+    }
+}
+
+impl Default for SqlServerDialect {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+impl SqlDialect for SqlServerDialect {
+    fn quote_identifier(&self, name: &str) -> String {
+        format!("[{}]", name.replace(']', "]]"))
+    }
+
+    fn quote_string(&self, value: &str) -> String {
+        let escaped = value.replace('\'', "''");
+        format!("'{escaped}'")
+    }
+
+    fn dialect_name(&self) -> &'static str {
+        "sqlserver"
+    }
+
+    fn limit_clause(&self, limit: usize) -> String {
+        format!("OFFSET 0 ROWS FETCH NEXT {limit} ROWS ONLY")
+    }
+
+    fn offset_clause(&self, offset: usize, limit: Option<usize>) -> String {
+        match limit {
+            Some(limit) => format!("OFFSET {offset} ROWS FETCH NEXT {limit} ROWS ONLY"),
+            None => format!("OFFSET {offset} ROWS"),
+        }
+    }
+
+    fn limit_requires_order_by(&self) -> bool {
+        true
+    }
+
+    fn supports_nulls_ordering(&self) -> bool {
+        false
+    }
+
+    // Plans are requested with SET SHOWPLAN_XML, not a query prefix.
+    fn explain_prefix(&self, _analyze: bool) -> Option<&'static str> {
+        None
+    }
+
+    // RAND() is evaluated once per query unless seeded per row.
+    fn random_fraction(&self) -> &'static str {
+        "RAND(CHECKSUM(NEWID()))"
+    }
+
+    // Follows the column collation, which is case-insensitive by default.
+    fn like_is_case_sensitive(&self) -> bool {
+        false
+    }
+
+    // Predicates are only allowed in WHERE, ON, HAVING and CASE WHEN.
+    fn supports_boolean_values(&self) -> bool {
+        false
+    }
+
+    // No TRUE/FALSE keywords; logical values are BIT.
+    fn boolean_literal(&self, value: bool) -> &'static str {
+        if value {
+            "1"
+        } else {
+            "0"
+        }
+    }
+
+    // `[a-c]` is a character class.
+    fn like_wildcards(&self) -> &'static str {
+        "%_["
+    }
+
+    fn binary_operator(&self, operator: &BinaryOp) -> &'static str {
+        match operator {
+            BinaryOp::NotEqual => "<>",
+            // No ILIKE; LIKE already compares case-insensitively under the
+            // default (_CI_) collations.
+            BinaryOp::ILike => "LIKE",
+            _ => translate_common_binary_operator(operator),
+        }
+    }
+
+    fn string_concat(&self, left: &str, right: &str) -> String {
+        format!("{left} + {right}")
+    }
+
+    fn aggregate_function(&self, function: &str) -> String {
+        match function.to_lowercase().as_str() {
+            "mean" | "avg" => "AVG".to_string(),
+            "sum" => "SUM".to_string(),
+            "count" | "length" => "COUNT".to_string(),
+            "min" => "MIN".to_string(),
+            "max" => "MAX".to_string(),
+            "n" => "COUNT".to_string(),
+            _ => function.to_uppercase(),
+        }
+    }
+
+    fn char_length(&self, value: &str) -> String {
+        format!("LEN({value})")
+    }
+
+    fn r_cast_type(&self, function: &str) -> Option<&'static str> {
+        match function {
+            "as.numeric" | "as.double" => Some("FLOAT"),
+            "as.integer" => Some("INT"),
+            "as.character" => Some("NVARCHAR(MAX)"),
+            "as.logical" => Some("BIT"),
+            _ => None,
+        }
+    }
+
+    fn date_part(&self, part: &str, value: &str) -> Option<String> {
+        Some(format!("DATEPART({part}, {value})"))
+    }
+
+    // DATETRUNC needs SQL Server 2022.
+    fn date_trunc(&self, unit: &str, value: &str) -> Option<String> {
+        Some(format!("DATETRUNC({unit}, {value})"))
+    }
+
     fn is_case_sensitive(&self) -> bool {
         false
     }
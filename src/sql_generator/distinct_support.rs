@@ -0,0 +1,104 @@
+// distinct() helpers (.keep_all deduplication).
+
+use super::assemble::{QueryParts, WhereCondition};
+use super::{GenerationResult, SqlGenerator};
+
+/// Row number of each row within its `distinct()` key, dropped again once
+/// the first row per key is picked.
+const DISTINCT_ROW_NUMBER: &str = "__distinct_row";
+
+impl SqlGenerator {
+    /// Processes `distinct(cols, .keep_all = TRUE)`: the first row of each
+    /// combination of `columns`, with all of its columns.
+    ///
+    /// Dialects with `DISTINCT ON` (PostgreSQL, DuckDB) render
+    /// `SELECT DISTINCT ON (cols) *`, ordered by the keys and then by the
+    /// arrange() keys, before or after the distinct(), that pick the row
+    /// kept: `arrange(desc(ts))` keeps the latest row per key.
+    ///
+    /// Elsewhere rows are numbered per key with `ROW_NUMBER() OVER (PARTITION BY
+    /// cols)`, ordered by a preceding arrange() so that "first" follows it,
+    /// and the rows numbered 1 are kept. The helper column is dropped with `*
+    /// EXCLUDE` where the dialect has it (DuckDB); elsewhere, e.g. on MySQL,
+    /// the schema must list the columns to project.
+    pub(super) fn process_distinct_keep_all(
+        &self,
+        columns: &[String],
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        if self.dialect.supports_distinct_on() {
+            return self.process_distinct_on(columns, query_parts, source_table);
+        }
+        let projection = match self
+            .dialect
+            .select_star_exclude(&[DISTINCT_ROW_NUMBER.to_string()])
+        {
+            Some(projection) => projection,
+            None => self
+                .current_columns(query_parts, source_table, "distinct(.keep_all = TRUE)")?
+                .iter()
+                .map(|column| self.dialect.quote_identifier(column))
+                .collect::<Vec<_>>()
+                .join(", "),
+        };
+        // The numbering consumes the ordering; the kept rows are sorted the
+        // same way again.
+        let order_by = std::mem::take(&mut query_parts.order_by);
+        let partition = columns
+            .iter()
+            .map(|column| self.dialect.quote_identifier(column))
+            .collect::<Vec<_>>()
+            .join(", ");
+        let window = if order_by.is_empty() {
+            format!("PARTITION BY {partition}")
+        } else {
+            format!("PARTITION BY {partition} ORDER BY {order_by}")
+        };
+
+        if !query_parts.is_bare_table() {
+            self.wrap_in_subquery(source_table, query_parts)?;
+        }
+        query_parts.select_columns = vec![
+            "*".to_string(),
+            self.column_alias(
+                &format!("ROW_NUMBER() OVER ({window})"),
+                DISTINCT_ROW_NUMBER,
+            ),
+        ];
+        self.wrap_in_subquery(source_table, query_parts)?;
+
+        query_parts.select_columns = vec![projection];
+        query_parts.where_clauses.push(WhereCondition::new(format!(
+            "{} = 1",
+            self.dialect.quote_identifier(DISTINCT_ROW_NUMBER)
+        )));
+        query_parts.order_by = order_by;
+        Ok(())
+    }
+
+    /// Renders `distinct(cols, .keep_all = TRUE)` as `SELECT DISTINCT ON
+    /// (cols) *`. A preceding arrange() follows the keys in the ORDER BY.
+    fn process_distinct_on(
+        &self,
+        columns: &[String],
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        let order_by = std::mem::take(&mut query_parts.order_by);
+        // The keys and the ordering must refer to input columns, not to a
+        // projection or a summary built so far.
+        if !query_parts.select_columns.is_empty() || query_parts.aggregation_group_by.is_some() {
+            self.wrap_in_subquery(source_table, query_parts)?;
+        }
+        query_parts.distinct_on = columns
+            .iter()
+            .map(|column| self.dialect.quote_identifier(column))
+            .collect::<Vec<_>>()
+            .join(", ");
+        if !order_by.is_empty() {
+            query_parts.order_by = format!("{}, {order_by}", query_parts.distinct_on);
+        }
+        Ok(())
+    }
+}
@@ -0,0 +1,124 @@
+// fill() helpers (window-based carry forward/backward of missing values).
+
+use super::assemble::QueryParts;
+use super::{FillDirection, GenerationError, GenerationResult, SqlGenerator};
+
+impl SqlGenerator {
+    /// Processes `fill(cols, .direction = ...)`.
+    ///
+    /// Rows are ordered by the preceding arrange() and partitioned by a
+    /// pending group_by(). "down" takes the last non-missing value up to the
+    /// current row, `LAST_VALUE(x IGNORE NULLS) OVER (... ROWS BETWEEN
+    /// UNBOUNDED PRECEDING AND CURRENT ROW)`; "up" takes the next one,
+    /// `FIRST_VALUE(x IGNORE NULLS) OVER (... ROWS BETWEEN CURRENT ROW AND
+    /// UNBOUNDED FOLLOWING)`. "downup"/"updown" COALESCE both in that order.
+    /// The filled values replace the columns in place.
+    pub(super) fn process_fill_operation(
+        &self,
+        columns: &[String],
+        direction: FillDirection,
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        if !self.dialect.supports_ignore_nulls() {
+            return Err(GenerationError::UnsupportedOperation {
+                operation: "fill".to_string(),
+                dialect: self.dialect.dialect_name().to_string(),
+            });
+        }
+        if query_parts.order_by.is_empty() {
+            return Err(GenerationError::InvalidAst {
+                reason: "fill() needs a preceding arrange() to define the row order".to_string(),
+            });
+        }
+
+        // Window functions cannot be nested: fill a mutated column from a
+        // derived table, keeping the ordering and grouping in effect.
+        if columns
+            .iter()
+            .any(|column| query_parts.mutated_columns.contains_key(column))
+        {
+            self.wrap_in_subquery_keeping_order(source_table, query_parts)?;
+        }
+
+        let partition = if query_parts.is_grouped() {
+            format!("PARTITION BY {} ", query_parts.group_by)
+        } else {
+            String::new()
+        };
+        let window = format!("{partition}ORDER BY {}", query_parts.order_by);
+
+        let mut replacements = Vec::new();
+        for column in columns {
+            let quoted = self.dialect.quote_identifier(column);
+            let down = format!(
+                "LAST_VALUE({quoted} IGNORE NULLS) OVER ({window} ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)"
+            );
+            let up = format!(
+                "FIRST_VALUE({quoted} IGNORE NULLS) OVER ({window} ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING)"
+            );
+            let filled = match direction {
+                FillDirection::Down => down,
+                FillDirection::Up => up,
+                FillDirection::DownUp => format!("COALESCE({down}, {up})"),
+                FillDirection::UpDown => format!("COALESCE({up}, {down})"),
+            };
+            query_parts
+                .mutated_columns
+                .insert(column.clone(), filled.clone());
+            replacements.push((column.as_str(), filled));
+        }
+
+        self.replace_projected_columns(&replacements, query_parts)
+    }
+
+    /// Swaps projected columns for `(column, expr)` replacements; an
+    /// implicit or explicit `*` becomes `* REPLACE (...)`.
+    fn replace_projected_columns(
+        &self,
+        replacements: &[(&str, String)],
+        query_parts: &mut QueryParts,
+    ) -> GenerationResult<()> {
+        if query_parts.select_columns.is_empty() {
+            query_parts.select_columns.push("*".to_string());
+        }
+
+        let mut star_replacements = Vec::new();
+        for (column, expr_sql) in replacements {
+            match self.projected_column_index(query_parts, column) {
+                Some(index) => {
+                    query_parts.select_columns[index] = self.column_alias(expr_sql, column)
+                }
+                // The REPLACE list always spells out AS.
+                None => star_replacements.push(format!(
+                    "{expr_sql} AS {}",
+                    self.dialect.quote_identifier(column)
+                )),
+            }
+        }
+
+        if star_replacements.is_empty() {
+            return Ok(());
+        }
+        let star = self
+            .dialect
+            .select_star_replace(&star_replacements)
+            .ok_or_else(|| GenerationError::UnsupportedOperation {
+                operation: "fill".to_string(),
+                dialect: self.dialect.dialect_name().to_string(),
+            })?;
+        match query_parts
+            .select_columns
+            .iter_mut()
+            .find(|item| *item == "*")
+        {
+            Some(item) => *item = star,
+            None => {
+                return Err(GenerationError::InvalidAst {
+                    reason: "fill() column is not part of the selected columns".to_string(),
+                })
+            }
+        }
+        Ok(())
+    }
+}
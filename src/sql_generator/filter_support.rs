@@ -0,0 +1,196 @@
+// Filter helpers (if_all()/if_any() predicates from filter_at()/filter_all()).
+
+use super::assemble::{QueryParts, WhereCondition};
+use super::summarise_support::is_condition;
+use super::{BinaryOp, Expr, GenerationError, GenerationResult, LiteralValue, SqlGenerator};
+use crate::parser::LAMBDA_PLACEHOLDER;
+
+impl SqlGenerator {
+    /// Expands `if_all(cols, pred)` / `if_any(cols, pred)` in a filter
+    /// condition into the predicate applied to each column, joined with AND
+    /// (`if_all`) or OR (`if_any`). The parsed `filter_at(cols,
+    /// all_vars(pred))` / `filter_all(...)` forms expand the same way, with
+    /// errors naming the verb that was written.
+    pub(super) fn expand_scoped_predicates(
+        &self,
+        expr: &Expr,
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Expr> {
+        match expr {
+            Expr::Function { name, args } if name == "if_all" || name == "if_any" => {
+                let [selection, predicate] = args.as_slice() else {
+                    return Err(GenerationError::InvalidAst {
+                        reason: format!("{name}() expects a column selection and a predicate"),
+                    });
+                };
+                self.expand_scoped_predicate(
+                    name,
+                    selection,
+                    predicate,
+                    name == "if_all",
+                    parts,
+                    source_table,
+                )
+            }
+            Expr::Function { name, args } if name == "filter_at" || name == "filter_all" => {
+                let [selection, Expr::Function {
+                    name: combinator,
+                    args: predicate,
+                }] = args.as_slice()
+                else {
+                    return Err(GenerationError::InvalidAst {
+                        reason: format!("{name}() expects all_vars() or any_vars()"),
+                    });
+                };
+                let [predicate] = predicate.as_slice() else {
+                    return Err(GenerationError::InvalidAst {
+                        reason: format!("{combinator}() expects one predicate"),
+                    });
+                };
+                self.expand_scoped_predicate(
+                    name,
+                    selection,
+                    predicate,
+                    combinator == "all_vars",
+                    parts,
+                    source_table,
+                )
+            }
+            Expr::Binary {
+                left,
+                operator,
+                right,
+            } => Ok(Expr::Binary {
+                left: Box::new(self.expand_scoped_predicates(left, parts, source_table)?),
+                operator: operator.clone(),
+                right: Box::new(self.expand_scoped_predicates(right, parts, source_table)?),
+            }),
+            Expr::Function { name, args } => Ok(Expr::Function {
+                name: name.clone(),
+                args: args
+                    .iter()
+                    .map(|arg| self.expand_scoped_predicates(arg, parts, source_table))
+                    .collect::<GenerationResult<_>>()?,
+            }),
+            _ => Ok(expr.clone()),
+        }
+    }
+
+    /// Applies `predicate` to each selected column, joined with AND (`all`)
+    /// or OR. `function` names the call in error messages.
+    fn expand_scoped_predicate(
+        &self,
+        function: &str,
+        selection: &Expr,
+        predicate: &Expr,
+        all: bool,
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Expr> {
+        let operator = if all { BinaryOp::And } else { BinaryOp::Or };
+        self.scoped_columns(selection, parts, source_table, function)?
+            .iter()
+            .map(|column| predicate.replace_identifier(LAMBDA_PLACEHOLDER, column))
+            .reduce(|left, right| Expr::Binary {
+                left: Box::new(left),
+                operator: operator.clone(),
+                right: Box::new(right),
+            })
+            .ok_or_else(|| GenerationError::InvalidAst {
+                reason: format!("{function}() selects no columns"),
+            })
+    }
+
+    /// Resolves the columns of a scoped predicate. Plain column names are
+    /// taken as given; selection helpers such as `everything()` need the
+    /// table schema.
+    pub(super) fn scoped_columns(
+        &self,
+        selection: &Expr,
+        parts: &QueryParts,
+        source_table: &str,
+        function: &str,
+    ) -> GenerationResult<Vec<String>> {
+        let items = match selection {
+            Expr::Vector(items) => items.as_slice(),
+            other => std::slice::from_ref(other),
+        };
+        let names: Option<Vec<String>> = items
+            .iter()
+            .map(|item| match item {
+                Expr::Identifier(name) => Some(name.clone()),
+                _ => None,
+            })
+            .collect();
+        if let Some(names) = names {
+            return Ok(names);
+        }
+
+        let current = self.current_columns(parts, source_table, &format!("{function}()"))?;
+        self.resolve_column_selection(items, &current, parts, source_table)
+    }
+
+    /// Renders a filter() condition for the WHERE clause.
+    pub(super) fn where_condition(&self, condition: Expr) -> GenerationResult<WhereCondition> {
+        if !self.dialect.supports_boolean_values()
+            && condition == Expr::Literal(LiteralValue::Boolean(false))
+        {
+            return Ok(WhereCondition::new("1 = 0".to_string()));
+        }
+        let condition = self.values_as_predicates(condition);
+        let sql = self.generate_expression(&condition)?;
+        // Binary operations are rendered in parentheses; `%/%` is the one
+        // exception, rendered through the dialect's floor division.
+        Ok(match condition {
+            Expr::Binary { operator, .. } if operator != BinaryOp::IntegerDivide => {
+                WhereCondition::enclosed(sql)
+            }
+            _ => WhereCondition::new(sql),
+        })
+    }
+
+    /// Turns the logical values of a filter condition into predicates
+    /// (`[is_active] = 1`) for dialects without boolean values, where
+    /// `WHERE [is_active]` is rejected. Comparisons and predicate functions
+    /// are left alone; `!`, `xor()`, `&` and `|` are searched for operands.
+    pub(super) fn values_as_predicates(&self, condition: Expr) -> Expr {
+        if self.dialect.supports_boolean_values() {
+            return condition;
+        }
+        value_as_predicate(condition)
+    }
+}
+
+fn value_as_predicate(expr: Expr) -> Expr {
+    match expr {
+        Expr::Binary {
+            left,
+            operator: operator @ (BinaryOp::And | BinaryOp::Or),
+            right,
+        } => Expr::Binary {
+            left: Box::new(value_as_predicate(*left)),
+            operator,
+            right: Box::new(value_as_predicate(*right)),
+        },
+        Expr::Function { name, args } if name == "!" || name == "xor" => Expr::Function {
+            name,
+            args: args.into_iter().map(value_as_predicate).collect(),
+        },
+        expr if is_condition(&expr) => expr,
+        Expr::Literal(LiteralValue::Boolean(value)) => Expr::Binary {
+            left: Box::new(Expr::Literal(LiteralValue::Number(1.0))),
+            operator: BinaryOp::Equal,
+            right: Box::new(Expr::Literal(LiteralValue::Number(if value {
+                1.0
+            } else {
+                0.0
+            }))),
+        },
+        expr => Expr::Binary {
+            left: Box::new(expr),
+            operator: BinaryOp::Equal,
+            right: Box::new(Expr::Literal(LiteralValue::Number(1.0))),
+        },
+    }
+}
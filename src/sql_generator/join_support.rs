@@ -0,0 +1,88 @@
+// Join helpers (join conditions across chained joins).
+
+use super::assemble::QueryParts;
+use super::{GenerationError, GenerationResult, JoinSpec, SqlGenerator};
+
+impl SqlGenerator {
+    /// Builds the ON condition of a join, or `None` for a natural join.
+    ///
+    /// `by = "col"` is qualified with the first table of the query so far
+    /// (base table, then earlier joins) whose schema has `col`, so a chain
+    /// such as `orders %>% left_join(customers, by = "customer_id") %>%
+    /// left_join(regions, by = "region_id")` compares `customers.region_id`
+    /// when only `customers` carries the key. Without `by`, the join uses the
+    /// columns shared with the tables so far when the schema knows them all,
+    /// and falls back to a NATURAL join otherwise.
+    pub(super) fn join_condition(
+        &self,
+        spec: &JoinSpec,
+        query_parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Option<String>> {
+        if let Some(by_column) = &spec.by_column {
+            let owner = self
+                .left_join_tables(query_parts, source_table)
+                .into_iter()
+                .find(|table| {
+                    self.options
+                        .table_columns(table)
+                        .is_some_and(|columns| columns.contains(&by_column.as_str()))
+                })
+                .unwrap_or(source_table);
+            return Ok(Some(self.join_key_equality(owner, &spec.table, by_column)));
+        }
+
+        if let Some(expr) = &spec.on_expr {
+            return self.generate_expression(expr).map(Some);
+        }
+
+        let Some(right_columns) = self.options.table_columns(&spec.table) else {
+            return Ok(None);
+        };
+        let mut keys = Vec::new();
+        for table in self.left_join_tables(query_parts, source_table) {
+            let Some(columns) = self.options.table_columns(table) else {
+                return Ok(None);
+            };
+            for column in columns {
+                if right_columns.contains(&column) && !keys.iter().any(|(key, _)| *key == column) {
+                    keys.push((column, table));
+                }
+            }
+        }
+
+        if keys.is_empty() {
+            return Err(GenerationError::InvalidAst {
+                reason: format!(
+                    "join with '{}' has no 'by' and no common columns",
+                    spec.table
+                ),
+            });
+        }
+        Ok(Some(
+            keys.into_iter()
+                .map(|(column, owner)| self.join_key_equality(owner, &spec.table, column))
+                .collect::<Vec<_>>()
+                .join(" AND "),
+        ))
+    }
+
+    /// Tables on the left side of the next join, in query order.
+    fn left_join_tables<'a>(
+        &self,
+        query_parts: &'a QueryParts,
+        source_table: &'a str,
+    ) -> Vec<&'a str> {
+        std::iter::once(source_table)
+            .chain(query_parts.joined_tables.iter().map(String::as_str))
+            .collect()
+    }
+
+    fn join_key_equality(&self, left_table: &str, right_table: &str, column: &str) -> String {
+        format!(
+            "{} = {}",
+            self.dialect.quote_identifier_path(&[left_table, column]),
+            self.dialect.quote_identifier_path(&[right_table, column])
+        )
+    }
+}
@@ -0,0 +1,112 @@
+// Dialect portability lint.
+
+use super::{DplyrNode, DplyrOperation, Expr, JoinType, SqlGenerator};
+use crate::parser::{BinaryOp, SetOperation, SourceLocation};
+
+/// A construct that transpiles but may not run (or behave the same) on the
+/// target dialect.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct LintWarning {
+    /// dplyr operation containing the construct (e.g. "join", "filter")
+    pub operation: String,
+    /// Human-readable description of the portability problem
+    pub message: String,
+    /// Position of the operation in the dplyr source
+    pub location: SourceLocation,
+}
+
+impl SqlGenerator {
+    /// Reports constructs of `ast` that are not portable to this dialect.
+    pub fn lint(&self, ast: &DplyrNode) -> Vec<LintWarning> {
+        let mut warnings = Vec::new();
+        self.lint_node(ast, &mut warnings);
+        warnings
+    }
+
+    fn lint_node(&self, node: &DplyrNode, warnings: &mut Vec<LintWarning>) {
+        let DplyrNode::Pipeline { operations, .. } = node else {
+            return;
+        };
+        for operation in operations {
+            self.lint_operation(operation, warnings);
+        }
+    }
+
+    fn lint_operation(&self, operation: &DplyrOperation, warnings: &mut Vec<LintWarning>) {
+        let dialect = self.dialect.dialect_name();
+        let mut warn = |message: String| {
+            warnings.push(LintWarning {
+                operation: operation.operation_name().to_string(),
+                message,
+                location: operation.location().clone(),
+            });
+        };
+
+        match operation {
+            DplyrOperation::Join {
+                join_type: JoinType::Full,
+                ..
+            } if !self.dialect.supports_full_join() => {
+                warn(format!("FULL JOIN is not supported by {dialect}"));
+            }
+            DplyrOperation::SetOp {
+                operation: set_op @ (SetOperation::Intersect | SetOperation::SetDiff),
+                ..
+            } if !self.dialect.supports_intersect_except() => {
+                let keyword = if *set_op == SetOperation::Intersect {
+                    "INTERSECT"
+                } else {
+                    "EXCEPT"
+                };
+                warn(format!(
+                    "{keyword} is not supported by all {dialect} versions"
+                ));
+            }
+            DplyrOperation::Filter { condition, .. } => {
+                self.lint_expression(condition, &mut warn);
+            }
+            DplyrOperation::Mutate { assignments, .. } => {
+                for assignment in assignments {
+                    self.lint_expression(&assignment.expr, &mut warn);
+                }
+            }
+            DplyrOperation::Select { columns, .. } => {
+                for column in columns {
+                    self.lint_expression(&column.expr, &mut warn);
+                }
+            }
+            DplyrOperation::BindRows { source, .. }
+            | DplyrOperation::BindCols { source, .. }
+            | DplyrOperation::Subquery { source, .. } => {
+                self.lint_node(source, warnings);
+            }
+            _ => {}
+        }
+    }
+
+    fn lint_expression(&self, expr: &Expr, warn: &mut impl FnMut(String)) {
+        match expr {
+            Expr::Binary {
+                left,
+                operator,
+                right,
+            } => {
+                if *operator == BinaryOp::Like && !self.dialect.like_is_case_sensitive() {
+                    warn(format!(
+                        "%like% renders as LIKE, which matches case-insensitively on {}",
+                        self.dialect.dialect_name()
+                    ));
+                }
+                self.lint_expression(left, warn);
+                self.lint_expression(right, warn);
+            }
+            Expr::Function { args, .. } | Expr::Vector(args) => {
+                for arg in args {
+                    self.lint_expression(arg, warn);
+                }
+            }
+            Expr::NamedArg { value, .. } => self.lint_expression(value, warn),
+            Expr::Identifier(_) | Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => {}
+        }
+    }
+}
@@ -3,26 +3,68 @@
 //! Provides functionality to convert AST to various SQL dialects.
 
 use crate::error::{GenerationError, GenerationResult};
+use crate::options::{NullsOrdering, TranspileOptions};
+use crate::parser::optimize;
 use crate::parser::{
-    Aggregation, BinaryOp, ColumnExpr, DplyrNode, DplyrOperation, Expr, JoinSpec, JoinType,
-    LiteralValue, OrderDirection, OrderExpr, RenameSpec, SetOperation,
+    Aggregation, BinaryOp, ColumnExpr, DplyrNode, DplyrOperation, Expr, FillDirection,
+    GroupingSets, JoinSpec, JoinType, LiteralValue, OrderDirection, OrderExpr, RelocateAnchor,
+    RenameSpec, SampleSize, SetOperation, SourceLocation, SummariseGroups, TopNKind, WindowFrame,
 };
 
 // Decomposition scaffolding (“Tidy First”): these modules are placeholders to
 // enable incremental extraction from this large module without behavior changes.
 pub mod assemble;
+pub mod bind_support;
+pub mod comment_support;
+pub mod cte_support;
+pub mod date_support;
+pub mod debug_support;
 pub mod dialect;
+pub mod distinct_support;
+pub mod fill_support;
+pub mod filter_support;
+pub mod join_support;
+pub mod lint;
 pub mod mutate_support;
+pub mod rename_support;
+pub mod sample_support;
+pub mod select_support;
+pub mod strict_support;
+pub mod summarise_support;
+pub mod tribble_support;
 
-use assemble::QueryParts;
+use assemble::{QueryParts, WhereCondition};
+use select_support::projection_names;
 
+pub use assemble::ClauseFragments;
+pub use debug_support::DebugInfo;
 pub use dialect::{
-    DialectConfig, DuckDbDialect, MySqlDialect, PostgreSqlDialect, SqlDialect, SqliteDialect,
+    DialectConfig, DuckDbDialect, MySqlDialect, PostgreSqlDialect, SqlDialect, SqlServerDialect,
+    SqliteDialect,
 };
+pub use lint::LintWarning;
 
 /// SQL generator struct
 pub struct SqlGenerator {
     dialect: Box<dyn SqlDialect>,
+    options: TranspileOptions,
+    /// Source comments keyed by the location of their operation
+    comments: Vec<(SourceLocation, String)>,
+}
+
+/// Grouping and ordering applied to window functions of a mutate.
+#[derive(Debug, Clone, Copy, Default)]
+struct WindowContext<'a> {
+    /// Rendered GROUP BY keys, used as `PARTITION BY`
+    partition_by: &'a str,
+    /// Rendered ORDER BY of the preceding `arrange()`, used by window
+    /// functions without an explicit `order_by`
+    order_by: &'a str,
+    /// True inside `mutate()`, where `n()` and `cur_group_id()` describe the
+    /// group of the current row
+    in_mutate: bool,
+    /// Row frame of the mutate's window aggregates (`.frame`)
+    frame: Option<WindowFrame>,
 }
 
 #[derive(Clone, Copy)]
@@ -93,6 +135,20 @@ const LOG_FORMALS: &[NamedArgFormal] = &[
         default_sql: None,
     },
 ];
+const BETWEEN_FORMALS: &[NamedArgFormal] = &[
+    NamedArgFormal {
+        name: "x",
+        default_sql: None,
+    },
+    NamedArgFormal {
+        name: "left",
+        default_sql: None,
+    },
+    NamedArgFormal {
+        name: "right",
+        default_sql: None,
+    },
+];
 const UNARY_X_FORMALS: &[NamedArgFormal] = &[NamedArgFormal {
     name: "x",
     default_sql: None,
@@ -136,6 +192,87 @@ const IF_ELSE_FORMALS: &[NamedArgFormal] = &[
     },
 ];
 
+/// True for operations that can be applied to the `SELECT DISTINCT` query
+/// itself: they filter or order the unique rows without changing them.
+fn keeps_distinct_rows(operation: &DplyrOperation) -> bool {
+    match operation {
+        DplyrOperation::Filter { .. }
+        | DplyrOperation::Arrange { .. }
+        | DplyrOperation::TopN { .. }
+        | DplyrOperation::Slice { .. } => true,
+        DplyrOperation::Distinct { columns, .. } => columns.is_empty(),
+        _ => false,
+    }
+}
+
+/// True for head() and slice(), which take rows in their current order.
+fn pages_in_current_order(operation: &DplyrOperation) -> bool {
+    match operation {
+        DplyrOperation::TopN { order_by, .. } => order_by.is_empty(),
+        DplyrOperation::Slice { .. } => true,
+        _ => false,
+    }
+}
+
+/// Operations that can share a query with `DISTINCT ON`: a WHERE would
+/// filter before the deduplication, and other projections or orderings
+/// would change which row each key keeps.
+fn keeps_distinct_on_rows(operation: &DplyrOperation) -> bool {
+    matches!(
+        operation,
+        DplyrOperation::Arrange { .. }
+            | DplyrOperation::Slice { .. }
+            | DplyrOperation::TopN {
+                kind: TopNKind::Head,
+                ..
+            }
+    )
+}
+
+/// Replaces each run of whitespace with a single space; edge whitespace is
+/// collapsed but kept.
+fn collapse_whitespace(value: &str) -> String {
+    let mut collapsed = String::with_capacity(value.len());
+    let mut in_whitespace = false;
+    for ch in value.chars() {
+        if ch.is_whitespace() {
+            if !in_whitespace {
+                collapsed.push(' ');
+            }
+            in_whitespace = true;
+        } else {
+            collapsed.push(ch);
+            in_whitespace = false;
+        }
+    }
+    collapsed
+}
+
+/// Splits the logical named argument `flag` (`na.rm = TRUE`) off the
+/// arguments of `function`.
+fn take_logical_argument(
+    function: &str,
+    args: &[Expr],
+    flag: &str,
+) -> GenerationResult<(Option<bool>, Vec<Expr>)> {
+    let mut value = None;
+    let mut rest = Vec::with_capacity(args.len());
+    for arg in args {
+        match arg {
+            Expr::NamedArg { name, value: expr } if name == flag => match expr.as_ref() {
+                Expr::Literal(LiteralValue::Boolean(b)) => value = Some(*b),
+                _ => {
+                    return Err(GenerationError::InvalidAst {
+                        reason: format!("{function}() {flag} must be TRUE or FALSE"),
+                    })
+                }
+            },
+            other => rest.push(other.clone()),
+        }
+    }
+    Ok((value, rest))
+}
+
 fn named_argument_formals(function: &str) -> Option<&'static [NamedArgFormal]> {
     match function.to_ascii_lowercase().as_str() {
         "round" => Some(ROUND_FORMALS),
@@ -143,6 +280,7 @@ fn named_argument_formals(function: &str) -> Option<&'static [NamedArgFormal]> {
         "str_detect" => Some(STR_DETECT_FORMALS),
         "substr" => Some(SUBSTR_FORMALS),
         "log" => Some(LOG_FORMALS),
+        "between" => Some(BETWEEN_FORMALS),
         "abs" | "floor" | "ceiling" | "ceil" | "sqrt" | "sign" | "exp" | "log10" | "sin"
         | "cos" | "tan" | "asin" | "acos" | "atan" | "sinh" | "cosh" | "tanh" | "str_length"
         | "str_to_lower" | "str_to_upper" | "str_trim" | "nchar" | "nzchar" | "trimws"
@@ -163,7 +301,26 @@ impl SqlGenerator {
     ///
     /// * `dialect` - The SQL dialect to use
     pub fn new(dialect: Box<dyn SqlDialect>) -> Self {
-        Self { dialect }
+        Self::with_options(dialect, TranspileOptions::default())
+    }
+
+    /// Creates a new SQL generator with explicit transpilation options.
+    ///
+    /// # Arguments
+    ///
+    /// * `dialect` - The SQL dialect to use
+    /// * `options` - Generation options (see [`TranspileOptions`])
+    pub fn with_options(dialect: Box<dyn SqlDialect>, options: TranspileOptions) -> Self {
+        Self {
+            dialect,
+            options,
+            comments: Vec::new(),
+        }
+    }
+
+    /// Returns the options used by this generator.
+    pub fn options(&self) -> &TranspileOptions {
+        &self.options
     }
 
     /// Converts AST to SQL query.
@@ -176,18 +333,59 @@ impl SqlGenerator {
     ///
     /// Returns SQL query string on success, GenerationError on failure.
     pub fn generate(&self, ast: &DplyrNode) -> GenerationResult<String> {
-        match ast {
+        self.ensure_pipeline_depth(ast)?;
+        let optimized;
+        let ast = if self.options.optimize {
+            optimized = optimize(ast.clone());
+            &optimized
+        } else {
+            ast
+        };
+        let sql = match ast {
             DplyrNode::Pipeline {
                 source,
                 target,
                 operations,
                 ..
-            } => self.generate_pipeline(source, target, operations),
-            DplyrNode::DataSource { name, .. } => Ok(format!(
-                "SELECT * FROM {}",
-                self.dialect.quote_identifier(name)
-            )),
+            } => self.generate_pipeline(source, target, operations)?,
+            DplyrNode::DataSource { name, .. } => self.select_all_from(name)?,
+        };
+        let mut sql = self.with_explain_prefix(sql)?;
+        if self.options.trailing_newline {
+            sql.push('\n');
         }
+        Ok(sql)
+    }
+
+    /// Prefixes `sql` with the dialect's EXPLAIN keyword when `explain` or
+    /// `analyze` is set.
+    fn with_explain_prefix(&self, sql: String) -> GenerationResult<String> {
+        let analyze = self.options.analyze;
+        if !self.options.explain && !analyze {
+            return Ok(sql);
+        }
+        let prefix = self.dialect.explain_prefix(analyze).ok_or_else(|| {
+            GenerationError::UnsupportedOperation {
+                operation: if analyze {
+                    "explain analyze"
+                } else {
+                    "explain"
+                }
+                .to_string(),
+                dialect: self.dialect.dialect_name().to_string(),
+            }
+        })?;
+        Ok(format!("{prefix} {sql}"))
+    }
+
+    /// Rejects pipelines longer than `max_pipeline_depth` steps.
+    fn ensure_pipeline_depth(&self, ast: &DplyrNode) -> GenerationResult<()> {
+        let max_steps = self.options.max_pipeline_depth;
+        let steps = ast.step_count();
+        if max_steps > 0 && steps > max_steps {
+            return Err(GenerationError::MaxPipelineDepthExceeded { steps, max_steps });
+        }
+        Ok(())
     }
 
     /// Converts pipeline to SQL.
@@ -204,28 +402,32 @@ impl SqlGenerator {
             });
         }
 
-        let mut query_parts = QueryParts::new();
-        let mut aggregation_group_by = None;
-
         // Get the source table name for join operations
         let source_table = source.as_deref().unwrap_or("data");
+        if self.options.staged_cte {
+            return self.generate_staged_pipeline(source_table, operations);
+        }
+        let query_parts = self.build_query_parts(source_table, operations)?;
+
+        // Assemble final SQL query
+        self.assemble_query(source, &query_parts)
+    }
+
+    /// Processes `operations` in order and returns the finished query parts.
+    fn build_query_parts(
+        &self,
+        source_table: &str,
+        operations: &[DplyrOperation],
+    ) -> GenerationResult<QueryParts> {
+        let mut query_parts = QueryParts::new();
 
         // Process each operation in order
         for operation in operations {
             self.process_operation(operation, &mut query_parts, source_table)?;
-            if matches!(operation, DplyrOperation::Summarise { .. }) {
-                aggregation_group_by = if query_parts.group_by.is_empty() {
-                    None
-                } else {
-                    Some(query_parts.group_by.clone())
-                };
-            }
         }
 
-        query_parts.group_by = aggregation_group_by.unwrap_or_default();
-
-        // Assemble final SQL query
-        self.assemble_query(source, &query_parts)
+        query_parts.finalize_group_by();
+        Ok(query_parts)
     }
 
     /// Processes individual operations.
@@ -235,45 +437,139 @@ impl SqlGenerator {
         query_parts: &mut QueryParts,
         source_table: &str,
     ) -> GenerationResult<()> {
+        // Anything after a LIMIT works on the limited rows, and anything
+        // reshaping the rows or the projection after distinct() works on the
+        // unique rows.
+        if query_parts.limit.is_some()
+            || query_parts.offset.is_some()
+            || (query_parts.distinct && !keeps_distinct_rows(operation))
+            // The `ORDER BY (SELECT NULL)` a T-SQL limit needs is not a
+            // selected column, which SELECT DISTINCT requires.
+            || (query_parts.distinct
+                && query_parts.order_by.is_empty()
+                && self.dialect.limit_requires_order_by()
+                && pages_in_current_order(operation))
+            || (!query_parts.distinct_on.is_empty() && !keeps_distinct_on_rows(operation))
+        {
+            self.wrap_in_subquery(source_table, query_parts)?;
+        }
+        self.record_operation_comments(operation, query_parts);
+
         match operation {
             DplyrOperation::Select { columns, .. } => {
+                let columns = self.expand_select_helpers(columns, query_parts, source_table)?;
+                // Computed columns (`select(total = price * qty)`) using a
+                // column created by mutate() read it one level up.
+                let created = query_parts.mutated_columns.keys().cloned().collect();
+                if columns.iter().any(|col| {
+                    !matches!(col.expr, Expr::Identifier(_))
+                        && self.expression_references_columns(&col.expr, &created)
+                }) {
+                    self.wrap_in_subquery_keeping_order(source_table, query_parts)?;
+                }
                 query_parts.select_columns =
-                    self.generate_select_columns_with_mutations(columns, query_parts)?;
+                    self.generate_select_columns_with_mutations(&columns, query_parts)?;
+                query_parts.columns = projection_names(&columns);
             }
+            // filter(TRUE) keeps every row; emitting it would only add a
+            // `WHERE TRUE`. filter(FALSE) renders as `WHERE FALSE`, or `WHERE
+            // 1 = 0` where a logical value is not a predicate (SQL Server).
+            DplyrOperation::Filter {
+                condition: Expr::Literal(LiteralValue::Boolean(true)),
+                ..
+            } => {}
             DplyrOperation::Filter { condition, .. } => {
-                let where_clause = self.generate_expression(condition)?;
-                if query_parts.where_clauses.is_empty() {
-                    query_parts.where_clauses.push(where_clause);
-                } else {
-                    query_parts
-                        .where_clauses
-                        .push(format!("AND ({where_clause})"));
+                if query_parts.aggregation_group_by.is_some() {
+                    self.ensure_exact_rendering(
+                        "filter",
+                        "after summarise() renders as WHERE on aggregated columns",
+                    )?;
                 }
+                let condition =
+                    self.expand_scoped_predicates(condition, query_parts, source_table)?;
+                let where_clause = self.where_condition(condition)?;
+                query_parts.where_clauses.push(where_clause);
             }
-            DplyrOperation::Mutate { assignments, .. } => {
+            DplyrOperation::Mutate {
+                assignments,
+                frame,
+                by,
+                ..
+            } => {
                 // Handle mutate operations - may need subqueries for complex cases
-                self.process_mutate_operation(assignments, query_parts)?;
+                if by.is_empty() {
+                    self.process_mutate_operation(assignments, *frame, query_parts, source_table)?;
+                } else {
+                    self.process_mutate_by(assignments, *frame, by, query_parts, source_table)?;
+                }
             }
             DplyrOperation::Rename { renames, .. } => {
-                self.process_rename_operation(renames, query_parts)?;
+                self.process_rename_operation(renames, query_parts, source_table)?;
             }
-            DplyrOperation::Arrange { columns, .. } => {
-                query_parts.order_by = self.generate_order_by(columns)?;
+            DplyrOperation::RenameWith {
+                function, columns, ..
+            } => {
+                self.process_rename_with_operation(function, columns, query_parts, source_table)?;
             }
-            DplyrOperation::GroupBy { columns, .. } => {
-                query_parts.group_by = columns
+            DplyrOperation::Arrange {
+                columns, by_group, ..
+            } => {
+                let mut order = Vec::new();
+                if *by_group && query_parts.is_grouped() {
+                    // .by_group = TRUE sorts by the grouping columns first.
+                    order.extend(query_parts.group_columns.iter().map(|col| OrderExpr {
+                        column: col.clone(),
+                        direction: OrderDirection::Asc,
+                    }));
+                }
+                order.extend(columns.iter().cloned());
+                if query_parts.distinct {
+                    self.ensure_distinct_order_keys(&order, query_parts)?;
+                }
+                self.ensure_summary_order_keys(&order, query_parts, source_table)?;
+                // The keys replace any earlier ordering rather than extend it
+                // (dbplyr semantics); SQL sorts are not stable, so ties of
+                // the new keys are not kept in the earlier order either.
+                query_parts.order_by = self.generate_order_by(&order)?;
+                query_parts.order_columns = order.iter().map(|key| key.column.clone()).collect();
+                if !query_parts.distinct_on.is_empty() {
+                    // DISTINCT ON keeps the first row per key in this order.
+                    query_parts.order_by =
+                        format!("{}, {}", query_parts.distinct_on, query_parts.order_by);
+                }
+                query_parts.window_order_by.clear();
+            }
+            DplyrOperation::GroupBy {
+                columns, add, sets, ..
+            } => {
+                if !add {
+                    query_parts.group_columns.clear();
+                }
+                query_parts.grouping_sets = sets.clone();
+                for col in columns {
+                    if !query_parts.group_columns.contains(col) {
+                        query_parts.group_columns.push(col.clone());
+                    }
+                }
+                query_parts.group_by = query_parts
+                    .group_columns
                     .iter()
                     .map(|col| self.dialect.quote_identifier(col))
                     .collect::<Vec<_>>()
                     .join(", ");
             }
-            DplyrOperation::Summarise { aggregations, .. } => {
-                let mut select_columns = Vec::new();
-                if !query_parts.group_by.is_empty() {
-                    select_columns.push(query_parts.group_by.clone());
+            DplyrOperation::Summarise {
+                aggregations,
+                groups,
+                by,
+                ..
+            } => {
+                if by.is_empty() {
+                    self.process_summarise_operation(aggregations, query_parts, source_table)?;
+                    self.apply_summarise_groups(*groups, query_parts);
+                } else {
+                    self.process_summarise_by(aggregations, by, query_parts, source_table)?;
                 }
-                select_columns.extend(self.generate_aggregations(aggregations)?);
-                query_parts.select_columns = select_columns;
             }
             DplyrOperation::Join {
                 join_type, spec, ..
@@ -292,14 +588,134 @@ impl SqlGenerator {
                 };
                 query_parts.set_operation = Some((set_op_sql.to_string(), right_table.clone()));
             }
+            DplyrOperation::BindRows { source, .. } => {
+                self.process_bind_rows_operation(source, query_parts, source_table)?;
+            }
+            DplyrOperation::BindCols { source, .. } => {
+                self.process_bind_cols_operation(source, query_parts, source_table)?;
+            }
+            DplyrOperation::TopN {
+                kind, order_by, n, ..
+            } => {
+                // head() ignores groups and keeps the current row order.
+                if *kind != TopNKind::Head {
+                    if query_parts.is_grouped() {
+                        // Per-group top-n needs a window filter; not supported yet.
+                        return Err(GenerationError::UnsupportedOperation {
+                            operation: format!("grouped {}", operation.operation_name()),
+                            dialect: self.dialect.dialect_name().to_string(),
+                        });
+                    }
+                    query_parts.order_by = self.generate_order_by(order_by)?;
+                    query_parts.order_columns =
+                        order_by.iter().map(|key| key.column.clone()).collect();
+                }
+                query_parts.limit = Some(*n);
+            }
+            DplyrOperation::Relocate {
+                columns, anchor, ..
+            } => {
+                self.process_relocate_operation(
+                    columns,
+                    anchor.as_ref(),
+                    query_parts,
+                    source_table,
+                )?;
+            }
+            DplyrOperation::Fill {
+                columns, direction, ..
+            } => {
+                self.process_fill_operation(columns, *direction, query_parts, source_table)?;
+            }
+            DplyrOperation::Distinct {
+                columns, keep_all, ..
+            } if *keep_all && !columns.is_empty() => {
+                self.process_distinct_keep_all(columns, query_parts, source_table)?;
+            }
+            DplyrOperation::Distinct { columns, .. } => {
+                if !columns.is_empty() {
+                    let columns = columns
+                        .iter()
+                        .map(|col| ColumnExpr {
+                            expr: Expr::Identifier(col.clone()),
+                            alias: None,
+                        })
+                        .collect::<Vec<_>>();
+                    query_parts.select_columns =
+                        self.generate_select_columns_with_mutations(&columns, query_parts)?;
+                    query_parts.columns = projection_names(&columns);
+                }
+                // Without columns the current projection, narrowed by any
+                // earlier select(), is deduplicated as is.
+                query_parts.distinct = true;
+                self.drop_order_outside_distinct(query_parts);
+            }
+            DplyrOperation::Count {
+                columns,
+                name,
+                sort,
+                wt,
+                ..
+            } => {
+                self.process_count_operation(
+                    columns,
+                    name,
+                    *sort,
+                    wt.as_ref(),
+                    query_parts,
+                    source_table,
+                )?;
+            }
+            DplyrOperation::Subquery { source, .. } => {
+                if !query_parts.is_bare_table() {
+                    return Err(GenerationError::InvalidAst {
+                        reason: "a parenthesized pipeline must be the source of the pipeline"
+                            .to_string(),
+                    });
+                }
+                *query_parts = QueryParts::from_subquery(self.generate_nested_source(source)?);
+            }
+            DplyrOperation::Tribble { columns, rows, .. } => {
+                self.process_tribble_operation(columns, rows, query_parts)?;
+            }
+            DplyrOperation::SliceSample {
+                size, weight_by, ..
+            } => {
+                self.process_slice_sample_operation(
+                    *size,
+                    weight_by.as_ref(),
+                    query_parts,
+                    source_table,
+                )?;
+            }
+            DplyrOperation::Slice { offset, limit, .. } => {
+                if query_parts.is_grouped() {
+                    // Per-group positions need a window filter; not supported yet.
+                    return Err(GenerationError::UnsupportedOperation {
+                        operation: "grouped slice".to_string(),
+                        dialect: self.dialect.dialect_name().to_string(),
+                    });
+                }
+                // Positions follow the current row order, which is only
+                // defined after an arrange().
+                query_parts.offset = (*offset > 0).then_some(*offset);
+                query_parts.limit = *limit;
+            }
         }
         Ok(())
     }
 
+    /// Processes `rename(new = old, ...)`.
+    ///
+    /// A column the SELECT list names explicitly (from select() or mutate())
+    /// is renamed in place. Columns behind `*` are excluded from the star and
+    /// re-added under their new name at the end of the projection, since SQL
+    /// cannot rename inside `*`.
     fn process_rename_operation(
         &self,
         renames: &[RenameSpec],
         query_parts: &mut QueryParts,
+        source_table: &str,
     ) -> GenerationResult<()> {
         if renames.is_empty() {
             return Err(GenerationError::InvalidAst {
@@ -307,45 +723,87 @@ impl SqlGenerator {
             });
         }
 
-        let excluded = renames
-            .iter()
-            .map(|spec| spec.old_name.clone())
-            .collect::<Vec<_>>();
-
-        let star_exclude = self.dialect.select_star_exclude(&excluded).ok_or_else(|| {
-            GenerationError::UnsupportedOperation {
-                operation: "rename".to_string(),
-                dialect: self.dialect.dialect_name().to_string(),
+        let mut columns = self.known_columns(query_parts, source_table);
+        let mut excluded = Vec::new();
+        let mut appended = Vec::new();
+        for spec in renames {
+            let in_place =
+                self.projected_index_expanding_star(&spec.old_name, query_parts, source_table);
+            let source_sql = query_parts
+                .mutated_columns
+                .remove(&spec.old_name)
+                .unwrap_or_else(|| self.dialect.quote_identifier(&spec.old_name));
+            let item = self.column_alias(&source_sql, &spec.new_name);
+            match in_place {
+                Some(index) => query_parts.select_columns[index] = item,
+                None => {
+                    excluded.push(spec.old_name.clone());
+                    appended.push(item);
+                }
             }
-        })?;
+            let position = columns
+                .as_ref()
+                .and_then(|columns| columns.iter().position(|column| *column == spec.old_name));
+            if let (Some(columns), Some(position)) = (columns.as_mut(), position) {
+                // A column behind `*` is re-added at the end of the projection.
+                if in_place.is_some() {
+                    columns[position] = spec.new_name.clone();
+                } else {
+                    columns.remove(position);
+                    columns.push(spec.new_name.clone());
+                }
+            }
+            if let Some(position) = query_parts
+                .mutated_order
+                .iter()
+                .position(|column| *column == spec.old_name)
+            {
+                query_parts.mutated_order[position] = spec.new_name.clone();
+            }
+            // Later verbs refer to the new name, which is an alias here.
+            query_parts
+                .mutated_columns
+                .insert(spec.new_name.clone(), source_sql);
+        }
+        query_parts.columns = columns;
+        if excluded.is_empty() {
+            return Ok(());
+        }
+
+        // Without SELECT *, the assembly stage drops the renamed columns from
+        // the schema expansion instead of relying on `* EXCLUDE`.
+        let star_exclude = if self.options.no_select_star {
+            query_parts.star_exclusions.extend(excluded.iter().cloned());
+            "*".to_string()
+        } else {
+            self.dialect.select_star_exclude(&excluded).ok_or_else(|| {
+                GenerationError::UnsupportedOperation {
+                    operation: "rename".to_string(),
+                    dialect: self.dialect.dialect_name().to_string(),
+                }
+            })?
+        };
 
         if query_parts.select_columns.is_empty() {
             query_parts.select_columns.push(star_exclude);
         } else {
-            let mut replaced_star = false;
-            for col in &mut query_parts.select_columns {
-                if col == "*" {
-                    *col = star_exclude.clone();
-                    replaced_star = true;
+            match query_parts
+                .select_columns
+                .iter_mut()
+                .find(|item| *item == "*")
+            {
+                Some(item) => *item = star_exclude,
+                None => {
+                    return Err(GenerationError::InvalidAst {
+                        reason: format!(
+                            "rename() column '{}' is not part of the selected columns",
+                            excluded[0]
+                        ),
+                    })
                 }
             }
-            if !replaced_star {
-                return Err(GenerationError::InvalidAst {
-                    reason:
-                        "rename() currently requires an implicit '*' projection (no prior select())"
-                            .to_string(),
-                });
-            }
         }
-
-        for spec in renames {
-            query_parts.select_columns.push(format!(
-                "{} AS {}",
-                self.dialect.quote_identifier(&spec.old_name),
-                self.dialect.quote_identifier(&spec.new_name)
-            ));
-        }
-
+        query_parts.select_columns.extend(appended);
         Ok(())
     }
 
@@ -372,22 +830,19 @@ impl SqlGenerator {
                 };
 
                 // Generate the condition
-                let condition = if let Some(by_column) = &spec.by_column {
-                    format!(
-                        "{} = {}",
-                        self.dialect
-                            .quote_identifier_path(&[source_table, by_column]),
-                        self.dialect
-                            .quote_identifier_path(&[&spec.table, by_column])
-                    )
-                } else if let Some(expr) = &spec.on_expr {
-                    self.generate_expression(expr)?
-                } else {
-                    return Err(GenerationError::InvalidAst {
-                        reason: "join operation requires either 'by' parameter or 'on' condition"
-                            .to_string(),
-                    });
-                };
+                let condition = self
+                    .join_condition(spec, query_parts, source_table)?
+                    .ok_or_else(|| GenerationError::InvalidAst {
+                        reason: format!(
+                            "{} without 'by' needs a schema for '{}'",
+                            if *join_type == JoinType::Semi {
+                                "semi_join"
+                            } else {
+                                "anti_join"
+                            },
+                            spec.table
+                        ),
+                    })?;
 
                 // Create subquery: WHERE (NOT) EXISTS (SELECT 1 FROM right_table ON condition)
                 let subquery = format!(
@@ -396,11 +851,9 @@ impl SqlGenerator {
                 );
 
                 // Add as WHERE clause (SEMI/ANTI don't need actual JOIN)
-                if query_parts.where_clauses.is_empty() {
-                    query_parts.where_clauses.push(subquery);
-                } else {
-                    query_parts.where_clauses.push(format!("AND ({subquery})"));
-                }
+                query_parts
+                    .where_clauses
+                    .push(WhereCondition::new(subquery));
 
                 return Ok(());
             }
@@ -418,32 +871,16 @@ impl SqlGenerator {
         };
 
         // Generate ON clause based on join specification
-        let on_clause = if let Some(by_column) = &spec.by_column {
-            // by = "column_name" -> ON "source"."column" = "right_table"."column"
-            format!(
-                "{} = {}",
-                self.dialect
-                    .quote_identifier_path(&[source_table, by_column]),
-                self.dialect
-                    .quote_identifier_path(&[&spec.table, by_column])
-            )
-        } else if let Some(expr) = &spec.on_expr {
-            // Fallback to expression-based ON clause
-            self.generate_expression(expr)?
-        } else {
-            // No join condition specified
-            return Err(GenerationError::InvalidAst {
-                reason: "join operation requires either 'by' parameter or 'on' condition"
-                    .to_string(),
-            });
-        };
+        let on_clause = self.join_condition(spec, query_parts, source_table)?;
 
-        query_parts.joins.push(format!(
-            "{} {} ON {}",
-            join_sql,
-            self.dialect.quote_identifier(&spec.table),
-            on_clause
-        ));
+        if !matches!(join_type, JoinType::Semi | JoinType::Anti) {
+            query_parts.joined_tables.push(spec.table.clone());
+        }
+        let table = self.dialect.quote_identifier(&spec.table);
+        query_parts.joins.push(match on_clause {
+            Some(on_clause) => format!("{join_sql} {table} ON {on_clause}"),
+            None => format!("NATURAL {join_sql} {table}"),
+        });
 
         Ok(())
     }
@@ -457,43 +894,128 @@ impl SqlGenerator {
                     OrderDirection::Asc => "ASC",
                     OrderDirection::Desc => "DESC",
                 };
-                Ok(format!(
-                    "{} {}",
-                    self.dialect.quote_identifier(&col.column),
-                    direction
-                ))
+                let column = self.dialect.quote_identifier(&col.column);
+                Ok(self.with_nulls_ordering(format!("{column} {direction}"), &column))
             })
             .collect();
 
         Ok(order_items?.join(", "))
     }
 
+    /// Rejects sort keys missing from a DISTINCT projection on dialects that
+    /// only sort DISTINCT results by selected columns. In dplyr the column no
+    /// longer exists after `distinct(a, b)` either.
+    fn ensure_distinct_order_keys(
+        &self,
+        order: &[OrderExpr],
+        query_parts: &QueryParts,
+    ) -> GenerationResult<()> {
+        let projects_star = query_parts.select_columns.is_empty()
+            || query_parts
+                .select_columns
+                .iter()
+                .any(|item| item.starts_with('*'));
+        if projects_star || !self.dialect.requires_distinct_order_in_select() {
+            return Ok(());
+        }
+        match order.iter().find(|key| {
+            self.projected_column_index(query_parts, &key.column)
+                .is_none()
+        }) {
+            Some(key) => Err(GenerationError::InvalidAst {
+                reason: format!(
+                    "arrange() column '{}' is not part of the distinct() columns",
+                    key.column
+                ),
+            }),
+            None => Ok(()),
+        }
+    }
+
+    /// Rejects sort keys that are not output columns of a preceding
+    /// summarise()/count(), e.g. `n` after `count(x, name = "cnt")`. Only the
+    /// grouping columns and the aggregate aliases exist at that point.
+    fn ensure_summary_order_keys(
+        &self,
+        order: &[OrderExpr],
+        query_parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        if query_parts.aggregation_group_by.is_none() {
+            return Ok(());
+        }
+        let Some(columns) = &query_parts.columns else {
+            return Ok(());
+        };
+        match order.iter().find(|key| !columns.contains(&key.column)) {
+            Some(key) => Err(GenerationError::InvalidColumnReference {
+                column: key.column.clone(),
+                table: Some(source_table.to_string()),
+            }),
+            None => Ok(()),
+        }
+    }
+
+    /// Drops an earlier arrange() whose keys the DISTINCT projection leaves
+    /// out (`arrange(b) %>% distinct(a)`), on dialects that only sort
+    /// DISTINCT results by selected columns. As after summarise(), the row
+    /// order does not survive; dplyr's first-occurrence order has no SQL
+    /// equivalent.
+    fn drop_order_outside_distinct(&self, query_parts: &mut QueryParts) {
+        let projects_star = query_parts.select_columns.is_empty()
+            || query_parts
+                .select_columns
+                .iter()
+                .any(|item| item.starts_with('*'));
+        if query_parts.order_by.is_empty()
+            || projects_star
+            || !self.dialect.requires_distinct_order_in_select()
+        {
+            return;
+        }
+        let stale = query_parts.order_columns.is_empty()
+            || query_parts
+                .order_columns
+                .iter()
+                .any(|column| self.projected_column_index(query_parts, column).is_none());
+        if stale {
+            query_parts.order_by.clear();
+            query_parts.order_columns.clear();
+        }
+    }
+
+    /// Applies the `nulls_ordering` option to the sort key `key` of
+    /// `column`. Dialects without `NULLS FIRST/LAST` get a leading
+    /// `column IS NULL` key instead (false sorts before true), or `CASE WHEN
+    /// column IS NULL THEN 1 ELSE 0 END` where a predicate is not a value.
+    fn with_nulls_ordering(&self, key: String, column: &str) -> String {
+        let Some(nulls) = self.options.nulls_ordering else {
+            return key;
+        };
+        let booleans = self.dialect.supports_boolean_values();
+        match (self.dialect.supports_nulls_ordering(), nulls) {
+            (true, NullsOrdering::First) => format!("{key} NULLS FIRST"),
+            (true, NullsOrdering::Last) => format!("{key} NULLS LAST"),
+            (false, NullsOrdering::First) if booleans => format!("{column} IS NOT NULL, {key}"),
+            (false, NullsOrdering::Last) if booleans => format!("{column} IS NULL, {key}"),
+            (false, NullsOrdering::First) => {
+                format!("CASE WHEN {column} IS NULL THEN 0 ELSE 1 END, {key}")
+            }
+            (false, NullsOrdering::Last) => {
+                format!("CASE WHEN {column} IS NULL THEN 1 ELSE 0 END, {key}")
+            }
+        }
+    }
+
     /// Generates aggregate functions.
     fn generate_aggregations(&self, aggregations: &[Aggregation]) -> GenerationResult<Vec<String>> {
         aggregations
             .iter()
             .map(|agg| {
-                let func_name = self
-                    .dialect
-                    .translate_aggregate_function(&agg.function)
-                    .ok_or_else(|| GenerationError::UnsupportedAggregateFunction {
-                        function: agg.function.clone(),
-                        dialect: self.dialect.dialect_name().to_string(),
-                    })?;
-                let column_ref = if agg.function.to_lowercase() == "n" {
-                    "*".to_string()
-                } else {
-                    self.dialect.quote_identifier(&agg.column)
-                };
-
-                let expr = format!("{func_name}({column_ref})");
+                let expr = self.generate_aggregation(agg)?;
 
                 if let Some(alias) = &agg.alias {
-                    Ok(format!(
-                        "{} AS {}",
-                        expr,
-                        self.dialect.quote_identifier(alias)
-                    ))
+                    Ok(self.column_alias(&expr, alias))
                 } else {
                     Ok(expr)
                 }
@@ -501,57 +1023,148 @@ impl SqlGenerator {
             .collect()
     }
 
+    /// Renders the projection item `expr AS "alias"`, or `expr "alias"` with
+    /// the `implicit_alias` option.
+    pub(super) fn column_alias(&self, expr_sql: &str, alias: &str) -> String {
+        format!(
+            "{expr_sql}{}{}",
+            self.alias_separator(),
+            self.dialect.quote_identifier(alias)
+        )
+    }
+
+    /// Text between a projected expression and its alias.
+    pub(super) fn alias_separator(&self) -> &'static str {
+        if self.options.implicit_alias {
+            " "
+        } else {
+            " AS "
+        }
+    }
+
     /// Converts expressions to SQL.
     fn generate_expression(&self, expr: &Expr) -> GenerationResult<String> {
-        self.generate_expression_with_window_partition(expr, "")
+        self.generate_expression_with_window_partition(expr, WindowContext::default())
     }
 
     fn generate_expression_with_window_partition(
         &self,
         expr: &Expr,
-        partition_by: &str,
+        window: WindowContext<'_>,
     ) -> GenerationResult<String> {
         match expr {
             Expr::Identifier(name) => Ok(self.dialect.quote_identifier(name)),
+            Expr::QualifiedIdentifier { table, column } => {
+                Ok(self.dialect.quote_identifier_path(&[table, column]))
+            }
             Expr::Literal(literal) => self.generate_literal(literal),
             Expr::Binary {
                 left,
                 operator,
                 right,
             } => {
-                let left_sql =
-                    self.generate_expression_with_window_partition(left, partition_by)?;
-                let right_sql =
-                    self.generate_expression_with_window_partition(right, partition_by)?;
+                let left_sql = self.generate_expression_with_window_partition(left, window)?;
+                let right_sql = if *operator == BinaryOp::In {
+                    self.generate_value_list(right, window)?
+                } else {
+                    self.generate_expression_with_window_partition(right, window)?
+                };
+                if *operator == BinaryOp::IntegerDivide {
+                    return Ok(self.dialect.integer_divide(&left_sql, &right_sql));
+                }
                 let op_sql = self.generate_binary_operator(operator);
                 Ok(format!("({left_sql} {op_sql} {right_sql})"))
             }
             Expr::Function { name, args } => {
-                self.generate_function_expression_with_window_partition(name, args, partition_by)
+                self.generate_function_expression_with_window_partition(name, args, window)
             }
             Expr::NamedArg { name, .. } => Err(GenerationError::InvalidAst {
                 reason: format!("named argument '{name}' cannot be used outside a function call"),
             }),
+            Expr::Vector(_) => Err(GenerationError::InvalidAst {
+                reason: "c() vectors can only be used with %in% or as column selections"
+                    .to_string(),
+            }),
         }
     }
 
+    /// Renders the right side of `%in%` as a parenthesized value list; a
+    /// single value counts as a one-element vector.
+    fn generate_value_list(
+        &self,
+        expr: &Expr,
+        window: WindowContext<'_>,
+    ) -> GenerationResult<String> {
+        let items = match expr {
+            Expr::Vector(items) => items.as_slice(),
+            other => std::slice::from_ref(other),
+        };
+        if items.is_empty() {
+            return Err(GenerationError::InvalidAst {
+                reason: "%in% requires at least one value".to_string(),
+            });
+        }
+        let values = items
+            .iter()
+            .map(|item| self.generate_expression_with_window_partition(item, window))
+            .collect::<GenerationResult<Vec<_>>>()?;
+        Ok(format!("({})", values.join(", ")))
+    }
+
     fn generate_function_expression_with_window_partition(
         &self,
         name: &str,
         args: &[Expr],
-        partition_by: &str,
+        window: WindowContext<'_>,
     ) -> GenerationResult<String> {
+        if window.in_mutate {
+            if let Some(sql) = self.group_context_function(name, args, window)? {
+                return Ok(sql);
+            }
+            if let Some(sql) = self.window_aggregate_function(name, args, window)? {
+                return Ok(sql);
+            }
+        }
+
+        if let Some(mapped) = self.options.function_override(name) {
+            let args_str =
+                self.generate_function_arguments_with_window_partition(name, args, window)?;
+            return Ok(format!("{mapped}({})", args_str.join(", ")));
+        }
+
+        self.ensure_known_function(name)?;
+
         if name.eq_ignore_ascii_case("paste") {
-            return self.generate_paste_expression_with_window_partition(name, args, partition_by);
+            return self.generate_paste_expression_with_window_partition(name, args, window);
+        }
+        if name.eq_ignore_ascii_case("between") {
+            return self.generate_between_expression(name, args, window);
+        }
+        if name.eq_ignore_ascii_case("pmax") || name.eq_ignore_ascii_case("pmin") {
+            return self.generate_extremum_expression(name, args, window);
+        }
+        if name.eq_ignore_ascii_case("case_match") {
+            return self.generate_case_match_expression(args, window);
+        }
+        if name.eq_ignore_ascii_case("cast") {
+            return self.generate_cast_expression(args, window);
+        }
+        if date_support::is_date_function(name) {
+            return self.generate_date_function_expression(name, args, window);
+        }
+        if name.eq_ignore_ascii_case("str_starts") || name.eq_ignore_ascii_case("str_ends") {
+            return self.generate_affix_match_expression(name, args, window);
         }
 
         let args_str =
-            self.generate_function_arguments_with_window_partition(name, args, partition_by)?;
+            self.generate_function_arguments_with_window_partition(name, args, window)?;
 
-        if let Some(translated) =
-            self.dialect
-                .translate_function_with_window_partition(name, &args_str, partition_by)
-        {
+        if let Some(translated) = self.dialect.translate_function_in_window(
+            name,
+            &args_str,
+            window.partition_by,
+            window.order_by,
+        ) {
             return Ok(translated);
         }
 
@@ -565,13 +1178,13 @@ impl SqlGenerator {
         &self,
         function: &str,
         args: &[Expr],
-        partition_by: &str,
+        window: WindowContext<'_>,
     ) -> GenerationResult<Vec<String>> {
         let has_named_args = args.iter().any(|arg| matches!(arg, Expr::NamedArg { .. }));
         if !has_named_args {
             return args
                 .iter()
-                .map(|arg| self.generate_expression_with_window_partition(arg, partition_by))
+                .map(|arg| self.generate_expression_with_window_partition(arg, window))
                 .collect();
         }
 
@@ -616,10 +1229,10 @@ impl SqlGenerator {
                     }
 
                     slots[index] =
-                        Some(self.generate_expression_with_window_partition(value, partition_by)?);
+                        Some(self.generate_expression_with_window_partition(value, window)?);
                 }
                 _ => {
-                    let sql = self.generate_expression_with_window_partition(arg, partition_by)?;
+                    let sql = self.generate_expression_with_window_partition(arg, window)?;
                     while next_positional < slots.len() && slots[next_positional].is_some() {
                         next_positional += 1;
                     }
@@ -656,11 +1269,243 @@ impl SqlGenerator {
         Ok(normalized)
     }
 
+    /// Renders `case_match(x, "a" ~ 1, c("b", "c") ~ 2, .default = 0)` as the
+    /// simple CASE form `CASE x WHEN 'a' THEN 1 WHEN 'b' THEN 2 ... ELSE 0 END`;
+    /// a vector on the left of an arm matches any of its values. `NA` never
+    /// equals anything, so an `NA` value switches to the searched form
+    /// (`CASE WHEN x IS NULL THEN ... WHEN x = 'a' THEN ...`).
+    fn generate_case_match_expression(
+        &self,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<String> {
+        let invalid = |reason: &str| GenerationError::InvalidAst {
+            reason: format!("case_match() {reason}"),
+        };
+        let Some((subject, rest)) = args.split_first() else {
+            return Err(invalid("requires a value to match"));
+        };
+
+        let subject_sql = self.generate_expression_with_window_partition(subject, window)?;
+        let searched = rest.iter().any(|arg| match arg {
+            Expr::Function { name, args: arm } if name == "~" => match arm.first() {
+                Some(Expr::Vector(items)) => items
+                    .iter()
+                    .any(|item| matches!(item, Expr::Literal(LiteralValue::Null))),
+                Some(value) => matches!(value, Expr::Literal(LiteralValue::Null)),
+                None => false,
+            },
+            _ => false,
+        });
+        let mut sql = if searched {
+            "CASE".to_string()
+        } else {
+            format!("CASE {subject_sql}")
+        };
+        let mut has_arms = false;
+        let mut default = None;
+        for arg in rest {
+            match arg {
+                Expr::Function { name, args: arm } if name == "~" => {
+                    let [values, result] = arm.as_slice() else {
+                        return Err(invalid("arms must be `values ~ result`"));
+                    };
+                    let result = self.generate_expression_with_window_partition(result, window)?;
+                    let values = match values {
+                        Expr::Vector(items) => items.as_slice(),
+                        other => std::slice::from_ref(other),
+                    };
+                    for value in values {
+                        let condition = match value {
+                            Expr::Literal(LiteralValue::Null) if searched => {
+                                format!("{subject_sql} IS NULL")
+                            }
+                            _ => {
+                                let value =
+                                    self.generate_expression_with_window_partition(value, window)?;
+                                if searched {
+                                    format!("{subject_sql} = {value}")
+                                } else {
+                                    value
+                                }
+                            }
+                        };
+                        sql.push_str(&format!(" WHEN {condition} THEN {result}"));
+                        has_arms = true;
+                    }
+                }
+                Expr::NamedArg { name, value } if name == ".default" => {
+                    default = Some(self.generate_expression_with_window_partition(value, window)?);
+                }
+                _ => return Err(invalid("arguments must be `values ~ result` or .default")),
+            }
+        }
+        if !has_arms {
+            return Err(invalid("requires at least one arm"));
+        }
+        if let Some(default) = default {
+            sql.push_str(&format!(" ELSE {default}"));
+        }
+        sql.push_str(" END");
+        Ok(sql)
+    }
+
+    /// Renders `cast(x, "integer")` as `CAST(x AS INTEGER)`. Common type names
+    /// map to the dialect's spelling; other names pass through uppercased.
+    fn generate_cast_expression(
+        &self,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<String> {
+        let [value, Expr::Literal(LiteralValue::String(type_name))] = args else {
+            return Err(GenerationError::InvalidAst {
+                reason: "cast() expects a value and a type name string".to_string(),
+            });
+        };
+        let type_name = type_name.trim();
+        if type_name.is_empty()
+            || !type_name
+                .chars()
+                .all(|c| c.is_ascii_alphanumeric() || matches!(c, '_' | ' ' | '(' | ')' | ','))
+        {
+            return Err(GenerationError::InvalidAst {
+                reason: format!("cast() type '{type_name}' is not a valid SQL type name"),
+            });
+        }
+        let value_sql = self.generate_expression_with_window_partition(value, window)?;
+        Ok(format!(
+            "CAST({value_sql} AS {})",
+            self.dialect.cast_type(type_name)
+        ))
+    }
+
+    /// Renders `str_starts(x, "A")` as `x LIKE 'A%'` and `str_ends(x, "A")`
+    /// as `x LIKE '%A'`; `negate = TRUE` selects NOT LIKE. The pattern is
+    /// matched literally, so LIKE wildcards in it are escaped with `!`.
+    fn generate_affix_match_expression(
+        &self,
+        name: &str,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<String> {
+        let (negate, args) = take_logical_argument(name, args, "negate")?;
+        let [value, Expr::Literal(LiteralValue::String(pattern))] = args.as_slice() else {
+            return Err(GenerationError::InvalidAst {
+                reason: format!("{name}() expects a value and a pattern string"),
+            });
+        };
+        if !self.dialect.like_is_case_sensitive() {
+            self.ensure_exact_rendering(name, "LIKE compares case-insensitively")?;
+        }
+
+        let wildcards = self.dialect.like_wildcards();
+        let mut escaped = String::with_capacity(pattern.len());
+        for c in pattern.chars() {
+            if c == '!' || wildcards.contains(c) {
+                escaped.push('!');
+            }
+            escaped.push(c);
+        }
+        let needs_escape = escaped.len() != pattern.len();
+        let like_pattern = if name.eq_ignore_ascii_case("str_starts") {
+            format!("{escaped}%")
+        } else {
+            format!("%{escaped}")
+        };
+
+        let value_sql = self.generate_expression_with_window_partition(value, window)?;
+        let mut sql = format!(
+            "({value_sql} {}{} {}",
+            if negate == Some(true) { "NOT " } else { "" },
+            self.generate_binary_operator(&BinaryOp::Like),
+            self.dialect.quote_string(&like_pattern)
+        );
+        if needs_escape {
+            sql.push_str(" ESCAPE '!'");
+        }
+        sql.push(')');
+        Ok(sql)
+    }
+
+    /// Renders `pmax(...)` / `pmin(...)` as the dialect's GREATEST / LEAST.
+    ///
+    /// With `na.rm = TRUE` missing values are skipped; on dialects where
+    /// GREATEST returns NULL for any NULL argument, each argument falls back
+    /// to the others (`GREATEST(COALESCE(a, b), COALESCE(b, a))`), so the
+    /// result is NULL only when all arguments are.
+    fn generate_extremum_expression(
+        &self,
+        name: &str,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<String> {
+        let (na_rm, values) = take_logical_argument(name, args, "na.rm")?;
+        let mut values_sql =
+            self.generate_function_arguments_with_window_partition(name, &values, window)?;
+        if na_rm == Some(true) && !self.dialect.greatest_ignores_nulls() && values_sql.len() > 1 {
+            values_sql = (0..values_sql.len())
+                .map(|start| {
+                    let rotated = values_sql[start..]
+                        .iter()
+                        .chain(&values_sql[..start])
+                        .cloned()
+                        .collect::<Vec<_>>();
+                    format!("COALESCE({})", rotated.join(", "))
+                })
+                .collect();
+        }
+        self.dialect
+            .translate_function_in_window(name, &values_sql, window.partition_by, window.order_by)
+            .ok_or_else(|| GenerationError::UnsupportedFunction {
+                function: name.to_string(),
+                dialect: self.dialect.dialect_name().to_string(),
+            })
+    }
+
+    /// Renders `between(x, left, right)`. dplyr's bounds are inclusive
+    /// (`BETWEEN`); `inclusive = FALSE` selects the half-open range
+    /// `x >= left AND x < right`.
+    fn generate_between_expression(
+        &self,
+        name: &str,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<String> {
+        let (inclusive, bounds) = take_logical_argument(name, args, "inclusive")?;
+        let inclusive = inclusive.unwrap_or(true);
+        let bounds_sql =
+            self.generate_function_arguments_with_window_partition(name, &bounds, window)?;
+        if inclusive {
+            return self
+                .dialect
+                .translate_function_in_window(
+                    name,
+                    &bounds_sql,
+                    window.partition_by,
+                    window.order_by,
+                )
+                .ok_or_else(|| GenerationError::InvalidAst {
+                    reason: "between() expects x, left and right".to_string(),
+                });
+        }
+        let [x, left, right] = bounds_sql.as_slice() else {
+            return Err(GenerationError::InvalidAst {
+                reason: "between() expects x, left and right".to_string(),
+            });
+        };
+        Ok(format!(
+            "(({x} {} {left}) {} ({x} {} {right}))",
+            self.generate_binary_operator(&BinaryOp::GreaterThanOrEqual),
+            self.generate_binary_operator(&BinaryOp::And),
+            self.generate_binary_operator(&BinaryOp::LessThan),
+        ))
+    }
+
     fn generate_paste_expression_with_window_partition(
         &self,
         name: &str,
         args: &[Expr],
-        partition_by: &str,
+        window: WindowContext<'_>,
     ) -> GenerationResult<String> {
         let mut positional_args = Vec::new();
         let mut separator = self.dialect.quote_string(" ");
@@ -678,8 +1523,7 @@ impl SqlGenerator {
                             dialect: self.dialect.dialect_name().to_string(),
                         });
                     }
-                    separator =
-                        self.generate_expression_with_window_partition(value, partition_by)?;
+                    separator = self.generate_expression_with_window_partition(value, window)?;
                     seen_separator = true;
                 }
                 Expr::NamedArg { name: arg_name, .. } => {
@@ -690,7 +1534,7 @@ impl SqlGenerator {
                     });
                 }
                 _ => positional_args
-                    .push(self.generate_expression_with_window_partition(arg, partition_by)?),
+                    .push(self.generate_expression_with_window_partition(arg, window)?),
             }
         }
 
@@ -705,33 +1549,19 @@ impl SqlGenerator {
     /// Converts literal values to SQL.
     fn generate_literal(&self, literal: &LiteralValue) -> GenerationResult<String> {
         match literal {
+            LiteralValue::String(s) if self.options.collapse_whitespace_in_strings => {
+                Ok(self.dialect.quote_string(&collapse_whitespace(s)))
+            }
             LiteralValue::String(s) => Ok(self.dialect.quote_string(s)),
             LiteralValue::Number(n) => Ok(n.to_string()),
-            LiteralValue::Boolean(b) => Ok(if *b {
-                "TRUE".to_string()
-            } else {
-                "FALSE".to_string()
-            }),
+            LiteralValue::Boolean(b) => Ok(self.dialect.boolean_literal(*b).to_string()),
             LiteralValue::Null => Ok("NULL".to_string()),
         }
     }
 
     /// Converts binary operators to SQL.
-    const fn generate_binary_operator(&self, operator: &BinaryOp) -> &'static str {
-        match operator {
-            BinaryOp::Equal => "=",
-            BinaryOp::NotEqual => "!=",
-            BinaryOp::LessThan => "<",
-            BinaryOp::LessThanOrEqual => "<=",
-            BinaryOp::GreaterThan => ">",
-            BinaryOp::GreaterThanOrEqual => ">=",
-            BinaryOp::And => "AND",
-            BinaryOp::Or => "OR",
-            BinaryOp::Plus => "+",
-            BinaryOp::Minus => "-",
-            BinaryOp::Multiply => "*",
-            BinaryOp::Divide => "/",
-        }
+    fn generate_binary_operator(&self, operator: &BinaryOp) -> &'static str {
+        self.dialect.binary_operator(operator)
     }
 }
 
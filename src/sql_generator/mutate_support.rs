@@ -1,7 +1,11 @@
 // Mutate-related helpers.
 
+use super::summarise_support::{aggregate_argument, is_condition};
 use super::QueryParts;
-use super::{ColumnExpr, Expr, GenerationResult, SqlGenerator};
+use super::{
+    ColumnExpr, Expr, GenerationError, GenerationResult, SqlGenerator, WindowContext, WindowFrame,
+};
+use crate::parser::{across_output_names, LAMBDA_PLACEHOLDER};
 
 impl SqlGenerator {
     /// Generates SELECT columns, inlining any columns created by previous mutate() calls.
@@ -29,11 +33,7 @@ impl SqlGenerator {
 
                 let alias = col.alias.as_deref().or(implicit_alias);
                 if let Some(alias) = alias {
-                    Ok(format!(
-                        "{} AS {}",
-                        expr_sql,
-                        self.dialect.quote_identifier(alias)
-                    ))
+                    Ok(self.column_alias(&expr_sql, alias))
                 } else {
                     Ok(expr_sql)
                 }
@@ -43,10 +43,17 @@ impl SqlGenerator {
 
     /// Processes mutate operations with support for complex expressions and subqueries.
     ///
+    /// SQL cannot reference a select-list alias from the same SELECT, so an
+    /// assignment using a column created earlier in the same mutate()
+    /// (`mutate(a = x + 1, b = a * 2)`) starts a new query level reading
+    /// from the previous one. Strict mode rejects such dependencies instead.
+    ///
     /// # Arguments
     ///
     /// * `assignments` - Vector of column assignments from mutate operation
+    /// * `frame` - Row frame of the window aggregates (`.frame`), if any
     /// * `query_parts` - Mutable reference to query parts being built
+    /// * `source_table` - Table the pipeline reads from
     ///
     /// # Returns
     ///
@@ -54,18 +61,150 @@ impl SqlGenerator {
     pub(super) fn process_mutate_operation(
         &self,
         assignments: &[crate::parser::Assignment],
+        frame: Option<WindowFrame>,
         query_parts: &mut QueryParts,
+        source_table: &str,
     ) -> GenerationResult<()> {
-        // Check if we need subqueries for complex expressions
-        let needs_subquery = self.mutate_needs_subquery(assignments, query_parts);
+        // Summary columns only exist on top of the aggregate query; the
+        // grouping left by summarise() still partitions the new columns, and
+        // an arrange() of the summary (`count(g) %>% arrange(desc(n))`) still
+        // orders them, as its keys are output columns of the summary.
+        if query_parts.aggregation_group_by.is_some() {
+            let grouping = (
+                query_parts.group_by.clone(),
+                query_parts.group_columns.clone(),
+            );
+            let order = (
+                query_parts.order_by.clone(),
+                query_parts.order_columns.clone(),
+            );
+            self.wrap_in_subquery(source_table, query_parts)?;
+            (query_parts.group_by, query_parts.group_columns) = grouping;
+            (query_parts.order_by, query_parts.order_columns) = order;
+        }
+        let assignments =
+            &self.expand_across_assignments(assignments, query_parts, source_table)?;
+
+        // Columns created by an earlier mutate() or rename() are aliases of
+        // the current SELECT and can only be referenced one level up.
+        let created = query_parts.mutated_columns.keys().cloned().collect();
+        if assignments
+            .iter()
+            .any(|assignment| self.expression_references_columns(&assignment.expr, &created))
+        {
+            self.wrap_in_subquery_keeping_order(source_table, query_parts)?;
+        }
+
+        if !self.mutate_needs_subquery(assignments, query_parts) {
+            return self.process_simple_mutate(assignments, frame, query_parts, source_table);
+        }
+
+        let mut stage_start = 0;
+        let mut defined = std::collections::HashSet::new();
+        for (index, assignment) in assignments.iter().enumerate() {
+            if self.expression_references_columns(&assignment.expr, &defined) {
+                self.ensure_exact_rendering(
+                    "mutate",
+                    &format!(
+                        "'{}' references a column created in the same mutate()",
+                        assignment.column
+                    ),
+                )?;
+                self.process_simple_mutate(
+                    &assignments[stage_start..index],
+                    frame,
+                    query_parts,
+                    source_table,
+                )?;
+                self.wrap_in_subquery_keeping_order(source_table, query_parts)?;
+                stage_start = index;
+                defined.clear();
+            }
+            defined.insert(assignment.column.clone());
+        }
+
+        self.process_simple_mutate(
+            &assignments[stage_start..],
+            frame,
+            query_parts,
+            source_table,
+        )
+    }
 
-        if needs_subquery {
-            // For complex cases, we'll use a simpler approach for now
-            // TODO: Implement full subquery/CTE support in future iterations
+    /// Processes `mutate(..., .by = c(a, b))`: window functions are
+    /// partitioned by `by` for this step only and the result is ungrouped.
+    pub(super) fn process_mutate_by(
+        &self,
+        assignments: &[crate::parser::Assignment],
+        frame: Option<WindowFrame>,
+        by: &[String],
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        if !query_parts.group_columns.is_empty() {
+            return Err(GenerationError::InvalidAst {
+                reason: "mutate(.by) cannot be used on grouped data".to_string(),
+            });
+        }
+        let window_order_by = query_parts.window_order_by.clone();
+        query_parts.group_columns = by.to_vec();
+        query_parts.group_by = by
+            .iter()
+            .map(|col| self.dialect.quote_identifier(col))
+            .collect::<Vec<_>>()
+            .join(", ");
+        self.process_mutate_operation(assignments, frame, query_parts, source_table)?;
+        query_parts.group_columns.clear();
+        query_parts.group_by.clear();
+        if query_parts.window_order_by != window_order_by {
+            // Nothing stays grouped, so the arrange() keys the windows used
+            // still sort the result rows.
+            query_parts.order_by =
+                std::mem::replace(&mut query_parts.window_order_by, window_order_by);
         }
+        Ok(())
+    }
 
-        // Simple mutate - add columns to SELECT clause
-        self.process_simple_mutate(assignments, query_parts)
+    /// Expands the across() calls the parser could not resolve, i.e. those
+    /// selecting columns with helpers such as `where(is.numeric)`, into one
+    /// assignment per selected column.
+    fn expand_across_assignments(
+        &self,
+        assignments: &[crate::parser::Assignment],
+        query_parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<crate::parser::Assignment>> {
+        let mut expanded = Vec::with_capacity(assignments.len());
+        for assignment in assignments {
+            match &assignment.expr {
+                Expr::Function { name, args } if name == "across" => {
+                    let [selection, body] = args.as_slice() else {
+                        return Err(GenerationError::InvalidAst {
+                            reason: "across() expects a column selection and a function"
+                                .to_string(),
+                        });
+                    };
+                    let columns =
+                        self.scoped_columns(selection, query_parts, source_table, name)?;
+                    let names =
+                        across_output_names(&assignment.column, &columns).map_err(|alias| {
+                            GenerationError::InvalidAst {
+                                reason: format!(
+                                    "across() .names gives several columns the name '{alias}'"
+                                ),
+                            }
+                        })?;
+                    for (column, output) in columns.iter().zip(names) {
+                        expanded.push(crate::parser::Assignment {
+                            column: output,
+                            expr: body.replace_identifier(LAMBDA_PLACEHOLDER, column),
+                        });
+                    }
+                }
+                _ => expanded.push(assignment.clone()),
+            }
+        }
+        Ok(expanded)
     }
 
     /// Determines if mutate operation needs subquery or CTE.
@@ -102,11 +241,105 @@ impl SqlGenerator {
         false
     }
 
+    /// Renders the group-context functions of a mutate as window functions:
+    /// `n()` is the size of the row's group (`COUNT(*) OVER (PARTITION BY g)`)
+    /// and `cur_group_id()` its 1-based index in key order
+    /// (`DENSE_RANK() OVER (ORDER BY g)`). Returns `None` for other functions.
+    ///
+    /// In summarise() `n()` keeps its aggregate meaning.
+    pub(super) fn group_context_function(
+        &self,
+        name: &str,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<Option<String>> {
+        let name = name.to_ascii_lowercase();
+        if !matches!(name.as_str(), "n" | "cur_group_id") {
+            return Ok(None);
+        }
+        if !args.is_empty() {
+            return Err(GenerationError::InvalidAst {
+                reason: format!("{name}() takes no arguments"),
+            });
+        }
+
+        let keys = window.partition_by.trim();
+        let sql = match (name.as_str(), keys.is_empty()) {
+            ("n", true) => "COUNT(*) OVER ()".to_string(),
+            ("n", false) => format!("COUNT(*) OVER (PARTITION BY {keys})"),
+            // An ungrouped data frame is a single group.
+            (_, true) => "1".to_string(),
+            (_, false) => format!("DENSE_RANK() OVER (ORDER BY {keys})"),
+        };
+        Ok(Some(sql))
+    }
+
+    /// Renders aggregates of a mutate as window aggregates over the row's
+    /// group (`AVG(x) OVER (PARTITION BY g)`), and the cumulative functions
+    /// (`cumsum`, `cummean`, `cummin`, `cummax`) as aggregates over the rows
+    /// up to the current one in arrange() order (the engine's row order when
+    /// there is no arrange()). A `.frame` replaces that
+    /// frame (`ROWS BETWEEN 2 PRECEDING AND CURRENT ROW`) and orders the
+    /// plain aggregates too. A `function_map` entry only replaces the
+    /// function name. Returns `None` for other functions.
+    pub(super) fn window_aggregate_function(
+        &self,
+        name: &str,
+        args: &[Expr],
+        window: WindowContext<'_>,
+    ) -> GenerationResult<Option<String>> {
+        let lower = name.to_ascii_lowercase();
+        let (function, default_frame) = match cumulative_aggregate(&lower) {
+            Some(function) => (
+                function.to_string(),
+                Some(WindowFrame {
+                    start: None,
+                    end: Some(0),
+                }),
+            ),
+            None => match self.dialect.translate_aggregate_function(&lower) {
+                Some(function) => (function, None),
+                None => return Ok(None),
+            },
+        };
+        let function = self
+            .options
+            .function_override(name)
+            .map_or(function, str::to_string);
+        let frame = window.frame.or(default_frame);
+
+        let args_sql = args
+            .iter()
+            .map(|arg| Ok(aggregate_argument(arg, self.generate_expression(arg)?)))
+            .collect::<GenerationResult<Vec<_>>>()?;
+        let mut over = Vec::new();
+        let keys = window.partition_by.trim();
+        if !keys.is_empty() {
+            over.push(format!("PARTITION BY {keys}"));
+        }
+        if let Some(frame) = frame {
+            // Like the ranking functions, a frame without arrange() follows
+            // the engine's row order.
+            let order_by = window.order_by.trim();
+            if !order_by.is_empty() {
+                over.push(format!("ORDER BY {order_by}"));
+            }
+            over.push(frame_clause(frame));
+        }
+        Ok(Some(format!(
+            "{function}({}) OVER ({})",
+            args_sql.join(", "),
+            over.join(" ")
+        )))
+    }
+
     /// Processes simple mutate operations by adding columns to SELECT clause.
     fn process_simple_mutate(
         &self,
         assignments: &[crate::parser::Assignment],
+        frame: Option<WindowFrame>,
         query_parts: &mut QueryParts,
+        source_table: &str,
     ) -> GenerationResult<()> {
         // If no columns selected yet, implies all columns (*) are included
         if query_parts.select_columns.is_empty() {
@@ -114,23 +347,142 @@ impl SqlGenerator {
         }
 
         for assignment in assignments {
-            let expr_sql = self.generate_expression_with_window_partition(
+            let window = WindowContext {
+                partition_by: &query_parts.group_by,
+                order_by: if query_parts.order_by.is_empty() {
+                    &query_parts.window_order_by
+                } else {
+                    &query_parts.order_by
+                },
+                in_mutate: true,
+                frame,
+            };
+            let expr_sql = self.condition_as_value(
                 &assignment.expr,
-                &query_parts.group_by,
-            )?;
+                self.generate_expression_with_window_partition(&assignment.expr, window)?,
+            );
+            let index =
+                self.projected_index_expanding_star(&assignment.column, query_parts, source_table);
             query_parts
                 .mutated_columns
                 .insert(assignment.column.clone(), expr_sql.clone());
-            let column_expr = format!(
-                "{} AS {}",
-                expr_sql,
-                self.dialect.quote_identifier(&assignment.column)
-            );
-            query_parts.select_columns.push(column_expr);
+            if !query_parts.mutated_order.contains(&assignment.column) {
+                query_parts.mutated_order.push(assignment.column.clone());
+            }
+            if let Some(columns) = query_parts.columns.as_mut() {
+                if !columns.contains(&assignment.column) {
+                    columns.push(assignment.column.clone());
+                }
+            }
+            // An existing column keeps its position; new columns are appended.
+            let column_expr = self.column_alias(&expr_sql, &assignment.column);
+            match index {
+                Some(index) => query_parts.select_columns[index] = column_expr,
+                None => query_parts.select_columns.push(column_expr),
+            }
+        }
+
+        // Within groups the arrange() keys order the window functions
+        // (`OVER (PARTITION BY g ORDER BY x)`); they no longer sort the
+        // result rows, but later windows keep using them.
+        if query_parts.is_grouped()
+            && !query_parts.order_by.is_empty()
+            && assignments
+                .iter()
+                .any(|assignment| uses_row_order(&assignment.expr))
+        {
+            query_parts.window_order_by = std::mem::take(&mut query_parts.order_by);
         }
         Ok(())
     }
 
+    /// Makes a condition selectable as a column value. Dialects without
+    /// boolean values get `CASE WHEN cond THEN 1 ELSE 0 END`; elsewhere the
+    /// condition is used as is (`("price" > 100) AS "is_expensive"`).
+    fn condition_as_value(&self, expr: &Expr, sql: String) -> String {
+        if self.dialect.supports_boolean_values() || !is_condition(expr) {
+            return sql;
+        }
+        format!("CASE WHEN {sql} THEN 1 ELSE 0 END")
+    }
+
+    /// Returns the index of the projected item producing `column`. When the
+    /// column is behind the implicit `*`, the star is first replaced with the
+    /// columns it stands for, so that overwriting or renaming it keeps its
+    /// position instead of adding a second column of the same name. Without
+    /// a schema the star stays as is and `None` is returned.
+    pub(super) fn projected_index_expanding_star(
+        &self,
+        column: &str,
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> Option<usize> {
+        self.projected_column_index(query_parts, column)
+            .or_else(|| {
+                self.expand_star_around(column, query_parts, source_table);
+                self.projected_column_index(query_parts, column)
+            })
+    }
+
+    fn expand_star_around(&self, column: &str, query_parts: &mut QueryParts, source_table: &str) {
+        // An empty projection is the implicit `*`.
+        if query_parts.select_columns.is_empty() {
+            query_parts.select_columns.push("*".to_string());
+        }
+        let Some(star) = query_parts
+            .select_columns
+            .iter()
+            .position(|item| item == "*")
+        else {
+            return;
+        };
+        if !query_parts.joined_tables.is_empty() {
+            return;
+        }
+        // Columns created at this level are projected after the star.
+        let Some(columns) = self.known_columns(query_parts, source_table) else {
+            return;
+        };
+        let starred: Vec<String> = columns
+            .into_iter()
+            .filter(|name| !query_parts.mutated_order.contains(name))
+            .collect();
+        if !starred.iter().any(|name| name == column) {
+            return;
+        }
+        let expanded = starred
+            .iter()
+            .map(|name| self.dialect.quote_identifier(name));
+        query_parts.select_columns.splice(star..=star, expanded);
+    }
+
+    /// Returns the index of the projected item producing `column`, if the
+    /// SELECT list names it explicitly: the plain column, an item of an
+    /// explicit projection whose output names are known, or the `expr AS
+    /// "column"` item of a column created or renamed at this level.
+    pub(super) fn projected_column_index(&self, parts: &QueryParts, column: &str) -> Option<usize> {
+        let quoted = self.dialect.quote_identifier(column);
+        if let Some(index) = parts.select_columns.iter().position(|item| *item == quoted) {
+            return Some(index);
+        }
+        // Without a star, the items line up with the output names.
+        let aligned = parts.columns.as_ref().filter(|columns| {
+            columns.len() == parts.select_columns.len()
+                && !parts
+                    .select_columns
+                    .iter()
+                    .any(|item| item.starts_with('*'))
+        });
+        if let Some(columns) = aligned {
+            return columns.iter().position(|name| name == column);
+        }
+        let item = self.column_alias(parts.mutated_columns.get(column)?, column);
+        parts
+            .select_columns
+            .iter()
+            .position(|candidate| *candidate == item)
+    }
+
     /// Checks if expression references any of the given columns.
     #[allow(clippy::only_used_in_recursion)]
     pub(super) fn expression_references_columns(
@@ -144,11 +496,12 @@ impl SqlGenerator {
                 self.expression_references_columns(left, columns)
                     || self.expression_references_columns(right, columns)
             }
-            Expr::Function { args, .. } => args
+            Expr::Function { args, .. } | Expr::Vector(args) => args
                 .iter()
                 .any(|arg| self.expression_references_columns(arg, columns)),
             Expr::NamedArg { value, .. } => self.expression_references_columns(value, columns),
-            Expr::Literal(_) => false,
+            // A qualified name refers to a table column, never to an alias.
+            Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => false,
         }
     }
 
@@ -200,10 +553,12 @@ impl SqlGenerator {
 
         // Add mutated columns
         for assignment in assignments {
-            let column_expr = format!(
-                "{} AS {}",
-                self.generate_expression(&assignment.expr)?,
-                self.dialect.quote_identifier(&assignment.column)
+            let column_expr = self.column_alias(
+                &self.condition_as_value(
+                    &assignment.expr,
+                    self.generate_expression(&assignment.expr)?,
+                ),
+                &assignment.column,
             );
             outer_select.push(column_expr);
         }
@@ -217,3 +572,62 @@ impl SqlGenerator {
         Ok(query)
     }
 }
+
+/// Returns the SQL aggregate accumulated by a dplyr cumulative function.
+fn cumulative_aggregate(function: &str) -> Option<&'static str> {
+    match function {
+        "cumsum" => Some("SUM"),
+        "cummean" => Some("AVG"),
+        "cummin" => Some("MIN"),
+        "cummax" => Some("MAX"),
+        _ => None,
+    }
+}
+
+/// True when `expr` calls a window function that depends on the row order
+/// (ranking, offsets, first/last values, cumulative aggregates). A call with
+/// its own `order_by =` (`lead(x, order_by = id)`) does not use it.
+fn uses_row_order(expr: &Expr) -> bool {
+    match expr {
+        Expr::Function { name, args } => {
+            let name = name.to_ascii_lowercase();
+            let own_order = args
+                .iter()
+                .any(|arg| matches!(arg, Expr::NamedArg { name, .. } if name == "order_by"));
+            let ordered = matches!(
+                name.as_str(),
+                "row_number"
+                    | "rank"
+                    | "dense_rank"
+                    | "ntile"
+                    | "lag"
+                    | "lead"
+                    | "first"
+                    | "first_value"
+                    | "last"
+                    | "last_value"
+                    | "nth_value"
+            ) || cumulative_aggregate(&name).is_some();
+            (ordered && !own_order) || args.iter().any(uses_row_order)
+        }
+        Expr::Binary { left, right, .. } => uses_row_order(left) || uses_row_order(right),
+        Expr::NamedArg { value, .. } => uses_row_order(value),
+        Expr::Vector(items) => items.iter().any(uses_row_order),
+        Expr::Identifier(_) | Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => false,
+    }
+}
+
+/// Renders `frame` as a `ROWS BETWEEN ... AND ...` clause.
+fn frame_clause(frame: WindowFrame) -> String {
+    let bound = |offset: Option<i64>, unbounded: &str| match offset {
+        None => format!("UNBOUNDED {unbounded}"),
+        Some(0) => "CURRENT ROW".to_string(),
+        Some(offset) if offset < 0 => format!("{} PRECEDING", -offset),
+        Some(offset) => format!("{offset} FOLLOWING"),
+    };
+    format!(
+        "ROWS BETWEEN {} AND {}",
+        bound(frame.start, "PRECEDING"),
+        bound(frame.end, "FOLLOWING")
+    )
+}
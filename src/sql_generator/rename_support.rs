@@ -0,0 +1,134 @@
+// Rename helpers (rename_with name functions).
+
+use super::assemble::QueryParts;
+use super::{
+    ColumnExpr, Expr, GenerationError, GenerationResult, LiteralValue, RenameSpec, SqlGenerator,
+};
+use crate::parser::LAMBDA_PLACEHOLDER;
+
+/// Functions rename_with() can apply to column names.
+const NAME_FUNCTIONS: &[&str] = &[
+    "toupper",
+    "tolower",
+    "str_to_upper",
+    "str_to_lower",
+    "paste0",
+    "paste",
+];
+
+impl SqlGenerator {
+    /// Processes `rename_with(fn, cols)` as a rename() of every selected
+    /// column to `fn` applied to its name. Plain column names are used as
+    /// given; selection helpers need the table schema.
+    ///
+    /// When the current columns are known, the result is an explicit
+    /// projection (`"id" AS "ID", "name"`) that works on every dialect and
+    /// keeps the column order; otherwise the renames go through rename().
+    pub(super) fn process_rename_with_operation(
+        &self,
+        function: &Expr,
+        columns: &Expr,
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        let mut renames = Vec::new();
+        for column in self.scoped_columns(columns, query_parts, source_table, "rename_with")? {
+            let new_name = renamed_column(function, &column)?;
+            if new_name != column {
+                renames.push(RenameSpec {
+                    new_name,
+                    old_name: column,
+                });
+            }
+        }
+        if renames.is_empty() {
+            return Ok(());
+        }
+        let current = self.known_columns(query_parts, source_table);
+        match current {
+            Some(current) if query_parts.joined_tables.is_empty() => {
+                self.project_renamed_columns(&current, &renames, query_parts)
+            }
+            _ => self.process_rename_operation(&renames, query_parts, source_table),
+        }
+    }
+
+    /// Projects `current` explicitly, each column in `renames` under its new name.
+    fn project_renamed_columns(
+        &self,
+        current: &[String],
+        renames: &[RenameSpec],
+        query_parts: &mut QueryParts,
+    ) -> GenerationResult<()> {
+        let new_name = |column: &str| {
+            renames
+                .iter()
+                .find(|spec| spec.old_name == column)
+                .map(|spec| spec.new_name.clone())
+        };
+        let columns: Vec<ColumnExpr> = current
+            .iter()
+            .map(|column| ColumnExpr {
+                expr: Expr::Identifier(column.clone()),
+                alias: new_name(column),
+            })
+            .collect();
+        query_parts.select_columns =
+            self.generate_select_columns_with_mutations(&columns, query_parts)?;
+
+        for spec in renames {
+            // Later verbs refer to the new name, which is an alias here.
+            let source_sql = query_parts
+                .mutated_columns
+                .remove(&spec.old_name)
+                .unwrap_or_else(|| self.dialect.quote_identifier(&spec.old_name));
+            query_parts
+                .mutated_columns
+                .insert(spec.new_name.clone(), source_sql);
+            if let Some(position) = query_parts
+                .mutated_order
+                .iter()
+                .position(|column| *column == spec.old_name)
+            {
+                query_parts.mutated_order[position] = spec.new_name.clone();
+            }
+        }
+        query_parts.columns = Some(
+            current
+                .iter()
+                .map(|column| new_name(column).unwrap_or_else(|| column.clone()))
+                .collect(),
+        );
+        Ok(())
+    }
+}
+
+/// Evaluates the rename_with() function for `column`: `.x` is the name,
+/// and `toupper`/`tolower`/`paste0`/`paste` work on strings.
+fn renamed_column(function: &Expr, column: &str) -> GenerationResult<String> {
+    match function {
+        Expr::Identifier(name) if name == LAMBDA_PLACEHOLDER => Ok(column.to_string()),
+        Expr::Literal(LiteralValue::String(text)) => Ok(text.clone()),
+        Expr::Function { name, args } if NAME_FUNCTIONS.contains(&name.as_str()) => {
+            let values = args
+                .iter()
+                .map(|arg| renamed_column(arg, column))
+                .collect::<GenerationResult<Vec<_>>>()?;
+            match (name.as_str(), values.as_slice()) {
+                ("toupper" | "str_to_upper", [value]) => Ok(value.to_uppercase()),
+                ("tolower" | "str_to_lower", [value]) => Ok(value.to_lowercase()),
+                ("paste0", values) => Ok(values.concat()),
+                ("paste", values) => Ok(values.join(" ")),
+                _ => Err(GenerationError::InvalidAst {
+                    reason: format!("{name}() in rename_with() takes a single name"),
+                }),
+            }
+        }
+        Expr::Function { name, .. } => Err(GenerationError::InvalidAst {
+            reason: format!("rename_with() cannot apply {name}() to column names"),
+        }),
+        _ => Err(GenerationError::InvalidAst {
+            reason: "rename_with() expects a function of .x on column names".to_string(),
+        }),
+    }
+}
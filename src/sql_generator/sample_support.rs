@@ -0,0 +1,96 @@
+// Random sampling helpers (slice_sample).
+
+use super::assemble::{QueryParts, WhereCondition};
+use super::{Expr, GenerationError, GenerationResult, SampleSize, SqlGenerator};
+
+impl SqlGenerator {
+    /// Processes `slice_sample(n = , prop = , weight_by = )`.
+    ///
+    /// Dialects with a sampling clause (DuckDB's `USING SAMPLE`) sample the
+    /// rows produced so far. Elsewhere `n` becomes `ORDER BY RANDOM() LIMIT
+    /// n` and `prop` keeps each row with probability `prop`, so its row count
+    /// is only approximate. Weighted samples order by `RANDOM() ^ (1 / w)`,
+    /// which draws rows with probability proportional to `w`.
+    ///
+    /// With the `seed` option the sampling clause or random function is
+    /// seeded so the same rows come back on every run.
+    pub(super) fn process_slice_sample_operation(
+        &self,
+        size: SampleSize,
+        weight_by: Option<&Expr>,
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        if query_parts.is_grouped() {
+            // Per-group sampling needs a window filter; not supported yet.
+            return Err(GenerationError::UnsupportedOperation {
+                operation: "grouped slice_sample".to_string(),
+                dialect: self.dialect.dialect_name().to_string(),
+            });
+        }
+        if matches!(size, SampleSize::Fraction(_)) {
+            self.ensure_exact_rendering(
+                "slice_sample",
+                "prop samples each row independently, so the row count is approximate",
+            )?;
+        }
+        if let Some(weight) = weight_by {
+            let SampleSize::Rows(n) = size else {
+                return Err(GenerationError::UnsupportedOperation {
+                    operation: "slice_sample(prop, weight_by)".to_string(),
+                    dialect: self.dialect.dialect_name().to_string(),
+                });
+            };
+            let weight_sql = self.generate_expression(weight)?;
+            let random = self.sample_random_fraction()?;
+            query_parts.order_by = format!("POWER({random}, 1.0 / {weight_sql}) DESC");
+            query_parts.limit = Some(n);
+            return Ok(());
+        }
+
+        if let Some(clause) = self.dialect.sample_clause(size, self.options.seed) {
+            // The clause samples the FROM input, so it reads the rows built
+            // so far from a derived table.
+            if !query_parts.is_bare_table() {
+                self.wrap_in_subquery(source_table, query_parts)?;
+            }
+            query_parts.select_columns = vec!["*".to_string()];
+            let sampled = self.assemble_current(source_table, query_parts)?;
+            *query_parts = QueryParts::from_subquery(format!("{sampled}\n{clause}"));
+            return Ok(());
+        }
+
+        let random = self.sample_random_fraction()?;
+        match size {
+            SampleSize::Rows(n) => {
+                query_parts.order_by = random;
+                query_parts.limit = Some(n);
+            }
+            SampleSize::Fraction(prop) => {
+                // WHERE runs before the projection, aggregates and windows.
+                if !query_parts.select_columns.is_empty()
+                    || query_parts.aggregation_group_by.is_some()
+                {
+                    self.wrap_in_subquery(source_table, query_parts)?;
+                }
+                query_parts
+                    .where_clauses
+                    .push(WhereCondition::new(format!("{random} < {prop}")));
+            }
+        }
+        Ok(())
+    }
+
+    /// Per-row random number of a sample, seeded when `seed` is set.
+    fn sample_random_fraction(&self) -> GenerationResult<String> {
+        match self.options.seed {
+            None => Ok(self.dialect.random_fraction().to_string()),
+            Some(seed) => self.dialect.seeded_random_fraction(seed).ok_or_else(|| {
+                GenerationError::UnsupportedOperation {
+                    operation: "seeded slice_sample".to_string(),
+                    dialect: self.dialect.dialect_name().to_string(),
+                }
+            }),
+        }
+    }
+}
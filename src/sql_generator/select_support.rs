@@ -0,0 +1,496 @@
+// Schema-backed select helpers (everything(), where(), ...) and relocate().
+
+use super::assemble::QueryParts;
+use super::{
+    ColumnExpr, Expr, GenerationError, GenerationResult, LiteralValue, RelocateAnchor, SqlGenerator,
+};
+
+/// Selection helpers resolved against the table schema.
+const SELECT_HELPERS: &[&str] = &[
+    "everything",
+    "where",
+    "last_col",
+    "num_range",
+    "starts_with",
+    "ends_with",
+    "contains",
+];
+
+/// True when `column` is a bare selection helper call such as `everything()`.
+fn is_select_helper(column: &ColumnExpr) -> bool {
+    column.alias.is_none()
+        && matches!(&column.expr, Expr::Function { name, .. } if SELECT_HELPERS.contains(&name.as_str()))
+}
+
+/// SQL type names matched by an R type predicate.
+fn type_predicate_names(predicate: &str) -> Option<&'static [&'static str]> {
+    match predicate {
+        "is.numeric" => Some(&[
+            "TINYINT",
+            "SMALLINT",
+            "INT",
+            "INTEGER",
+            "BIGINT",
+            "HUGEINT",
+            "UTINYINT",
+            "USMALLINT",
+            "UINTEGER",
+            "UBIGINT",
+            "DECIMAL",
+            "NUMERIC",
+            "REAL",
+            "FLOAT",
+            "DOUBLE",
+            "DOUBLE PRECISION",
+            "FLOAT4",
+            "FLOAT8",
+            "INT2",
+            "INT4",
+            "INT8",
+        ]),
+        "is.character" => Some(&["CHAR", "VARCHAR", "TEXT", "STRING", "CHARACTER VARYING"]),
+        "is.logical" => Some(&["BOOL", "BOOLEAN"]),
+        _ => None,
+    }
+}
+
+/// Normalizes a declared type such as `decimal(10, 2)` to `DECIMAL`.
+fn base_type_name(data_type: &str) -> String {
+    let base = data_type.split('(').next().unwrap_or(data_type);
+    base.trim().to_uppercase()
+}
+
+/// Converts a helper argument to a non-negative whole number.
+fn whole_number(value: f64, helper: &str) -> GenerationResult<usize> {
+    if value >= 0.0 && value.fract() == 0.0 {
+        Ok(value as usize)
+    } else {
+        Err(invalid_helper_arguments(helper))
+    }
+}
+
+fn invalid_helper_arguments(helper: &str) -> GenerationError {
+    GenerationError::InvalidAst {
+        reason: format!("unsupported arguments for select helper {helper}()"),
+    }
+}
+
+impl SqlGenerator {
+    /// Expands selection helpers into plain column references.
+    ///
+    /// Every entry resolves to a list of columns and a column selected more
+    /// than once keeps its first position, as in dplyr: `select(id,
+    /// everything())` moves `id` to the front and keeps the remaining columns
+    /// in table order. A selection matching no column is an error, as an
+    /// empty projection would render as `SELECT *`.
+    pub(super) fn expand_select_helpers(
+        &self,
+        columns: &[ColumnExpr],
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<ColumnExpr>> {
+        if !columns.iter().any(is_select_helper) {
+            return Ok(columns.to_vec());
+        }
+
+        let mut expanded: Vec<ColumnExpr> = Vec::new();
+        // Helpers that picked no column, named in the empty-selection error.
+        let mut unmatched: Vec<String> = Vec::new();
+        let mut push_column = |column: ColumnExpr| {
+            let duplicate = column.alias.is_none()
+                && matches!(column.expr, Expr::Identifier(_))
+                && expanded.contains(&column);
+            if !duplicate {
+                expanded.push(column);
+            }
+        };
+
+        for column in columns {
+            let Expr::Function { name, args } = &column.expr else {
+                push_column(column.clone());
+                continue;
+            };
+            if !is_select_helper(column) {
+                push_column(column.clone());
+                continue;
+            }
+
+            let picked = self.resolve_select_helper(name, args, parts, source_table)?;
+            if picked.is_empty() {
+                unmatched.push(match args.as_slice() {
+                    [Expr::Identifier(predicate)] if name == "where" => {
+                        format!("where({predicate})")
+                    }
+                    _ => format!("{name}()"),
+                });
+            }
+            for name in picked {
+                push_column(ColumnExpr {
+                    expr: Expr::Identifier(name),
+                    alias: None,
+                });
+            }
+        }
+
+        if expanded.is_empty() {
+            return Err(GenerationError::InvalidAst {
+                reason: format!(
+                    "select() matches no columns ({} selects none)",
+                    unmatched.join(", ")
+                ),
+            });
+        }
+        Ok(expanded)
+    }
+
+    /// Resolves one selection helper to the columns it picks.
+    fn resolve_select_helper(
+        &self,
+        helper: &str,
+        args: &[Expr],
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<String>> {
+        match (helper, args) {
+            ("everything", []) => self.current_columns(parts, source_table, "everything()"),
+            ("where", [Expr::Identifier(predicate)]) => {
+                self.resolve_where_helper(predicate, parts, source_table)
+            }
+            ("last_col", _) => self.resolve_last_col_helper(args, parts, source_table),
+            ("num_range", _) => self.resolve_num_range_helper(args, parts, source_table),
+            ("starts_with" | "ends_with" | "contains", _) => {
+                self.resolve_name_match_helper(helper, args, parts, source_table)
+            }
+            _ => Err(invalid_helper_arguments(helper)),
+        }
+    }
+
+    /// Resolves `starts_with("x")`, `ends_with("x")` and `contains("x")`.
+    /// Matching ignores case unless `ignore.case = FALSE`, as in dplyr.
+    fn resolve_name_match_helper(
+        &self,
+        helper: &str,
+        args: &[Expr],
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<String>> {
+        let mut pattern = None;
+        let mut ignore_case = true;
+        for arg in args {
+            match arg {
+                Expr::Literal(LiteralValue::String(text)) if pattern.is_none() => {
+                    pattern = Some(text.as_str())
+                }
+                Expr::NamedArg { name, value } if name == "ignore.case" => match value.as_ref() {
+                    Expr::Literal(LiteralValue::Boolean(flag)) => ignore_case = *flag,
+                    _ => return Err(invalid_helper_arguments(helper)),
+                },
+                _ => return Err(invalid_helper_arguments(helper)),
+            }
+        }
+        let pattern = pattern.ok_or_else(|| invalid_helper_arguments(helper))?;
+
+        let fold = |text: &str| {
+            if ignore_case {
+                text.to_lowercase()
+            } else {
+                text.to_string()
+            }
+        };
+        let pattern = fold(pattern);
+        let columns = self.current_columns(parts, source_table, &format!("{helper}()"))?;
+        Ok(columns
+            .into_iter()
+            .filter(|column| {
+                let column = fold(column);
+                match helper {
+                    "starts_with" => column.starts_with(&pattern),
+                    "ends_with" => column.ends_with(&pattern),
+                    _ => column.contains(&pattern),
+                }
+            })
+            .collect())
+    }
+
+    /// Resolves `where(is.numeric)` and friends using the column types
+    /// declared in the schema.
+    fn resolve_where_helper(
+        &self,
+        predicate: &str,
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<String>> {
+        let call = format!("where({predicate})");
+        let type_names =
+            type_predicate_names(predicate).ok_or_else(|| GenerationError::InvalidAst {
+                reason: format!("unsupported type predicate in {call}"),
+            })?;
+
+        let mut selected = Vec::new();
+        for column in self.current_columns(parts, source_table, &call)? {
+            let data_type = self
+                .options
+                .column_type(source_table, &column)
+                .ok_or_else(|| GenerationError::ColumnTypeRequired {
+                    operation: call.clone(),
+                    column: column.clone(),
+                })?;
+            if type_names.contains(&base_type_name(data_type).as_str()) {
+                selected.push(column);
+            }
+        }
+        Ok(selected)
+    }
+
+    /// Resolves `last_col(offset = 0)` to the column `offset` places from the end.
+    fn resolve_last_col_helper(
+        &self,
+        args: &[Expr],
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<String>> {
+        let offset = match args {
+            [] => 0,
+            [Expr::Literal(LiteralValue::Number(n))] => whole_number(*n, "last_col")?,
+            [Expr::NamedArg { name, value }] if name == "offset" => match value.as_ref() {
+                Expr::Literal(LiteralValue::Number(n)) => whole_number(*n, "last_col")?,
+                _ => return Err(invalid_helper_arguments("last_col")),
+            },
+            _ => return Err(invalid_helper_arguments("last_col")),
+        };
+
+        let columns = self.current_columns(parts, source_table, "last_col()")?;
+        let index =
+            columns
+                .len()
+                .checked_sub(offset + 1)
+                .ok_or_else(|| GenerationError::InvalidAst {
+                    reason: format!(
+                        "last_col(offset = {offset}) is out of range for {} columns",
+                        columns.len()
+                    ),
+                })?;
+        Ok(vec![columns[index].clone()])
+    }
+
+    /// Resolves `num_range("x", 1:3, width = n)` to the existing columns among
+    /// `x1`, `x2`, `x3` (numbers zero-padded to `width`).
+    fn resolve_num_range_helper(
+        &self,
+        args: &[Expr],
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<String>> {
+        let mut positional = Vec::new();
+        let mut width = 0;
+        for arg in args {
+            match arg {
+                Expr::NamedArg { name, value } if name == "width" => match value.as_ref() {
+                    Expr::Literal(LiteralValue::Number(n)) => {
+                        width = whole_number(*n, "num_range")?
+                    }
+                    _ => return Err(invalid_helper_arguments("num_range")),
+                },
+                Expr::NamedArg { .. } => return Err(invalid_helper_arguments("num_range")),
+                _ => positional.push(arg),
+            }
+        }
+
+        let [Expr::Literal(LiteralValue::String(prefix)), range] = positional.as_slice() else {
+            return Err(invalid_helper_arguments("num_range"));
+        };
+        let numbers = match range {
+            Expr::Literal(LiteralValue::Number(n)) => vec![whole_number(*n, "num_range")?],
+            Expr::Function { name, args } if name == ":" => match args.as_slice() {
+                [Expr::Literal(LiteralValue::Number(from)), Expr::Literal(LiteralValue::Number(to))] =>
+                {
+                    let from = whole_number(*from, "num_range")?;
+                    let to = whole_number(*to, "num_range")?;
+                    if from <= to {
+                        (from..=to).collect()
+                    } else {
+                        (to..=from).rev().collect()
+                    }
+                }
+                _ => return Err(invalid_helper_arguments("num_range")),
+            },
+            _ => return Err(invalid_helper_arguments("num_range")),
+        };
+
+        let columns = self.current_columns(parts, source_table, "num_range()")?;
+        Ok(numbers
+            .into_iter()
+            .map(|n| format!("{prefix}{n:0width$}"))
+            .filter(|name| columns.contains(name))
+            .collect())
+    }
+
+    /// Processes `relocate(cols, .before = x | .after = y)`.
+    ///
+    /// The moved columns keep their selection order and the remaining columns
+    /// their current order. Without an anchor the selection moves to the
+    /// front; an anchor resolving to several columns places the selection
+    /// before the first (`.before`) or after the last (`.after`) of them.
+    /// Anchors may use selection helpers: `.after = last_col()` moves the
+    /// selection to the end.
+    pub(super) fn process_relocate_operation(
+        &self,
+        columns: &[Expr],
+        anchor: Option<&RelocateAnchor>,
+        parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        let current = self.current_columns(parts, source_table, "relocate()")?;
+        let moved = self.resolve_column_selection(columns, &current, parts, source_table)?;
+        let mut order: Vec<String> = current
+            .iter()
+            .filter(|column| !moved.contains(column))
+            .cloned()
+            .collect();
+
+        let position = match anchor {
+            None => 0,
+            Some(RelocateAnchor::Before(expr) | RelocateAnchor::After(expr)) => {
+                let targets = self.resolve_column_selection(
+                    std::slice::from_ref(expr),
+                    &current,
+                    parts,
+                    source_table,
+                )?;
+                // As in dplyr, the anchor is located among all columns, so
+                // it may be a moved column itself: `relocate(z, .after =
+                // last_col())` keeps a last `z` in place.
+                let positions: Vec<usize> = current
+                    .iter()
+                    .enumerate()
+                    .filter(|(_, column)| targets.contains(column))
+                    .map(|(index, _)| index)
+                    .collect();
+                let boundary = match (anchor, positions.first(), positions.last()) {
+                    (Some(RelocateAnchor::Before(_)), Some(first), _) => *first,
+                    (Some(RelocateAnchor::After(_)), _, Some(last)) => last + 1,
+                    _ => {
+                        return Err(GenerationError::InvalidAst {
+                            reason: "relocate() anchor selects no columns".to_string(),
+                        })
+                    }
+                };
+                current[..boundary]
+                    .iter()
+                    .filter(|column| !moved.contains(column))
+                    .count()
+            }
+        };
+        order.splice(position..position, moved);
+
+        let columns: Vec<ColumnExpr> = order
+            .iter()
+            .map(|name| ColumnExpr {
+                expr: Expr::Identifier(name.clone()),
+                alias: None,
+            })
+            .collect();
+        parts.select_columns = self.generate_select_columns_with_mutations(&columns, parts)?;
+        parts.columns = Some(order);
+        Ok(())
+    }
+
+    /// Resolves column names and selection helpers to distinct column names,
+    /// in selection order.
+    pub(super) fn resolve_column_selection(
+        &self,
+        selection: &[Expr],
+        current: &[String],
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<Vec<String>> {
+        let mut selected: Vec<String> = Vec::new();
+        for expr in selection {
+            let names = match expr {
+                Expr::Identifier(name) if current.contains(name) => vec![name.clone()],
+                Expr::Identifier(name) => {
+                    return Err(GenerationError::InvalidColumnReference {
+                        column: name.clone(),
+                        table: Some(source_table.to_string()),
+                    })
+                }
+                Expr::Function { name, args } if SELECT_HELPERS.contains(&name.as_str()) => {
+                    self.resolve_select_helper(name, args, parts, source_table)?
+                }
+                Expr::Vector(items) => {
+                    self.resolve_column_selection(items, current, parts, source_table)?
+                }
+                _ => {
+                    return Err(GenerationError::InvalidAst {
+                        reason: "relocate() expects column names or select helpers".to_string(),
+                    })
+                }
+            };
+            for name in names {
+                if !selected.contains(&name) {
+                    selected.push(name);
+                }
+            }
+        }
+        Ok(selected)
+    }
+
+    /// Returns the columns of the data at this point of the pipeline. Unless
+    /// an earlier verb narrowed the projection, these are the schema columns
+    /// of `source_table` followed by columns added by mutate().
+    pub(super) fn current_columns(
+        &self,
+        parts: &QueryParts,
+        source_table: &str,
+        operation: &str,
+    ) -> GenerationResult<Vec<String>> {
+        self.known_columns(parts, source_table)
+            .ok_or_else(|| GenerationError::SchemaRequired {
+                operation: operation.to_string(),
+                table: source_table.to_string(),
+            })
+    }
+
+    /// Like `current_columns`, but `None` when the projection still
+    /// includes source columns the schema does not list.
+    pub(super) fn known_columns(
+        &self,
+        parts: &QueryParts,
+        source_table: &str,
+    ) -> Option<Vec<String>> {
+        if let Some(columns) = &parts.columns {
+            return Some(columns.clone());
+        }
+        let mut columns: Vec<String> = self
+            .options
+            .table_columns(source_table)?
+            .into_iter()
+            .map(str::to_string)
+            .collect();
+
+        for mutated in &parts.mutated_order {
+            if !columns.contains(mutated) {
+                columns.push(mutated.clone());
+            }
+        }
+        Some(columns)
+    }
+}
+
+/// Output names of a select() list, or `None` when an entry has no name
+/// (an unaliased expression).
+pub(super) fn projection_names(columns: &[ColumnExpr]) -> Option<Vec<String>> {
+    let mut names: Vec<String> = Vec::new();
+    for column in columns {
+        let name = match (&column.alias, &column.expr) {
+            (Some(alias), _) => alias.clone(),
+            (None, Expr::Identifier(name)) => name.clone(),
+            (None, Expr::QualifiedIdentifier { column, .. }) => column.clone(),
+            _ => return None,
+        };
+        if !names.contains(&name) {
+            names.push(name);
+        }
+    }
+    Some(names)
+}
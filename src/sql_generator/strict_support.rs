@@ -0,0 +1,39 @@
+// Strict mode checks (TranspileOptions::strict_mode).
+
+use super::{GenerationError, GenerationResult, SqlGenerator};
+
+impl SqlGenerator {
+    /// In strict mode, only functions with a known translation (the dialect's
+    /// mapping or `function_map`) may be rendered; dialect hooks that pass
+    /// other names through are not consulted. The built-in dialects have no
+    /// such hook, so this only changes the result for custom dialects.
+    pub(super) fn ensure_known_function(&self, name: &str) -> GenerationResult<()> {
+        if !self.options.strict_mode
+            || self.options.function_override(name).is_some()
+            || self.dialect.is_supported_function(name)
+            || self.dialect.translate_aggregate_function(name).is_some()
+        {
+            return Ok(());
+        }
+        Err(GenerationError::UnsupportedFunction {
+            function: name.to_string(),
+            dialect: self.dialect.dialect_name().to_string(),
+        })
+    }
+
+    /// In strict mode, rejects an operation that is only rendered on a
+    /// best-effort basis; `reason` says why the SQL may be wrong.
+    pub(super) fn ensure_exact_rendering(
+        &self,
+        operation: &str,
+        reason: &str,
+    ) -> GenerationResult<()> {
+        if !self.options.strict_mode {
+            return Ok(());
+        }
+        Err(GenerationError::StrictModeViolation {
+            operation: operation.to_string(),
+            reason: reason.to_string(),
+        })
+    }
+}
@@ -0,0 +1,678 @@
+// Summarise helpers (general summary expressions, intra-summarise alias references).
+
+use std::collections::HashMap;
+
+use super::assemble::QueryParts;
+use super::{
+    Aggregation, BinaryOp, Expr, GenerationError, GenerationResult, GroupingSets, OrderDirection,
+    OrderExpr, SqlGenerator, SummariseGroups, WindowContext,
+};
+
+/// Prefix of the helper columns that carry hoisted aggregates.
+const HOISTED_AGGREGATE_PREFIX: &str = "__agg";
+
+/// Rendering state for one summary expression.
+struct SummaryScope {
+    /// Earlier aliases visible to the expression, with their SQL replacement.
+    aliases: HashMap<String, String>,
+    /// Aggregate calls moved into the inner query (`AGG(..) AS "__aggN"`);
+    /// `None` renders aggregates in place.
+    hoisted: Option<Vec<String>>,
+    /// Number of helper columns handed out so far.
+    helper_count: usize,
+}
+
+impl SqlGenerator {
+    /// Processes `summarise(...)`.
+    ///
+    /// SQL cannot reference a select-list alias from the same SELECT, so when
+    /// an entry uses an earlier alias (`pct = sum(x) / total`) the summary is
+    /// split in two: the inner query computes the grouping keys, the
+    /// independent aggregations and every aggregate call used by dependent
+    /// entries; the outer query then evaluates the dependent expressions over
+    /// those columns.
+    pub(super) fn process_summarise_operation(
+        &self,
+        aggregations: &[Aggregation],
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        // A summarise over a summary aggregates its output, which only
+        // exists once the earlier summary query is nested as a derived table.
+        if query_parts.aggregation_group_by.is_some()
+            || (query_parts.from_subquery.is_some() && !query_parts.select_columns.is_empty())
+        {
+            let grouping = (
+                query_parts.group_by.clone(),
+                query_parts.group_columns.clone(),
+            );
+            self.wrap_in_subquery(source_table, query_parts)?;
+            (query_parts.group_by, query_parts.group_columns) = grouping;
+        }
+
+        self.ensure_no_aggregated_aliases(aggregations)?;
+        self.ensure_grouped_references(aggregations, &query_parts.group_columns)?;
+        // One item per grouping column, in line with the output names.
+        let mut select_columns: Vec<String> = query_parts
+            .group_columns
+            .iter()
+            .map(|column| self.dialect.quote_identifier(column))
+            .collect();
+
+        if !self.has_alias_dependencies(aggregations) {
+            select_columns.extend(self.generate_aggregations(aggregations)?);
+            // The aggregation list replaces the implicit `*`; an ungrouped
+            // summarise therefore renders as `SELECT agg, ... FROM t`.
+            query_parts.select_columns = select_columns;
+            query_parts.columns = summary_columns(&query_parts.group_columns, aggregations);
+            // Row order does not survive aggregation, and ordering by a
+            // non-grouped column would make the aggregate query invalid.
+            query_parts.order_by.clear();
+            query_parts.order_columns.clear();
+            query_parts.aggregation_group_by = Some(self.grouping_clause(query_parts)?);
+            return Ok(());
+        }
+
+        let mut outer_columns = select_columns.clone();
+        let mut scope = SummaryScope {
+            aliases: HashMap::new(),
+            hoisted: Some(Vec::new()),
+            helper_count: 0,
+        };
+        for agg in aggregations {
+            let dependent = agg
+                .expr
+                .as_ref()
+                .is_some_and(|expr| self.references_alias(expr, &scope.aliases));
+
+            if dependent {
+                let expr = agg
+                    .expr
+                    .as_ref()
+                    .expect("dependent aggregations carry expr");
+                let sql = self.generate_summary_expression(expr, &mut scope)?;
+                match &agg.alias {
+                    Some(alias) => {
+                        outer_columns.push(self.column_alias(&sql, alias));
+                        // Later references inline the expression.
+                        scope.aliases.insert(alias.clone(), sql);
+                    }
+                    None => outer_columns.push(sql),
+                }
+                continue;
+            }
+
+            let sql = self.generate_aggregation(agg)?;
+            let alias = match &agg.alias {
+                Some(alias) => alias.clone(),
+                None => next_helper_alias(&mut scope),
+            };
+            let quoted = self.dialect.quote_identifier(&alias);
+            select_columns.push(self.column_alias(&sql, &alias));
+            outer_columns.push(quoted.clone());
+            scope.aliases.insert(alias, quoted);
+        }
+        select_columns.extend(scope.hoisted.unwrap_or_default());
+
+        let mut inner = query_parts.clone();
+        inner.select_columns = select_columns;
+        inner.order_by.clear();
+        inner.aggregation_group_by = Some(self.grouping_clause(query_parts)?);
+        *query_parts = QueryParts::from_subquery(self.assemble_current(source_table, &inner)?);
+        query_parts.select_columns = outer_columns;
+        // The grouping keys are columns of the derived table, so the
+        // grouping left by `.groups` still applies one level up.
+        query_parts.columns = summary_columns(&inner.group_columns, aggregations);
+        query_parts.group_by = inner.group_by;
+        query_parts.group_columns = inner.group_columns;
+        Ok(())
+    }
+
+    /// Sets the grouping seen by the verbs after a summarise (`.groups`):
+    /// `"drop"` ungroups, so a later mutate() is no longer partitioned,
+    /// `"keep"` keeps every grouping column and `"drop_last"` (dplyr's
+    /// default) removes the last one.
+    pub(super) fn apply_summarise_groups(
+        &self,
+        groups: Option<SummariseGroups>,
+        query_parts: &mut QueryParts,
+    ) {
+        // The remaining grouping is a plain one, whatever produced the summary.
+        query_parts.grouping_sets = None;
+        match groups.unwrap_or(SummariseGroups::DropLast) {
+            SummariseGroups::Keep => return,
+            SummariseGroups::Drop => query_parts.group_columns.clear(),
+            SummariseGroups::DropLast => {
+                query_parts.group_columns.pop();
+            }
+        }
+        query_parts.group_by = query_parts
+            .group_columns
+            .iter()
+            .map(|col| self.dialect.quote_identifier(col))
+            .collect::<Vec<_>>()
+            .join(", ");
+    }
+
+    /// Processes `summarise(..., .by = c(a, b))`: the summary is grouped by
+    /// `by` for this step only and, as in dplyr, its result is ungrouped.
+    pub(super) fn process_summarise_by(
+        &self,
+        aggregations: &[Aggregation],
+        by: &[String],
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        if !query_parts.group_columns.is_empty() {
+            return Err(GenerationError::InvalidAst {
+                reason: "summarise(.by) cannot be used on grouped data".to_string(),
+            });
+        }
+        query_parts.group_columns = by.to_vec();
+        query_parts.group_by = by
+            .iter()
+            .map(|col| self.dialect.quote_identifier(col))
+            .collect::<Vec<_>>()
+            .join(", ");
+        self.process_summarise_operation(aggregations, query_parts, source_table)?;
+        self.apply_summarise_groups(Some(SummariseGroups::Drop), query_parts);
+        Ok(())
+    }
+
+    /// Processes `count(...)` as `summarise(name = n())` grouped by the
+    /// current groups plus `columns`; the input grouping is kept afterwards.
+    /// A weight (`wt = sales`) counts `sum(sales)` instead of rows.
+    ///
+    /// `sort = TRUE` orders by the count, largest first. A later arrange()
+    /// replaces that ordering.
+    pub(super) fn process_count_operation(
+        &self,
+        columns: &[String],
+        name: &str,
+        sort: bool,
+        wt: Option<&Expr>,
+        query_parts: &mut QueryParts,
+        source_table: &str,
+    ) -> GenerationResult<()> {
+        let input_grouping = (
+            query_parts.group_by.clone(),
+            query_parts.group_columns.clone(),
+        );
+        for col in columns {
+            if !query_parts.group_columns.contains(col) {
+                query_parts.group_columns.push(col.clone());
+            }
+        }
+        query_parts.group_by = query_parts
+            .group_columns
+            .iter()
+            .map(|col| self.dialect.quote_identifier(col))
+            .collect::<Vec<_>>()
+            .join(", ");
+
+        let count = match wt {
+            Some(weight) => Aggregation::from_expr(
+                Expr::Function {
+                    name: "sum".to_string(),
+                    args: vec![weight.clone()],
+                },
+                Some(name.to_string()),
+            ),
+            None => Aggregation {
+                function: "n".to_string(),
+                column: String::new(),
+                alias: Some(name.to_string()),
+                expr: None,
+            },
+        };
+        self.process_summarise_operation(&[count], query_parts, source_table)?;
+        if sort {
+            query_parts.order_by = self.generate_order_by(&[OrderExpr {
+                column: name.to_string(),
+                direction: OrderDirection::Desc,
+            }])?;
+            query_parts.order_columns = vec![name.to_string()];
+        }
+        (query_parts.group_by, query_parts.group_columns) = input_grouping;
+        Ok(())
+    }
+
+    /// Renders the GROUP BY list of a summary. The grouping keys lead the
+    /// SELECT list, so with `group_by_ordinals` they are referenced as
+    /// `1, 2, ...` instead of being repeated. A `rollup()`/`cube()`/
+    /// `groupingsets()` grouping renders the dialect's grouping construct.
+    fn grouping_clause(&self, query_parts: &QueryParts) -> GenerationResult<String> {
+        if let Some(sets) = &query_parts.grouping_sets {
+            return self
+                .dialect
+                .grouping_sets_clause(sets, &query_parts.group_columns)
+                .ok_or_else(|| GenerationError::UnsupportedOperation {
+                    operation: format!("group_by({})", grouping_sets_name(sets)),
+                    dialect: self.dialect.dialect_name().to_string(),
+                });
+        }
+        if !self.options.group_by_ordinals || query_parts.group_by.is_empty() {
+            return Ok(query_parts.group_by.clone());
+        }
+        Ok((1..=query_parts.group_columns.len())
+            .map(|position| position.to_string())
+            .collect::<Vec<_>>()
+            .join(", "))
+    }
+
+    /// Renders a single aggregation without its alias.
+    pub(super) fn generate_aggregation(&self, agg: &Aggregation) -> GenerationResult<String> {
+        if let Some(expr) = &agg.expr {
+            let mut scope = SummaryScope {
+                aliases: HashMap::new(),
+                hoisted: None,
+                helper_count: 0,
+            };
+            return self.generate_summary_expression(expr, &mut scope);
+        }
+
+        if is_distinct_count(&agg.function) {
+            return self.distinct_count(&[Expr::Identifier(agg.column.clone())]);
+        }
+        let func_name = self.aggregate_function_name(&agg.function)?;
+        let column_ref = if agg.function.to_lowercase() == "n" {
+            "*".to_string()
+        } else {
+            self.dialect.quote_identifier(&agg.column)
+        };
+        Ok(format!("{func_name}({column_ref})"))
+    }
+
+    /// Returns the SQL name of the aggregate `function` (function_map first).
+    fn aggregate_function_name(&self, function: &str) -> GenerationResult<String> {
+        match self.options.function_override(function) {
+            Some(mapped) => Ok(mapped.to_string()),
+            None => self
+                .dialect
+                .translate_aggregate_function(function)
+                .ok_or_else(|| GenerationError::UnsupportedAggregateFunction {
+                    function: function.to_string(),
+                    dialect: self.dialect.dialect_name().to_string(),
+                }),
+        }
+    }
+
+    /// True when `name` is an aggregate of summarise(): the dialect's
+    /// aggregates plus `n_distinct()`.
+    fn is_summary_aggregate(&self, name: &str) -> bool {
+        is_distinct_count(name) || self.dialect.translate_aggregate_function(name).is_some()
+    }
+
+    /// Renders `n_distinct()`: the number of distinct values, or of distinct
+    /// combinations when several columns are given (`n_distinct(a, b)`).
+    fn distinct_count(&self, args: &[Expr]) -> GenerationResult<String> {
+        let values = args
+            .iter()
+            .map(|arg| self.generate_expression(arg))
+            .collect::<GenerationResult<Vec<_>>>()?;
+        let key = match values.as_slice() {
+            [] => {
+                return Err(GenerationError::InvalidAst {
+                    reason: "n_distinct() expects at least one column".to_string(),
+                })
+            }
+            [value] => value.clone(),
+            _ => self.dialect.distinct_key(&values).ok_or_else(|| {
+                GenerationError::UnsupportedFunction {
+                    function: "n_distinct".to_string(),
+                    dialect: self.dialect.dialect_name().to_string(),
+                }
+            })?,
+        };
+        let count = self.aggregate_function_name("n")?;
+        Ok(format!("{count}(DISTINCT {key})"))
+    }
+
+    /// Rejects an aggregate over the alias of an earlier entry
+    /// (`summarise(total = sum(x), avg = mean(total))`): the alias holds one
+    /// value per group and cannot be aggregated again in the same query.
+    fn ensure_no_aggregated_aliases(&self, aggregations: &[Aggregation]) -> GenerationResult<()> {
+        let mut aliases: Vec<&str> = Vec::new();
+        for agg in aggregations {
+            let aggregated = match &agg.expr {
+                Some(expr) => self.aggregated_alias(expr, &aliases),
+                None => aliases
+                    .contains(&agg.column.as_str())
+                    .then(|| agg.column.clone()),
+            };
+            if let Some(alias) = aggregated {
+                return Err(GenerationError::InvalidAst {
+                    reason: format!(
+                        "summarise() cannot aggregate '{alias}', a summary created in the same summarise()"
+                    ),
+                });
+            }
+            if let Some(alias) = &agg.alias {
+                aliases.push(alias);
+            }
+        }
+        Ok(())
+    }
+
+    /// Rejects a column used outside an aggregate call that is neither a
+    /// grouping column nor an earlier alias (`summarise(total = sum(x), z =
+    /// x + 1)`): the summary has one row per group, so `x` has no value.
+    fn ensure_grouped_references(
+        &self,
+        aggregations: &[Aggregation],
+        group_columns: &[String],
+    ) -> GenerationResult<()> {
+        let mut visible: Vec<&str> = group_columns.iter().map(String::as_str).collect();
+        for agg in aggregations {
+            let ungrouped = match &agg.expr {
+                Some(expr) => self.ungrouped_column(expr, &visible),
+                None if self.is_summary_aggregate(&agg.function)
+                    || self.options.function_override(&agg.function).is_some()
+                    || agg.column.is_empty()
+                    || visible.contains(&agg.column.as_str()) =>
+                {
+                    None
+                }
+                None => Some(agg.column.clone()),
+            };
+            if let Some(name) = ungrouped {
+                return Err(GenerationError::InvalidAst {
+                    reason: format!(
+                        "summarise() can only use column '{name}' inside an aggregate, as it is not a grouping column"
+                    ),
+                });
+            }
+            if let Some(alias) = &agg.alias {
+                visible.push(alias);
+            }
+        }
+        Ok(())
+    }
+
+    /// Returns a column of `expr` outside any aggregate call that is not in
+    /// `visible`.
+    fn ungrouped_column(&self, expr: &Expr, visible: &[&str]) -> Option<String> {
+        match expr {
+            Expr::Identifier(name) => (!visible.contains(&name.as_str())).then(|| name.clone()),
+            Expr::Function { name, .. } if self.is_summary_aggregate(name) => None,
+            Expr::Binary { left, right, .. } => self
+                .ungrouped_column(left, visible)
+                .or_else(|| self.ungrouped_column(right, visible)),
+            Expr::Function { args, .. } | Expr::Vector(args) => args
+                .iter()
+                .find_map(|arg| self.ungrouped_column(arg, visible)),
+            Expr::NamedArg { value, .. } => self.ungrouped_column(value, visible),
+            Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => None,
+        }
+    }
+
+    /// Returns an alias in `aliases` used inside an aggregate call of `expr`.
+    fn aggregated_alias(&self, expr: &Expr, aliases: &[&str]) -> Option<String> {
+        match expr {
+            Expr::Function { name, args } if self.is_summary_aggregate(name) => {
+                args.iter().find_map(|arg| referenced_name(arg, aliases))
+            }
+            Expr::Binary { left, right, .. } => self
+                .aggregated_alias(left, aliases)
+                .or_else(|| self.aggregated_alias(right, aliases)),
+            Expr::Function { args, .. } | Expr::Vector(args) => args
+                .iter()
+                .find_map(|arg| self.aggregated_alias(arg, aliases)),
+            Expr::NamedArg { value, .. } => self.aggregated_alias(value, aliases),
+            Expr::Identifier(_) | Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => None,
+        }
+    }
+
+    /// True when any entry refers to the alias of an earlier entry.
+    fn has_alias_dependencies(&self, aggregations: &[Aggregation]) -> bool {
+        let mut aliases = HashMap::new();
+        for agg in aggregations {
+            if agg
+                .expr
+                .as_ref()
+                .is_some_and(|expr| self.references_alias(expr, &aliases))
+            {
+                return true;
+            }
+            if let Some(alias) = &agg.alias {
+                aliases.insert(alias.clone(), String::new());
+            }
+        }
+        false
+    }
+
+    /// Renders a summary expression: aggregate calls become SQL aggregates
+    /// (or hoisted helper columns), earlier aliases their replacement.
+    fn generate_summary_expression(
+        &self,
+        expr: &Expr,
+        scope: &mut SummaryScope,
+    ) -> GenerationResult<String> {
+        match expr {
+            Expr::Identifier(name) => match scope.aliases.get(name) {
+                Some(replacement) => Ok(replacement.clone()),
+                None => Ok(self.dialect.quote_identifier(name)),
+            },
+            Expr::Binary {
+                left,
+                operator,
+                right,
+            } => {
+                let left_sql = self.generate_summary_expression(left, scope)?;
+                let right_sql = if *operator == BinaryOp::In {
+                    self.generate_value_list(right, WindowContext::default())?
+                } else {
+                    self.generate_summary_expression(right, scope)?
+                };
+                if *operator == BinaryOp::IntegerDivide {
+                    return Ok(self.dialect.integer_divide(&left_sql, &right_sql));
+                }
+                let op_sql = self.generate_binary_operator(operator);
+                Ok(format!("({left_sql} {op_sql} {right_sql})"))
+            }
+            Expr::Function { name, args } if self.is_summary_aggregate(name) => {
+                let sql = match self.conditional_aggregate(name, args)? {
+                    Some(sql) => sql,
+                    None if is_distinct_count(name) => self.distinct_count(args)?,
+                    None => {
+                        let func_name = self.aggregate_function_name(name)?;
+                        let args_sql = if name.eq_ignore_ascii_case("n") && args.is_empty() {
+                            "*".to_string()
+                        } else {
+                            args.iter()
+                                .map(|arg| {
+                                    Ok(aggregate_argument(arg, self.generate_expression(arg)?))
+                                })
+                                .collect::<GenerationResult<Vec<_>>>()?
+                                .join(", ")
+                        };
+                        format!("{func_name}({args_sql})")
+                    }
+                };
+                if scope.hoisted.is_none() {
+                    return Ok(sql);
+                }
+                let alias = next_helper_alias(scope);
+                let item = self.column_alias(&sql, &alias);
+                if let Some(hoisted) = scope.hoisted.as_mut() {
+                    hoisted.push(item);
+                }
+                Ok(self.dialect.quote_identifier(&alias))
+            }
+            Expr::Function { name, args }
+                if !args.iter().any(|arg| matches!(arg, Expr::NamedArg { .. })) =>
+            {
+                let args_sql = args
+                    .iter()
+                    .map(|arg| self.generate_summary_expression(arg, scope))
+                    .collect::<GenerationResult<Vec<_>>>()?;
+                if let Some(mapped) = self.options.function_override(name) {
+                    return Ok(format!("{mapped}({})", args_sql.join(", ")));
+                }
+                self.ensure_known_function(name)?;
+                self.dialect
+                    .translate_function_with_window_partition(name, &args_sql, "")
+                    .ok_or_else(|| GenerationError::UnsupportedFunction {
+                        function: name.to_string(),
+                        dialect: self.dialect.dialect_name().to_string(),
+                    })
+            }
+            _ => self.generate_expression(expr),
+        }
+    }
+
+    /// Renders `sum(cond)` and `mean(cond)` over a logical condition, which
+    /// count (or take the share of) the rows where `cond` holds.
+    ///
+    /// SQL does not sum booleans portably: dialects with aggregate filters
+    /// render `sum(cond)` as `COUNT(*) FILTER (WHERE cond)`, the others (and
+    /// every `mean(cond)`) aggregate `CASE WHEN cond THEN 1 ELSE 0 END`.
+    /// `sum(!is.na(x))` counts the non-missing values, which is `COUNT(x)`.
+    /// Returns `None` for any other call, or when `function_map` overrides
+    /// the aggregate.
+    fn conditional_aggregate(&self, name: &str, args: &[Expr]) -> GenerationResult<Option<String>> {
+        let [condition] = args else {
+            return Ok(None);
+        };
+        let function = name.to_lowercase();
+        if !matches!(function.as_str(), "sum" | "mean")
+            || !is_condition(condition)
+            || self.options.function_override(&function).is_some()
+        {
+            return Ok(None);
+        }
+
+        if function == "sum" {
+            if let Some(value) = negated_missing_test(condition) {
+                let count = self.aggregate_function_name("n")?;
+                let value_sql = aggregate_argument(value, self.generate_expression(value)?);
+                return Ok(Some(format!("{count}({value_sql})")));
+            }
+        }
+        let condition_sql = aggregate_argument(condition, self.generate_expression(condition)?);
+        if function == "sum" && self.dialect.supports_aggregate_filter() {
+            let count = self.aggregate_function_name("n")?;
+            return Ok(Some(format!("{count}(*) FILTER (WHERE {condition_sql})")));
+        }
+        let func_name = self.aggregate_function_name(&function)?;
+        Ok(Some(format!(
+            "{func_name}(CASE WHEN {condition_sql} THEN 1 ELSE 0 END)"
+        )))
+    }
+
+    /// True when `expr` uses one of `aliases` outside aggregate calls.
+    ///
+    /// Arguments of aggregate calls are evaluated per row, so they always
+    /// refer to source columns.
+    fn references_alias(&self, expr: &Expr, aliases: &HashMap<String, String>) -> bool {
+        match expr {
+            Expr::Identifier(name) => aliases.contains_key(name),
+            Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => false,
+            Expr::Binary { left, right, .. } => {
+                self.references_alias(left, aliases) || self.references_alias(right, aliases)
+            }
+            Expr::Function { name, .. } if self.is_summary_aggregate(name) => false,
+            Expr::Function { args, .. } | Expr::Vector(args) => {
+                args.iter().any(|arg| self.references_alias(arg, aliases))
+            }
+            Expr::NamedArg { value, .. } => self.references_alias(value, aliases),
+        }
+    }
+}
+
+/// Drops the parentheses around a binary aggregate argument (`arg_sql`
+/// rendered from `arg`): the call's own parentheses already delimit it, so
+/// `sum(price * qty)` renders as `SUM("price" * "qty")`.
+pub(super) fn aggregate_argument(arg: &Expr, arg_sql: String) -> String {
+    match arg {
+        Expr::Binary { .. } if arg_sql.starts_with('(') && arg_sql.ends_with(')') => {
+            arg_sql[1..arg_sql.len() - 1].to_string()
+        }
+        _ => arg_sql,
+    }
+}
+
+/// True when `expr` is a logical condition: a comparison, pattern or
+/// membership test, a combination of those, or a predicate function
+/// (`is.na()`, `between()`, the stringr matchers).
+pub(super) fn is_condition(expr: &Expr) -> bool {
+    match expr {
+        Expr::Binary { operator, .. } => !matches!(
+            operator,
+            BinaryOp::Plus
+                | BinaryOp::Minus
+                | BinaryOp::Multiply
+                | BinaryOp::Divide
+                | BinaryOp::IntegerDivide
+                | BinaryOp::Modulo
+        ),
+        Expr::Function { name, .. } => matches!(
+            name.as_str(),
+            "!" | "is.na" | "xor" | "between" | "str_detect" | "str_starts" | "str_ends"
+        ),
+        _ => false,
+    }
+}
+
+/// Returns `x` of a `!is.na(x)` test.
+fn negated_missing_test(expr: &Expr) -> Option<&Expr> {
+    let Expr::Function { name, args } = expr else {
+        return None;
+    };
+    let [Expr::Function {
+        name: inner,
+        args: inner_args,
+    }] = args.as_slice()
+    else {
+        return None;
+    };
+    match inner_args.as_slice() {
+        [value] if name == "!" && inner == "is.na" => Some(value),
+        _ => None,
+    }
+}
+
+/// dplyr spelling of a grouping construct, for error messages.
+fn grouping_sets_name(sets: &GroupingSets) -> &'static str {
+    match sets {
+        GroupingSets::Rollup => "rollup()",
+        GroupingSets::Cube => "cube()",
+        GroupingSets::Sets(_) => "groupingsets()",
+    }
+}
+
+/// Hands out the next helper column name (`__agg1`, `__agg2`, ...).
+fn next_helper_alias(scope: &mut SummaryScope) -> String {
+    scope.helper_count += 1;
+    format!("{HOISTED_AGGREGATE_PREFIX}{}", scope.helper_count)
+}
+
+/// True for `n_distinct()`, which `distinct_count` renders.
+fn is_distinct_count(name: &str) -> bool {
+    name.eq_ignore_ascii_case("n_distinct")
+}
+
+/// Output columns of a summary: the grouping keys, then one column per
+/// entry. `None` when an entry is unnamed.
+fn summary_columns(group_columns: &[String], aggregations: &[Aggregation]) -> Option<Vec<String>> {
+    let mut columns = group_columns.to_vec();
+    for agg in aggregations {
+        columns.push(agg.alias.clone()?);
+    }
+    Some(columns)
+}
+
+/// Returns the first identifier of `expr` that is one of `names`.
+fn referenced_name(expr: &Expr, names: &[&str]) -> Option<String> {
+    match expr {
+        Expr::Identifier(name) => names.contains(&name.as_str()).then(|| name.clone()),
+        Expr::Binary { left, right, .. } => {
+            referenced_name(left, names).or_else(|| referenced_name(right, names))
+        }
+        Expr::Function { args, .. } | Expr::Vector(args) => {
+            args.iter().find_map(|arg| referenced_name(arg, names))
+        }
+        Expr::NamedArg { value, .. } => referenced_name(value, names),
+        Expr::QualifiedIdentifier { .. } | Expr::Literal(_) => None,
+    }
+}
@@ -12,6 +12,40 @@ fn normalize_sql(sql: &str) -> String {
         .to_uppercase()
 }
 
+// Helper function to transpile dplyr code for a dialect with options
+fn transpile_with(
+    dialect: Box<dyn SqlDialect>,
+    options: TranspileOptions,
+    code: &str,
+) -> Result<String, crate::TranspileError> {
+    crate::Transpiler::with_options(dialect, options).transpile(code)
+}
+
+// Helper function to transpile dplyr code for a dialect with default options
+fn transpile_for(
+    dialect: Box<dyn SqlDialect>,
+    code: &str,
+) -> Result<String, crate::TranspileError> {
+    transpile_with(dialect, TranspileOptions::default(), code)
+}
+
+// Helper function to transpile dplyr code for PostgreSQL with default options
+fn transpile(code: &str) -> Result<String, crate::TranspileError> {
+    transpile_for(Box::new(PostgreSqlDialect::new()), code)
+}
+
+// Helper function to transpile dplyr code for PostgreSQL with a table schema
+fn transpile_with_schema(
+    schema: Option<crate::Schema>,
+    code: &str,
+) -> Result<String, crate::TranspileError> {
+    let options = TranspileOptions {
+        schema,
+        ..TranspileOptions::default()
+    };
+    transpile_with(Box::new(PostgreSqlDialect::new()), options, code)
+}
+
 // Helper function to create test AST nodes
 fn create_test_select_operation(columns: Vec<&str>) -> DplyrOperation {
     DplyrOperation::Select {
@@ -118,6 +152,19 @@ mod dialect_tests {
         assert_eq!(dialect.aggregate_function("mean"), "AVG");
     }
 
+    #[test]
+    fn test_sqlserver_dialect() {
+        let dialect = SqlServerDialect::new();
+        assert_eq!(dialect.quote_identifier("test"), "[test]");
+        assert_eq!(dialect.quote_identifier("bad]name"), "[bad]]name]");
+        assert_eq!(dialect.string_concat("a", "b"), "a + b");
+        assert_eq!(
+            dialect.limit_clause(10),
+            "OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"
+        );
+        assert!(dialect.limit_requires_order_by());
+    }
+
     #[test]
     fn test_dialect_limit_clause() {
         let pg_dialect = PostgreSqlDialect::new();
@@ -172,6 +219,79 @@ mod clause_generation_tests {
         assert_eq!(result[1], "\"age\" AS \"user_age\"");
     }
 
+    #[test]
+    fn test_assembly_skips_empty_fragments() {
+        let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
+        let mut parts = QueryParts::new();
+        parts.select_columns = vec![String::new(), "\"a\"".to_string()];
+        parts.joins = vec![String::new()];
+        parts.where_clauses = vec![
+            WhereCondition::new(String::new()),
+            WhereCondition::enclosed("(\"x\" > 1)".to_string()),
+            WhereCondition::new(" ".to_string()),
+            WhereCondition::enclosed("(\"y\" < 2)".to_string()),
+        ];
+
+        let sql = generator
+            .assemble_query(&Some("data".to_string()), &parts)
+            .unwrap();
+        // 빈 조각은 이중 공백이나 남는 AND를 만들지 않는다
+        assert_eq!(
+            sql,
+            "SELECT \"a\"\nFROM \"data\"\nWHERE (\"x\" > 1) AND (\"y\" < 2)"
+        );
+        assert!(!sql.contains("  "), "{sql}");
+
+        parts.select_columns = vec![String::new()];
+        parts.where_clauses = vec![WhereCondition::new(String::new())];
+        let sql = generator
+            .assemble_query(&Some("data".to_string()), &parts)
+            .unwrap();
+        assert_eq!(sql, "SELECT *\nFROM \"data\"");
+    }
+
+    #[test]
+    fn test_chained_filters_keep_or_groups() {
+        let sql = transpile("data %>% filter(a > 1 | b > 2) %>% filter(c > 3)").unwrap();
+        assert!(
+            sql.ends_with("WHERE ((\"a\" > 1) OR (\"b\" > 2)) AND (\"c\" > 3)"),
+            "{sql}"
+        );
+
+        // 최적화로 합쳐진 조건도 같은 그룹을 유지
+        let options = crate::TranspileOptions {
+            optimize: true,
+            ..crate::TranspileOptions::default()
+        };
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "data %>% filter(a > 1 | b > 2) %>% filter(c > 3)",
+        )
+        .unwrap();
+        assert!(
+            sql.ends_with("WHERE (((\"a\" > 1) OR (\"b\" > 2)) AND (\"c\" > 3))"),
+            "{sql}"
+        );
+
+        // 괄호로 감싸지 않은 조건만 AND 앞에서 묶는다
+        let sql = transpile("data %>% filter(is_active) %>% filter(c > 3)").unwrap();
+        assert!(
+            sql.ends_with("WHERE (\"is_active\") AND (\"c\" > 3)"),
+            "{sql}"
+        );
+        let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
+        let mut parts = QueryParts::new();
+        parts.where_clauses = vec![
+            WhereCondition::new("\"a\" OR \"b\"".to_string()),
+            WhereCondition::enclosed("(\"c\")".to_string()),
+        ];
+        let sql = generator
+            .assemble_query(&Some("data".to_string()), &parts)
+            .unwrap();
+        assert!(sql.ends_with("WHERE (\"a\" OR \"b\") AND (\"c\")"), "{sql}");
+    }
+
     #[test]
     fn test_where_clause_generation() {
         let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -186,6 +306,30 @@ mod clause_generation_tests {
         assert_eq!(result, "(\"age\" >= 18)");
     }
 
+    #[test]
+    fn test_where_clause_with_expression_left_operand() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
+
+        // 함수 호출이 비교식의 왼쪽에 오는 경우
+        let sql = transpiler
+            .transpile("codes %>% filter(substr(code, 1, 1) == \"A\")")
+            .unwrap();
+        assert!(
+            sql.ends_with("WHERE (SUBSTR(\"code\", 1, ((1) - (1) + 1)) = 'A')"),
+            "{sql}"
+        );
+
+        let sql = transpiler
+            .transpile("codes %>% filter(tolower(name) != \"x\" & (price - cost) * qty > 100)")
+            .unwrap();
+        assert!(
+            sql.ends_with(
+                "WHERE ((LOWER(\"name\") != 'x') AND (((\"price\" - \"cost\") * \"qty\") > 100))"
+            ),
+            "{sql}"
+        );
+    }
+
     #[test]
     fn test_order_by_clause_generation() {
         let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -205,6 +349,37 @@ mod clause_generation_tests {
         assert_eq!(result, "\"name\" ASC, \"age\" DESC");
     }
 
+    #[test]
+    fn test_three_key_mixed_direction_order_by() {
+        // Each key keeps its own direction and its position in arrange().
+        let dialects: Vec<(Box<dyn SqlDialect>, &str)> = vec![
+            (
+                Box::new(PostgreSqlDialect::new()),
+                "ORDER BY \"a\" ASC, \"b\" DESC, \"c\" ASC",
+            ),
+            (
+                Box::new(MySqlDialect::new()),
+                "ORDER BY `a` ASC, `b` DESC, `c` ASC",
+            ),
+            (
+                Box::new(SqliteDialect::new()),
+                "ORDER BY \"a\" ASC, \"b\" DESC, \"c\" ASC",
+            ),
+            (
+                Box::new(DuckDbDialect::new()),
+                "ORDER BY \"a\" ASC, \"b\" DESC, \"c\" ASC",
+            ),
+        ];
+
+        for (dialect, expected) in dialects {
+            let sql = transpile_for(dialect, "data %>% arrange(a, desc(b), c)").unwrap();
+            assert!(sql.ends_with(expected), "unexpected ordering: {sql}");
+        }
+
+        let sql = transpile("data %>% arrange(desc(d), asc(a), c, desc(b))").unwrap();
+        assert!(sql.ends_with("ORDER BY \"d\" DESC, \"a\" ASC, \"c\" ASC, \"b\" DESC"));
+    }
+
     #[test]
     fn test_aggregation_generation() {
         let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -214,11 +389,13 @@ mod clause_generation_tests {
                 function: "mean".to_string(),
                 column: "salary".to_string(),
                 alias: Some("avg_salary".to_string()),
+                expr: None,
             },
             Aggregation {
                 function: "n".to_string(),
                 column: "".to_string(),
                 alias: Some("count".to_string()),
+                expr: None,
             },
         ];
 
@@ -331,6 +508,243 @@ mod clause_generation_tests {
 mod dialect_specific_tests {
     use super::*;
 
+    #[test]
+    fn test_like_operators_render_per_dialect() {
+        fn transpile(dialect: Box<dyn SqlDialect>) -> String {
+            transpile_for(
+                dialect,
+                r#"data %>% filter(name %like% "A%_x" | city %ilike% "%york")"#,
+            )
+            .unwrap()
+        }
+
+        // The pattern is passed through with its metacharacters untouched.
+        let pg_sql = transpile(Box::new(PostgreSqlDialect::new()));
+        assert!(pg_sql.contains("WHERE ((\"name\" LIKE 'A%_x') OR (\"city\" ILIKE '%york'))"));
+
+        let duckdb_sql = transpile(Box::new(DuckDbDialect::new()));
+        assert!(duckdb_sql.contains("(\"name\" LIKE 'A%_x')"));
+        assert!(duckdb_sql.contains("(\"city\" ILIKE '%york')"));
+
+        // MySQL and SQLite have no ILIKE; their LIKE is already case-insensitive.
+        let mysql_sql = transpile(Box::new(MySqlDialect::new()));
+        assert!(mysql_sql.contains("WHERE ((`name` LIKE 'A%_x') OR (`city` LIKE '%york'))"));
+
+        let sqlite_sql = transpile(Box::new(SqliteDialect::new()));
+        assert!(sqlite_sql.contains("(\"city\" LIKE '%york')"));
+        assert!(!sqlite_sql.contains("ILIKE"));
+    }
+
+    #[test]
+    fn test_integer_division_and_modulo_render_per_dialect() {
+        fn transpile(dialect: Box<dyn SqlDialect>) -> String {
+            transpile_for(dialect, "data %>% mutate(q = a %/% b, r = a %% b)").unwrap()
+        }
+
+        // %/%는 R처럼 내림: 정수 피연산자가 먼저 잘리지 않도록 * 1.0
+        let pg_sql = transpile(Box::new(PostgreSqlDialect::new()));
+        assert!(pg_sql.contains("FLOOR((\"a\" * 1.0) / \"b\") AS \"q\", (\"a\" % \"b\") AS \"r\""));
+
+        // DuckDB's `/` is float division.
+        let duckdb_sql = transpile(Box::new(DuckDbDialect::new()));
+        assert!(duckdb_sql.contains("FLOOR(\"a\" / \"b\") AS \"q\", (\"a\" % \"b\") AS \"r\""));
+
+        let mysql_sql = transpile(Box::new(MySqlDialect::new()));
+        assert!(mysql_sql.contains("FLOOR(`a` / `b`) AS `q`, (`a` MOD `b`) AS `r`"));
+
+        let sqlite_sql = transpile(Box::new(SqliteDialect::new()));
+        // SQLite의 FLOOR()는 수학 확장이 필요
+        assert!(sqlite_sql.contains(
+            "(CAST(((\"a\" * 1.0) / \"b\") AS INTEGER) - (((\"a\" * 1.0) / \"b\") < CAST(((\"a\" * 1.0) / \"b\") AS INTEGER))) AS \"q\""
+        ), "{sqlite_sql}");
+
+        let mssql_sql = transpile(Box::new(SqlServerDialect::new()));
+        assert!(
+            mssql_sql.contains("FLOOR(([a] * 1.0) / [b]) AS [q]"),
+            "{mssql_sql}"
+        );
+    }
+
+    #[test]
+    fn test_caret_renders_as_power() {
+        let sql = transpile("data %>% mutate(sq = x ^ 2, area = 3.14 * r^2)").unwrap();
+        assert!(sql.contains("POWER(\"x\", 2) AS \"sq\""));
+        assert!(sql.contains("(3.14 * POWER(\"r\", 2)) AS \"area\""));
+
+        // SQLite only has POWER() with the math extension.
+        let err = transpile_for(
+            Box::new(SqliteDialect::new()),
+            "data %>% mutate(sq = x ^ 2)",
+        )
+        .unwrap_err();
+        assert!(err.to_string().contains("'^'"), "{err}");
+    }
+
+    #[test]
+    fn test_in_operator_renders_value_list() {
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            r#"data %>% filter(name %in% c("a", "b") & id %in% 3)"#,
+        )
+        .unwrap();
+        assert!(sql.contains("(`name` IN ('a', 'b'))"), "{sql}");
+        // 단일 값도 한 원소짜리 목록으로 렌더링
+        assert!(sql.contains("(`id` IN (3))"), "{sql}");
+
+        // %in% 밖의 c()는 값으로 쓸 수 없음
+        let err = transpile("data %>% mutate(v = c(1, 2))").unwrap_err();
+        assert!(err.to_string().contains("c()"), "{err}");
+    }
+
+    #[test]
+    fn test_between_is_inclusive_unless_half_open() {
+        // dplyr의 between()은 양 끝을 포함
+        let sql = transpile_for(
+            Box::new(SqliteDialect::new()),
+            "data %>% filter(between(x, 1, 10))",
+        )
+        .unwrap();
+        assert!(sql.contains("WHERE (\"x\" BETWEEN 1 AND 10)"), "{sql}");
+
+        // inclusive = FALSE는 [lo, hi) 반개구간
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            "data %>% filter(between(ts, lo, hi, inclusive = FALSE))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("WHERE ((`ts` >= `lo`) AND (`ts` < `hi`))"),
+            "{sql}"
+        );
+
+        let err = transpile("data %>% filter(between(x, 1, 10, inclusive = y))").unwrap_err();
+        assert!(err.to_string().contains("inclusive"), "{err}");
+    }
+
+    #[test]
+    fn test_in_and_between_compose_in_filter() {
+        let code = r#"data %>% filter(region %in% c("US", "CA") & between(age, 18, 65))"#;
+        let sql = transpile(code).unwrap();
+        assert!(
+            sql.ends_with("WHERE ((\"region\" IN ('US', 'CA')) AND (\"age\" BETWEEN 18 AND 65))"),
+            "{sql}"
+        );
+
+        // 논리 연산자 우선순위: &가 |보다 먼저 묶인다
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            r#"data %>% filter(region %in% c("US") | between(age, 18, 65) & active)"#,
+        )
+        .unwrap();
+        assert!(
+            sql.ends_with(
+                "WHERE ((`region` IN ('US')) OR ((`age` BETWEEN 18 AND 65) AND `active`))"
+            ),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_xor_filter_renders_boolean_inequality() {
+        let code = "data %>% filter(xor(a > 1, b > 2))";
+        let sql = transpile(code).unwrap();
+        assert!(sql.ends_with("WHERE ((\"a\" > 1) != (\"b\" > 2))"), "{sql}");
+        let sql = transpile_for(Box::new(MySqlDialect::new()), code).unwrap();
+        assert!(sql.ends_with("WHERE ((`a` > 1) <> (`b` > 2))"), "{sql}");
+
+        // 불리언 값이 없는 방언은 AND/OR로 풀어 쓴다
+        let sql = transpile_for(Box::new(SqlServerDialect::new()), code).unwrap();
+        assert!(
+            sql.ends_with("WHERE ((([a] > 1) AND NOT ([b] > 2)) OR (NOT ([a] > 1) AND ([b] > 2)))"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_constant_filters() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
+
+        // filter(TRUE)는 WHERE를 만들지 않는다
+        let sql = transpiler.transpile("data %>% filter(TRUE)").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM \"DATA\"");
+        let sql = transpiler
+            .transpile("data %>% filter(x > 1) %>% filter(TRUE)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"DATA\" WHERE (\"X\" > 1)"
+        );
+
+        let sql = transpiler.transpile("data %>% filter(FALSE)").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM \"DATA\" WHERE FALSE");
+    }
+
+    #[test]
+    fn test_pmax_pmin_na_rm_per_dialect() {
+        // na.rm이 없으면 그대로 GREATEST/LEAST
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            "data %>% mutate(hi = pmax(a, b), lo = pmin(a, b, na.rm = FALSE))",
+        )
+        .unwrap();
+        assert!(sql.contains("GREATEST(`a`, `b`) AS `hi`"), "{sql}");
+        assert!(sql.contains("LEAST(`a`, `b`) AS `lo`"), "{sql}");
+
+        // MySQL의 GREATEST는 NULL을 전파하므로 COALESCE로 보완
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            "data %>% mutate(hi = pmax(a, b, na.rm = TRUE))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("GREATEST(COALESCE(`a`, `b`), COALESCE(`b`, `a`)) AS `hi`"),
+            "{sql}"
+        );
+
+        // PostgreSQL은 NULL을 무시하므로 그대로
+        let sql = transpile_for(
+            Box::new(PostgreSqlDialect::new()),
+            "data %>% mutate(hi = pmax(a, b, na.rm = TRUE))",
+        )
+        .unwrap();
+        assert!(sql.contains("GREATEST(\"a\", \"b\") AS \"hi\""), "{sql}");
+
+        // SQLite는 다중 인자 MIN()
+        let sql = transpile_for(
+            Box::new(SqliteDialect::new()),
+            "data %>% mutate(lo = pmin(a, b, c, na.rm = TRUE))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("MIN(COALESCE(\"a\", \"b\", \"c\"), COALESCE(\"b\", \"c\", \"a\"), COALESCE(\"c\", \"a\", \"b\"))"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_comparison_operators_render_per_dialect() {
+        fn transpile(dialect: Box<dyn SqlDialect>) -> String {
+            transpile_for(dialect, "data %>% filter(x != 1 & y == 2)").unwrap()
+        }
+
+        let duckdb_sql = transpile(Box::new(DuckDbDialect::new()));
+        assert!(duckdb_sql.contains("WHERE ((\"x\" != 1) AND (\"y\" = 2))"));
+
+        // MySQL prefers the ANSI spelling of "not equal".
+        let mysql_sql = transpile(Box::new(MySqlDialect::new()));
+        assert!(mysql_sql.contains("WHERE ((`x` <> 1) AND (`y` = 2))"));
+        assert!(!mysql_sql.contains("!="));
+
+        let defaults: Vec<Box<dyn SqlDialect>> = vec![
+            Box::new(PostgreSqlDialect::new()),
+            Box::new(SqliteDialect::new()),
+        ];
+        for dialect in defaults {
+            assert_eq!(dialect.binary_operator(&BinaryOp::Equal), "=");
+            assert_eq!(dialect.binary_operator(&BinaryOp::NotEqual), "!=");
+        }
+    }
+
     #[test]
     fn test_postgresql_vs_mysql_identifier_quoting() {
         let pg_generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -413,6 +827,48 @@ mod dialect_specific_tests {
         ));
     }
 
+    #[test]
+    fn test_str_starts_and_str_ends_filter_with_like() {
+        let sql = transpile(r#"data %>% filter(str_starts(name, "A"))"#).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"DATA\" WHERE (\"NAME\" LIKE 'A%')"
+        );
+
+        let sql = transpile(r#"data %>% filter(str_ends(name, "son"))"#).unwrap();
+        assert!(sql.contains("(\"name\" LIKE '%son')"), "{sql}");
+
+        // 와일드카드는 리터럴로 이스케이프, negate는 NOT LIKE
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            r#"data %>% filter(str_ends(code, "10%_!", negate = TRUE))"#,
+        )
+        .unwrap();
+        assert!(
+            sql.contains("(`code` NOT LIKE '%10!%!_!!' ESCAPE '!')"),
+            "{sql}"
+        );
+
+        let sql = transpile_for(
+            Box::new(SqlServerDialect::new()),
+            r#"data %>% filter(str_starts(code, "[a]"))"#,
+        )
+        .unwrap();
+        assert!(sql.contains("([code] LIKE '![a]%' ESCAPE '!')"), "{sql}");
+
+        // LIKE가 대소문자를 구분하지 않는 dialect는 strict 모드에서 거부
+        let err = transpile_with(
+            Box::new(MySqlDialect::new()),
+            crate::options::TranspileOptions {
+                strict_mode: true,
+                ..Default::default()
+            },
+            r#"data %>% filter(str_starts(name, "A"))"#,
+        )
+        .unwrap_err();
+        assert!(err.to_string().contains("str_starts"), "{err}");
+    }
+
     #[test]
     fn test_tidyverse_casts_are_dialect_specific() {
         let pg_generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -659,6 +1115,27 @@ mod dialect_specific_tests {
         );
     }
 
+    #[test]
+    fn test_if_else_na_branch_renders_null() {
+        let sql = transpile("data %>% mutate(y = if_else(x > 0, x, NA))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, CASE WHEN (\"X\" > 0) THEN \"X\" ELSE NULL END AS \"Y\" FROM \"DATA\""
+        );
+
+        // 타입이 있는 NA 상수도 NULL
+        let sql = transpile(r#"data %>% mutate(y = ifelse(x > 0, NA_real_, x), z = if_else(ok, "a", NA_character_))"#)
+            .unwrap();
+        assert!(
+            sql.contains("CASE WHEN (\"x\" > 0) THEN NULL ELSE \"x\" END AS \"y\""),
+            "{sql}"
+        );
+        assert!(
+            sql.contains("CASE WHEN \"ok\" THEN 'a' ELSE NULL END AS \"z\""),
+            "{sql}"
+        );
+    }
+
     #[test]
     fn test_unsupported_named_argument_reports_argument_name() {
         let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -1007,6 +1484,15 @@ mod dialect_specific_tests {
         );
     }
 
+    #[test]
+    fn test_coalesce_passes_any_number_of_arguments() {
+        let sql = transpile("data %>% mutate(x = coalesce(a, b, c, d))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            normalize_sql("SELECT *, COALESCE(\"a\", \"b\", \"c\", \"d\") AS \"x\" FROM \"data\"")
+        );
+    }
+
     #[test]
     fn test_unsupported_case_function_is_rejected() {
         let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -1081,6 +1567,7 @@ mod dialect_specific_tests {
             function: "extension_agg".to_string(),
             column: "value".to_string(),
             alias: Some("result".to_string()),
+            expr: None,
         }];
 
         let error = generator.generate_aggregations(&aggregations).unwrap_err();
@@ -1098,6 +1585,7 @@ mod dialect_specific_tests {
             function: "extension_agg".to_string(),
             column: "value".to_string(),
             alias: Some("result".to_string()),
+            expr: None,
         }];
 
         let error = generator.generate_aggregations(&aggregations).unwrap_err();
@@ -1136,6 +1624,7 @@ mod dialect_specific_tests {
                         column: "name\"x".to_string(),
                         direction: OrderDirection::Asc,
                     }],
+                    by_group: false,
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1161,6 +1650,8 @@ mod dialect_specific_tests {
             operations: vec![
                 DplyrOperation::GroupBy {
                     columns: vec!["dept\"x".to_string()],
+                    add: false,
+                    sets: None,
                     location: SourceLocation::unknown(),
                 },
                 DplyrOperation::Summarise {
@@ -1168,7 +1659,10 @@ mod dialect_specific_tests {
                         function: "mean".to_string(),
                         column: "salary\"x".to_string(),
                         alias: Some("avg\"x".to_string()),
+                        expr: None,
                     }],
+                    groups: None,
+                    by: Vec::new(),
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1240,11 +1734,13 @@ mod dialect_specific_tests {
                 function: "median".to_string(),
                 column: "salary".to_string(),
                 alias: None,
+                expr: None,
             },
             Aggregation {
                 function: "mode".to_string(),
                 column: "category".to_string(),
                 alias: None,
+                expr: None,
             },
         ];
 
@@ -1276,6 +1772,7 @@ mod complex_query_tests {
                         column: "salary".to_string(),
                         direction: OrderDirection::Desc,
                     }],
+                    by_group: false,
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1305,6 +1802,8 @@ mod complex_query_tests {
             operations: vec![
                 DplyrOperation::GroupBy {
                     columns: vec!["department".to_string()],
+                    add: false,
+                    sets: None,
                     location: SourceLocation::unknown(),
                 },
                 DplyrOperation::Summarise {
@@ -1313,13 +1812,17 @@ mod complex_query_tests {
                             function: "mean".to_string(),
                             column: "salary".to_string(),
                             alias: Some("avg_salary".to_string()),
+                            expr: None,
                         },
                         Aggregation {
                             function: "n".to_string(),
                             column: "".to_string(),
                             alias: Some("count".to_string()),
+                            expr: None,
                         },
                     ],
+                    groups: None,
+                    by: Vec::new(),
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1346,6 +1849,8 @@ mod complex_query_tests {
             operations: vec![
                 DplyrOperation::GroupBy {
                     columns: vec!["dept".to_string()],
+                    add: false,
+                    sets: None,
                     location: SourceLocation::unknown(),
                 },
                 DplyrOperation::Summarise {
@@ -1353,7 +1858,10 @@ mod complex_query_tests {
                         function: "mean".to_string(),
                         column: "salary".to_string(),
                         alias: Some("avg".to_string()),
+                        expr: None,
                     }],
+                    groups: None,
+                    by: Vec::new(),
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1372,6 +1880,118 @@ mod complex_query_tests {
         );
     }
 
+    #[test]
+    fn test_ungrouped_summarise_single_aggregation() {
+        let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
+
+        let ast = DplyrNode::Pipeline {
+            source: Some("t".to_string()),
+            target: None,
+            operations: vec![DplyrOperation::Summarise {
+                aggregations: vec![Aggregation {
+                    function: "sum".to_string(),
+                    column: "x".to_string(),
+                    alias: Some("total".to_string()),
+                    expr: None,
+                }],
+                groups: None,
+                by: Vec::new(),
+                location: SourceLocation::unknown(),
+            }],
+            location: SourceLocation::unknown(),
+        };
+
+        let sql = generator.generate(&ast).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT SUM(\"X\") AS \"TOTAL\" FROM \"T\""
+        );
+    }
+
+    #[test]
+    fn test_ungrouped_summarise_multiple_aggregations() {
+        let generator = SqlGenerator::new(Box::new(MySqlDialect::new()));
+
+        let ast = DplyrNode::Pipeline {
+            source: Some("t".to_string()),
+            target: None,
+            operations: vec![
+                create_test_filter_operation("x", 0.0),
+                DplyrOperation::Summarise {
+                    aggregations: vec![
+                        Aggregation {
+                            function: "sum".to_string(),
+                            column: "x".to_string(),
+                            alias: Some("total".to_string()),
+                            expr: None,
+                        },
+                        Aggregation {
+                            function: "mean".to_string(),
+                            column: "y".to_string(),
+                            alias: Some("avg_y".to_string()),
+                            expr: None,
+                        },
+                        Aggregation {
+                            function: "n".to_string(),
+                            column: "".to_string(),
+                            alias: Some("n".to_string()),
+                            expr: None,
+                        },
+                    ],
+                    groups: None,
+                    by: Vec::new(),
+                    location: SourceLocation::unknown(),
+                },
+            ],
+            location: SourceLocation::unknown(),
+        };
+
+        let sql = generator.generate(&ast).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT SUM(`X`) AS `TOTAL`, AVG(`Y`) AS `AVG_Y`, COUNT(*) AS `N` FROM `T` WHERE (`X` > 0)"
+        );
+        assert!(!sql.contains("GROUP BY"));
+    }
+
+    #[test]
+    fn test_ungrouped_summarise_drops_prior_arrange() {
+        let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
+
+        let ast = DplyrNode::Pipeline {
+            source: Some("t".to_string()),
+            target: None,
+            operations: vec![
+                DplyrOperation::Arrange {
+                    columns: vec![OrderExpr {
+                        column: "x".to_string(),
+                        direction: OrderDirection::Desc,
+                    }],
+                    by_group: false,
+                    location: SourceLocation::unknown(),
+                },
+                DplyrOperation::Summarise {
+                    aggregations: vec![Aggregation {
+                        function: "max".to_string(),
+                        column: "x".to_string(),
+                        alias: Some("top".to_string()),
+                        expr: None,
+                    }],
+                    groups: None,
+                    by: Vec::new(),
+                    location: SourceLocation::unknown(),
+                },
+            ],
+            location: SourceLocation::unknown(),
+        };
+
+        let sql = generator.generate(&ast).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT MAX(\"X\") AS \"TOP\" FROM \"T\""
+        );
+    }
+
     #[test]
     fn test_group_by_after_summarise_is_metadata_only() {
         let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -1385,11 +2005,16 @@ mod complex_query_tests {
                         function: "n".to_string(),
                         column: "".to_string(),
                         alias: Some("n".to_string()),
+                        expr: None,
                     }],
+                    groups: None,
+                    by: Vec::new(),
                     location: SourceLocation::unknown(),
                 },
                 DplyrOperation::GroupBy {
                     columns: vec!["g".to_string()],
+                    add: false,
+                    sets: None,
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1414,6 +2039,8 @@ mod complex_query_tests {
             operations: vec![
                 DplyrOperation::GroupBy {
                     columns: vec!["g".to_string()],
+                    add: false,
+                    sets: None,
                     location: SourceLocation::unknown(),
                 },
                 DplyrOperation::Summarise {
@@ -1421,11 +2048,16 @@ mod complex_query_tests {
                         function: "n".to_string(),
                         column: "".to_string(),
                         alias: Some("n".to_string()),
+                        expr: None,
                     }],
+                    groups: None,
+                    by: Vec::new(),
                     location: SourceLocation::unknown(),
                 },
                 DplyrOperation::GroupBy {
                     columns: vec!["h".to_string()],
+                    add: false,
+                    sets: None,
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1508,6 +2140,8 @@ mod complex_query_tests {
                         },
                     },
                 ],
+                frame: None,
+                by: Vec::new(),
                 location: SourceLocation::unknown(),
             }],
             location: SourceLocation::unknown(),
@@ -1522,18 +2156,110 @@ mod complex_query_tests {
         assert!(normalized.contains("\"SALARY\" * 1.1"));
         assert!(normalized.contains("AS \"SALARY_BONUS\""));
     }
-}
 
-// ===== Error Case Tests =====
+    #[test]
+    fn test_arrange_by_group_prepends_group_keys() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
 
-mod error_case_tests {
-    use super::*;
+        let sql = transpiler
+            .transpile("data %>% group_by(region, city) %>% arrange(desc(sales), .by_group = TRUE)")
+            .unwrap();
+        assert!(sql.ends_with("ORDER BY \"region\" ASC, \"city\" ASC, \"sales\" DESC"));
+
+        // Without .by_group (or without groups) the keys are not added.
+        let sql = transpiler
+            .transpile("data %>% group_by(region) %>% arrange(desc(sales))")
+            .unwrap();
+        assert!(sql.ends_with("ORDER BY \"sales\" DESC"));
+        let sql = transpiler
+            .transpile("data %>% arrange(desc(sales), .by_group = TRUE)")
+            .unwrap();
+        assert!(sql.ends_with("ORDER BY \"sales\" DESC"));
+    }
 
     #[test]
-    fn test_empty_pipeline_error() {
-        let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
+    fn test_arrange_across_sorts_columns_descending() {
+        let sql = transpile("data %>% arrange(across(c(a, b), desc))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"DATA\" ORDER BY \"A\" DESC, \"B\" DESC"
+        );
+    }
 
-        let ast = DplyrNode::Pipeline {
+    #[test]
+    fn test_chained_arrange_replaces_earlier_keys() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
+
+        let sql = transpiler
+            .transpile("data %>% arrange(a) %>% arrange(desc(b))")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"DATA\" ORDER BY \"B\" DESC"
+        );
+
+        // 사이에 낀 head()는 첫 번째 정렬을 기준으로 행을 고른다
+        let sql = transpiler
+            .transpile("data %>% arrange(a) %>% head(5) %>% arrange(desc(b))")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM (SELECT * FROM \"DATA\" ORDER BY \"A\" ASC LIMIT 5) AS \"DATA\" \
+             ORDER BY \"B\" DESC"
+        );
+    }
+
+    #[test]
+    fn test_desc_renders_the_same_for_every_column_type() {
+        let schema = crate::Schema::new().with_typed_table(
+            "users",
+            [
+                ("name", "VARCHAR"),
+                ("signup_date", "DATE"),
+                ("age", "INTEGER"),
+            ],
+        );
+        let options = crate::TranspileOptions {
+            schema: Some(schema),
+            ..crate::TranspileOptions::default()
+        };
+        let dialects: Vec<Box<dyn SqlDialect>> = vec![
+            Box::new(PostgreSqlDialect::new()),
+            Box::new(MySqlDialect::new()),
+            Box::new(SqliteDialect::new()),
+            Box::new(DuckDbDialect::new()),
+        ];
+        for dialect in dialects {
+            let quoted = |column: &str| dialect.quote_identifier(column);
+            let expected = format!(
+                "ORDER BY {} DESC, {} DESC, {} DESC",
+                quoted("name"),
+                quoted("signup_date"),
+                quoted("age")
+            );
+            let dialect_name = dialect.dialect_name();
+            let sql = transpile_with(
+                dialect.clone_box(),
+                options.clone(),
+                "users %>% arrange(desc(name), desc(signup_date), desc(age))",
+            )
+            .unwrap();
+            // 문자열·날짜 열도 캐스트나 정렬 규칙 없이 숫자 열과 같게 렌더링
+            assert!(sql.ends_with(&expected), "{dialect_name}: {sql}");
+        }
+    }
+}
+
+// ===== Error Case Tests =====
+
+mod error_case_tests {
+    use super::*;
+
+    #[test]
+    fn test_empty_pipeline_error() {
+        let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
+
+        let ast = DplyrNode::Pipeline {
             source: None,
             target: None,
             operations: vec![],
@@ -1713,6 +2439,8 @@ mod mutate_advanced_tests {
             operations: vec![
                 DplyrOperation::GroupBy {
                     columns: vec!["department".to_string()],
+                    add: false,
+                    sets: None,
                     location: SourceLocation::unknown(),
                 },
                 DplyrOperation::Mutate {
@@ -1750,6 +2478,8 @@ mod mutate_advanced_tests {
                             },
                         },
                     ],
+                    frame: None,
+                    by: Vec::new(),
                     location: SourceLocation::unknown(),
                 },
             ],
@@ -1773,6 +2503,193 @@ mod mutate_advanced_tests {
         );
     }
 
+    #[test]
+    fn test_grouped_mutate_window_functions_follow_descending_arrange() {
+        let sql = transpile("events %>% group_by(g) %>% arrange(desc(ts)) %>% mutate(prev = lag(x), rn = row_number(), nxt = lead(x, 1, order_by = id))")
+            .unwrap();
+
+        // arrange()의 방향(DESC)이 OVER 절에 그대로 반영되어야 함
+        assert!(sql
+            .contains("LAG(\"x\", 1) OVER (PARTITION BY \"g\" ORDER BY \"ts\" DESC) AS \"prev\""));
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (PARTITION BY \"g\" ORDER BY \"ts\" DESC) AS \"rn\"")
+        );
+        // 명시적인 order_by가 arrange()보다 우선함
+        assert!(sql
+            .contains("LEAD(\"x\", 1, NULL) OVER (PARTITION BY \"g\" ORDER BY \"id\") AS \"nxt\""));
+
+        // 자체 order_by만 있는 윈도우는 arrange() 정렬을 소비하지 않음
+        let sql = transpile("events %>% group_by(g) %>% arrange(desc(ts)) %>% mutate(nxt = lead(x, 1, order_by = id))")
+            .unwrap();
+        assert!(sql.ends_with("ORDER BY \"ts\" DESC"), "{sql}");
+    }
+
+    #[test]
+    fn test_grouped_arrange_is_consumed_by_window() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
+
+        let sql = transpiler
+            .transpile("data %>% group_by(g) %>% arrange(x) %>% mutate(r = row_number())")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, ROW_NUMBER() OVER (PARTITION BY \"G\" ORDER BY \"X\" ASC) AS \"R\" FROM \"DATA\""
+        );
+
+        // 이후 mutate의 윈도우도 같은 정렬을 사용
+        let sql = transpiler
+            .transpile(
+                "data %>% group_by(g) %>% arrange(x) %>% mutate(r = row_number()) %>% mutate(c = cumsum(v))",
+            )
+            .unwrap();
+        assert!(
+            sql.contains("SUM(\"v\") OVER (PARTITION BY \"g\" ORDER BY \"x\" ASC ROWS BETWEEN"),
+            "{sql}"
+        );
+        assert!(
+            !normalize_sql(&sql).ends_with("ORDER BY \"X\" ASC"),
+            "{sql}"
+        );
+
+        // 순서와 무관한 윈도우나 그룹이 없으면 정렬은 그대로 남음
+        let sql = transpiler
+            .transpile("data %>% group_by(g) %>% arrange(x) %>% mutate(m = mean(v))")
+            .unwrap();
+        assert!(sql.ends_with("ORDER BY \"x\" ASC"), "{sql}");
+        let sql = transpiler
+            .transpile("data %>% arrange(x) %>% mutate(r = row_number())")
+            .unwrap();
+        assert!(sql.ends_with("ORDER BY \"x\" ASC"), "{sql}");
+    }
+
+    #[test]
+    fn test_growth_rate_uses_window_per_operand() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
+
+        let sql = transpiler
+            .transpile(
+                "sales %>% group_by(store) %>% arrange(month) %>% mutate(growth = (x - lag(x)) / lag(x))",
+            )
+            .unwrap();
+        // 산술식의 각 윈도우 피연산자가 자신의 OVER 절을 가져야 함
+        let window = "LAG(\"x\", 1) OVER (PARTITION BY \"store\" ORDER BY \"month\" ASC)";
+        assert!(
+            sql.contains(&format!("((\"x\" - {window}) / {window}) AS \"growth\"")),
+            "{sql}"
+        );
+
+        // 다른 컬럼을 참조하는 default도 같은 OVER 절을 사용
+        let sql = transpiler
+            .transpile(
+                "sales %>% arrange(month) %>% mutate(growth = x / lag(x, default = base) - 1)",
+            )
+            .unwrap();
+        assert!(
+            sql.contains(
+                "((\"x\" / LAG(\"x\", 1, \"base\") OVER (ORDER BY \"month\" ASC)) - 1) AS \"growth\""
+            ),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_mutate_by_partitions_only_that_mutate() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
+
+        let sql = transpiler
+            .transpile("data %>% mutate(rank = row_number(), .by = region)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, ROW_NUMBER() OVER (PARTITION BY \"REGION\") AS \"RANK\" FROM \"DATA\""
+        );
+
+        // 이후 mutate는 그룹 없이, arrange() 정렬은 결과 행에도 유지
+        let sql = transpiler
+            .transpile(
+                "data %>% arrange(ts) %>% mutate(prev = lag(x), .by = c(region, store)) %>% mutate(r = row_number())",
+            )
+            .unwrap();
+        assert!(
+            sql.contains(
+                "LAG(\"x\", 1) OVER (PARTITION BY \"region\", \"store\" ORDER BY \"ts\" ASC) AS \"prev\""
+            ),
+            "{sql}"
+        );
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (ORDER BY \"ts\" ASC) AS \"r\""),
+            "{sql}"
+        );
+        assert!(sql.ends_with("ORDER BY \"ts\" ASC"), "{sql}");
+
+        // 이미 그룹화된 데이터에는 .by를 쓸 수 없음
+        assert!(transpiler
+            .transpile("data %>% group_by(g) %>% mutate(r = row_number(), .by = region)")
+            .is_err());
+    }
+
+    #[test]
+    fn test_group_by_add_extends_previous_grouping() {
+        let transpiler = crate::Transpiler::new(Box::new(PostgreSqlDialect::new()));
+
+        let sql = transpiler
+            .transpile(
+                "sales %>% group_by(region) %>% group_by(year, region, .add = TRUE) %>% summarise(total = sum(amount))",
+            )
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", \"YEAR\", SUM(\"AMOUNT\") AS \"TOTAL\" FROM \"SALES\" GROUP BY \"REGION\", \"YEAR\""
+        );
+
+        // .add가 없으면 이전 그룹을 대체
+        let sql = transpiler
+            .transpile(
+                "sales %>% group_by(region) %>% group_by(year) %>% summarise(total = sum(amount))",
+            )
+            .unwrap();
+        assert!(sql.contains("GROUP BY \"year\""));
+        assert!(!sql.contains("region"));
+    }
+
+    #[test]
+    fn test_grouped_mutate_n_is_group_size() {
+        let sql =
+            transpile("events %>% group_by(g) %>% mutate(cnt = n(), share = x / n())").unwrap();
+
+        assert!(sql.contains("COUNT(*) OVER (PARTITION BY \"g\") AS \"cnt\""));
+        assert!(sql.contains("(\"x\" / COUNT(*) OVER (PARTITION BY \"g\")) AS \"share\""));
+        // summarise()에서는 기존 집계 의미를 유지
+        let sql = transpile("events %>% group_by(g) %>% summarise(cnt = n())").unwrap();
+        assert!(sql.contains("COUNT(*) AS \"cnt\""));
+        assert!(!sql.contains("OVER"));
+    }
+
+    #[test]
+    fn test_grouped_mutate_cur_group_id_ranks_group_keys() {
+        let sql =
+            transpile("events %>% group_by(g, h) %>% arrange(ts) %>% mutate(gid = cur_group_id())")
+                .unwrap();
+
+        // 그룹 ID는 arrange() 순서가 아니라 그룹 키 순서를 따름
+        assert!(sql.contains("DENSE_RANK() OVER (ORDER BY \"g\", \"h\") AS \"gid\""));
+
+        let sql = transpile("events %>% mutate(gid = cur_group_id(), cnt = n())").unwrap();
+        assert!(sql.contains("1 AS \"gid\""));
+        assert!(sql.contains("COUNT(*) OVER () AS \"cnt\""));
+    }
+
+    #[test]
+    fn test_ungrouped_mutate_window_function_follows_arrange() {
+        let sql = transpile_for(
+            Box::new(SqliteDialect::new()),
+            "events %>% arrange(desc(ts), id) %>% mutate(rk = dense_rank())",
+        )
+        .unwrap();
+
+        assert!(sql.contains("DENSE_RANK() OVER (ORDER BY \"ts\" DESC, \"id\" ASC) AS \"rk\""));
+    }
+
     #[test]
     fn test_mutate_subquery_generation() {
         let generator = SqlGenerator::new(Box::new(PostgreSqlDialect::new()));
@@ -1827,6 +2744,8 @@ mod mutate_advanced_tests {
                         ],
                     },
                 }],
+                frame: None,
+                by: Vec::new(),
                 location: SourceLocation::unknown(),
             },
         ];
@@ -1902,4 +2821,3178 @@ mod mutate_advanced_tests {
         let literal_expr = Expr::Literal(LiteralValue::Number(42.0));
         assert!(!generator.expression_is_complex(&literal_expr));
     }
+
+    #[test]
+    fn test_mutate_across_names_template_expands_columns() {
+        let sql = transpile(r#"data %>% mutate(across(c(a, b), ~ .x * 2, .names = "{.col}_dbl"))"#)
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, (\"A\" * 2) AS \"A_DBL\", (\"B\" * 2) AS \"B_DBL\" FROM \"DATA\""
+        );
+    }
+
+    #[test]
+    fn test_mutate_across_names_template_fn_and_duplicates() {
+        let transpile = |code: &str| transpile(code).map_err(|e| e.to_string());
+        // 함수가 하나이면 {.fn}은 "1"
+        let sql =
+            transpile(r#"data %>% mutate(across(c(a, b), ~ .x * 2, .names = "{.col}_{.fn}"))"#)
+                .unwrap();
+        assert!(sql.contains("AS \"a_1\", (\"b\" * 2) AS \"b_1\""), "{sql}");
+
+        // {.col}이 없으면 모든 열이 같은 이름이 되므로 오류
+        let err = transpile(r#"data %>% mutate(across(c(a, b), ~ .x * 2, .names = "{col}_x"))"#)
+            .unwrap_err();
+        assert!(err.contains("{col}_x"), "{err}");
+
+        let schema =
+            crate::Schema::new().with_typed_table("data", [("a", "INTEGER"), ("b", "INTEGER")]);
+        let err = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            crate::TranspileOptions {
+                schema: Some(schema),
+                ..crate::TranspileOptions::default()
+            },
+            r#"data %>% mutate(across(where(is.numeric), ~ .x * 2, .names = "x"))"#,
+        )
+        .unwrap_err()
+        .to_string();
+        assert!(err.contains("name 'x'"), "{err}");
+    }
+
+    #[test]
+    fn test_mutate_across_where_uses_schema_types() {
+        let schema = crate::Schema::new().with_typed_table(
+            "sales",
+            [
+                ("region", "VARCHAR"),
+                ("units", "INTEGER"),
+                ("price", "DECIMAL(10, 2)"),
+            ],
+        );
+        let transpiler = crate::Transpiler::with_options(
+            Box::new(PostgreSqlDialect::new()),
+            crate::TranspileOptions {
+                schema: Some(schema),
+                ..crate::TranspileOptions::default()
+            },
+        );
+        let sql = transpiler
+            .transpile("sales %>% mutate(across(where(is.numeric), ~ . * 1.0))")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", (\"UNITS\" * 1) AS \"UNITS\", (\"PRICE\" * 1) AS \"PRICE\" FROM \"SALES\""
+        );
+
+        // 앞선 select()에 남은 숫자 열만 바뀜
+        let sql = transpiler
+            .transpile(
+                "sales %>% select(region, units) %>% mutate(across(where(is.numeric), ~ . * 1.0))",
+            )
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", (\"UNITS\" * 1) AS \"UNITS\" FROM \"SALES\""
+        );
+
+        // 스키마가 없으면 열을 고를 수 없다
+        let err = transpile("sales %>% mutate(across(where(is.numeric), ~ .x * 2))").unwrap_err();
+        assert!(err.to_string().contains("across()"), "{err}");
+    }
+
+    #[test]
+    fn test_mutate_referencing_earlier_assignment_nests_subquery() {
+        let sql = transpile("data %>% mutate(a = x + 1, b = a * 2, c = x - 1)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, (\"A\" * 2) AS \"B\", (\"X\" - 1) AS \"C\" FROM (SELECT *, (\"X\" + 1) AS \"A\" FROM \"DATA\") AS \"DATA\""
+        );
+
+        // 연쇄 의존은 단계마다 한 겹씩, 그룹은 바깥 쿼리에 유지되고 정렬은 윈도우가 소비
+        let sql = transpile(
+            "data %>% group_by(g) %>% arrange(t) %>% mutate(a = lag(x), b = a * 2, c = b + a)",
+        )
+        .unwrap();
+        assert_eq!(normalize_sql(&sql).matches("FROM (SELECT").count(), 2);
+        assert!(
+            sql.contains("LAG(\"x\", 1) OVER (PARTITION BY \"g\" ORDER BY \"t\" ASC)"),
+            "{sql}"
+        );
+        assert!(!sql.trim_end().ends_with("ORDER BY \"t\" ASC"), "{sql}");
+    }
+
+    #[test]
+    fn test_mutate_trailing_window_frame() {
+        let sql = transpile("data %>% arrange(day) %>% mutate(roll = mean(x), .frame = c(-2, 0))")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, AVG(\"X\") OVER (ORDER BY \"DAY\" ASC ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS \"ROLL\" FROM \"DATA\" ORDER BY \"DAY\" ASC"
+        );
+
+        // 프레임이 없으면 그룹 전체 집계, 누적 함수는 현재 행까지
+        let sql = transpile(
+            "data %>% group_by(g) %>% arrange(day) %>% mutate(share = x / sum(x), run = cumsum(x))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("(\"x\" / SUM(\"x\") OVER (PARTITION BY \"g\")) AS \"share\""),
+            "{sql}"
+        );
+        assert!(
+            sql.contains("SUM(\"x\") OVER (PARTITION BY \"g\" ORDER BY \"day\" ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS \"run\""),
+            "{sql}"
+        );
+
+        // arrange()가 없으면 엔진의 행 순서를 따른다
+        let sql = transpile("data %>% mutate(roll = mean(x), .frame = c(-2, 0))").unwrap();
+        assert!(
+            sql.contains("AVG(\"x\") OVER (ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS \"roll\""),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_grouped_ranking_without_arrange() {
+        // ORDER BY 없이 그룹 분할만 한다
+        let code = "data %>% group_by(g) %>% mutate(r = row_number(), k = rank(), run = cumsum(x))";
+        let sql = transpile(code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, ROW_NUMBER() OVER (PARTITION BY \"G\") AS \"R\", RANK() OVER (PARTITION BY \"G\") AS \"K\", SUM(\"X\") OVER (PARTITION BY \"G\" ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS \"RUN\" FROM \"DATA\""
+        );
+
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            "data %>% group_by(g) %>% mutate(r = row_number())",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (PARTITION BY `g`) AS `r`"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_case_match_renders_simple_case() {
+        let sql = transpile(
+            r#"data %>% mutate(code = case_match(grade, "a" ~ 1, "b" ~ 2, .default = 0))"#,
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, CASE \"GRADE\" WHEN 'A' THEN 1 WHEN 'B' THEN 2 ELSE 0 END AS \"CODE\" FROM \"DATA\""
+        );
+
+        // .default가 없으면 ELSE 없이 NULL, 벡터 왼쪽은 값마다 WHEN
+        let sql =
+            transpile(r#"data %>% mutate(code = case_match(grade, c("a", "b") ~ "top"))"#).unwrap();
+        assert!(
+            sql.contains("CASE \"grade\" WHEN 'a' THEN 'top' WHEN 'b' THEN 'top' END AS \"code\""),
+            "{sql}"
+        );
+
+        let err = transpile("data %>% mutate(code = case_match(grade, .default = 0))").unwrap_err();
+        assert!(err.to_string().contains("case_match()"), "{err}");
+    }
+
+    #[test]
+    fn test_case_match_na_uses_searched_case() {
+        // WHEN NULL은 절대 일치하지 않으므로 IS NULL 조건으로 바꿈
+        let sql =
+            transpile(r#"data %>% mutate(y = case_match(x, NA ~ 0, c(1, 2) ~ 10, .default = x))"#)
+                .unwrap();
+        assert!(
+            sql.contains(
+                "CASE WHEN \"x\" IS NULL THEN 0 WHEN \"x\" = 1 THEN 10 \
+                 WHEN \"x\" = 2 THEN 10 ELSE \"x\" END AS \"y\""
+            ),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_str_glue_renders_concatenation() {
+        let code = r#"data %>% mutate(label = str_glue("{city}, {state}"))"#;
+        let sql = transpile_for(Box::new(SqliteDialect::new()), code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, (\"CITY\" || ', ' || \"STATE\") AS \"LABEL\" FROM \"DATA\""
+        );
+
+        // 방언의 문자열 연결을 사용
+        let sql = transpile_for(Box::new(MySqlDialect::new()), code).unwrap();
+        assert!(
+            sql.contains("CONCAT(`city`, ', ', `state`) AS `label`"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_condition_renders_as_boolean_column() {
+        let code = "data %>% mutate(is_expensive = price > 100)";
+        let sql = transpile_for(Box::new(DuckDbDialect::new()), code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, (\"PRICE\" > 100) AS \"IS_EXPENSIVE\" FROM \"DATA\""
+        );
+
+        let sql = transpile_for(Box::new(MySqlDialect::new()), code).unwrap();
+        assert!(sql.contains("(`price` > 100) AS `is_expensive`"), "{sql}");
+
+        // 조건을 값으로 쓸 수 없는 dialect는 CASE로 감싼다
+        let sql = transpile_for(
+            Box::new(SqlServerDialect::new()),
+            "data %>% mutate(is_expensive = price > 100, missing = is.na(x), y = x + 1)",
+        )
+        .unwrap();
+        assert!(
+            sql.contains(
+                "CASE WHEN ([price] > 100) THEN 1 ELSE 0 END AS [is_expensive], \
+                 CASE WHEN ([x] IS NULL) THEN 1 ELSE 0 END AS [missing], ([x] + 1) AS [y]"
+            ),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_cast_helper_maps_type_names() {
+        let code = r#"data %>% mutate(a = cast(y, "integer"), b = cast(y, "varchar"))"#;
+        let sql = transpile(code).unwrap();
+        assert!(
+            sql.contains("CAST(\"y\" AS INTEGER) AS \"a\", CAST(\"y\" AS TEXT) AS \"b\""),
+            "{sql}"
+        );
+
+        let sql = transpile_for(Box::new(MySqlDialect::new()), code).unwrap();
+        assert!(
+            sql.contains("CAST(`y` AS SIGNED) AS `a`, CAST(`y` AS CHAR) AS `b`"),
+            "{sql}"
+        );
+
+        // MySQL CAST에는 VARCHAR(n)나 BOOLEAN 대상이 없음
+        let sql = transpile_for(Box::new(MySqlDialect::new()), r#"data %>% mutate(a = cast(y, "varchar(20)"), b = cast(y, "boolean"), c = cast(y, "bigint"), d = cast(y, "numeric(10, 2)"))"#)
+            .unwrap();
+        assert!(
+            sql.contains(
+                "CAST(`y` AS CHAR(20)) AS `a`, CAST(`y` AS SIGNED) AS `b`, \
+                 CAST(`y` AS SIGNED) AS `c`, CAST(`y` AS DECIMAL(10, 2)) AS `d`"
+            ),
+            "{sql}"
+        );
+
+        let sql = transpile_for(Box::new(SqlServerDialect::new()), code).unwrap();
+        assert!(
+            sql.contains("CAST([y] AS INT) AS [a], CAST([y] AS NVARCHAR(MAX)) AS [b]"),
+            "{sql}"
+        );
+
+        // 알려지지 않은 타입 이름은 대문자로 그대로 전달
+        let sql = transpile_for(
+            Box::new(DuckDbDialect::new()),
+            r#"data %>% mutate(d = cast(y, "decimal(10, 2)"))"#,
+        )
+        .unwrap();
+        assert!(sql.contains("CAST(\"y\" AS DECIMAL(10, 2))"), "{sql}");
+
+        let err = transpile(r#"data %>% mutate(d = cast(y, "int; DROP TABLE t"))"#).unwrap_err();
+        assert!(err.to_string().contains("cast()"), "{err}");
+    }
+
+    #[test]
+    fn test_vector_selects_columns_in_across_and_select() {
+        let sql =
+            transpile("data %>% mutate(across(c(a, b), round)) %>% select(c(a, b), id)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT ROUND(\"A\") AS \"A\", ROUND(\"B\") AS \"B\", \"ID\" FROM \"DATA\""
+        );
+    }
+
+    #[test]
+    fn test_select_after_mutate_keeps_listed_order() {
+        let transpile = |code: &str| {
+            let sql = transpile(code).unwrap();
+            normalize_sql(&sql)
+        };
+
+        // select()가 마지막으로 지정한 순서를 따른다
+        assert_eq!(
+            transpile("data %>% mutate(z = x + 1) %>% select(z, a, x)"),
+            "SELECT (\"X\" + 1) AS \"Z\", \"A\", \"X\" FROM \"DATA\""
+        );
+        assert_eq!(
+            transpile("data %>% mutate(z = x + 1) %>% rename(zz = z) %>% select(a, zz)"),
+            "SELECT \"A\", (\"X\" + 1) AS \"ZZ\" FROM \"DATA\""
+        );
+
+        // 기존 열을 바꾸는 mutate()와 rename()은 위치를 유지
+        assert_eq!(
+            transpile("data %>% select(c, a, b) %>% mutate(a = a * 2, d = b)"),
+            "SELECT \"C\", (\"A\" * 2) AS \"A\", \"B\", \"B\" AS \"D\" FROM \"DATA\""
+        );
+        assert_eq!(
+            transpile("data %>% select(b, a) %>% rename(x = b) %>% select(a, x)"),
+            "SELECT \"A\", \"B\" AS \"X\" FROM \"DATA\""
+        );
+
+        // 이전 mutate()의 열을 참조하면 한 단계 위에서 계산
+        assert_eq!(
+            transpile("data %>% mutate(z = x + 1) %>% select(z, a) %>% mutate(w = z * 2) %>% select(w, z)"),
+            "SELECT (\"Z\" * 2) AS \"W\", \"Z\" FROM (SELECT (\"X\" + 1) AS \"Z\", \"A\" FROM \"DATA\") AS \"DATA\""
+        );
+    }
+
+    #[test]
+    fn test_select_computed_column() {
+        let transpile = |code: &str| {
+            let sql = transpile(code).unwrap();
+            normalize_sql(&sql)
+        };
+
+        assert_eq!(
+            transpile("data %>% select(total = price * qty, region)"),
+            "SELECT (\"PRICE\" * \"QTY\") AS \"TOTAL\", \"REGION\" FROM \"DATA\""
+        );
+
+        // mutate()로 만든 열을 쓰는 계산 열은 한 단계 위에서 계산
+        assert_eq!(
+            transpile("data %>% mutate(net = price - cost) %>% select(total = net * qty, region)"),
+            "SELECT (\"NET\" * \"QTY\") AS \"TOTAL\", \"REGION\" FROM (SELECT *, (\"PRICE\" - \"COST\") AS \"NET\" FROM \"DATA\") AS \"DATA\""
+        );
+    }
+}
+
+// ===== Transpile Options Tests =====
+
+mod options_tests {
+    use super::*;
+    use crate::options::{NullsOrdering, Schema, TranspileOptions};
+
+    fn no_star_options(schema: Option<Schema>) -> TranspileOptions {
+        TranspileOptions {
+            no_select_star: true,
+            schema,
+            ..TranspileOptions::default()
+        }
+    }
+
+    #[test]
+    fn test_no_select_star_without_schema_errors() {
+        let err = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            no_star_options(None),
+            "users %>% filter(age > 18)",
+        )
+        .unwrap_err();
+        assert!(
+            err.to_string().contains("Schema required"),
+            "unexpected error: {err}"
+        );
+        assert!(err.to_string().contains("users"), "unexpected error: {err}");
+    }
+
+    #[test]
+    fn test_no_select_star_expands_schema_columns() {
+        let schema = Schema::new().with_table("users", ["id", "name", "age"]);
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            no_star_options(Some(schema)),
+            "users %>% filter(age > 18)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"NAME\", \"AGE\" FROM \"USERS\" WHERE (\"AGE\" > 18)"
+        );
+    }
+
+    #[test]
+    fn test_no_select_star_keeps_explicit_select() {
+        // 명시적 select는 스키마 없이도 허용
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            no_star_options(None),
+            "users %>% select(id, name)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"NAME\" FROM \"USERS\""
+        );
+    }
+
+    #[test]
+    fn test_no_select_star_expands_mutate_and_rename() {
+        let schema = Schema::new().with_table("users", ["id", "name", "age"]);
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            no_star_options(Some(schema.clone())),
+            "users %>% mutate(age2 = age * 2)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"NAME\", \"AGE\", (\"AGE\" * 2) AS \"AGE2\" FROM \"USERS\""
+        );
+
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            no_star_options(Some(schema)),
+            "users %>% rename(full_name = name)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"NAME\" AS \"FULL_NAME\", \"AGE\" FROM \"USERS\""
+        );
+    }
+
+    #[test]
+    fn test_no_select_star_expands_joined_tables() {
+        let schema = Schema::new()
+            .with_table("orders", ["id", "user_id"])
+            .with_table("users", ["user_id", "name"]);
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            no_star_options(Some(schema)),
+            "orders %>% left_join(users, by = \"user_id\")",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ORDERS\".\"ID\", \"ORDERS\".\"USER_ID\", \"USERS\".\"USER_ID\", \"USERS\".\"NAME\" \
+             FROM \"ORDERS\" LEFT JOIN \"USERS\" ON \"ORDERS\".\"USER_ID\" = \"USERS\".\"USER_ID\""
+        );
+    }
+
+    #[test]
+    fn test_chained_joins_qualify_keys_by_owning_table() {
+        let schema = Schema::new()
+            .with_table("orders", ["id", "customer_id", "amount"])
+            .with_table("customers", ["customer_id", "name", "region_id"])
+            .with_table("regions", ["region_id", "region"]);
+        let options = TranspileOptions {
+            schema: Some(schema),
+            ..TranspileOptions::default()
+        };
+
+        let sql = transpile_with(Box::new(PostgreSqlDialect::new()),
+            options.clone(),
+            "orders %>% left_join(customers, by = \"customer_id\") %>% left_join(regions, by = \"region_id\")",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"ORDERS\" \
+             LEFT JOIN \"CUSTOMERS\" ON \"ORDERS\".\"CUSTOMER_ID\" = \"CUSTOMERS\".\"CUSTOMER_ID\" \
+             LEFT JOIN \"REGIONS\" ON \"CUSTOMERS\".\"REGION_ID\" = \"REGIONS\".\"REGION_ID\""
+        );
+
+        // by 생략 시 공통 컬럼으로 조인
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "orders %>% left_join(customers) %>% left_join(regions)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"ORDERS\" \
+             LEFT JOIN \"CUSTOMERS\" ON \"ORDERS\".\"CUSTOMER_ID\" = \"CUSTOMERS\".\"CUSTOMER_ID\" \
+             LEFT JOIN \"REGIONS\" ON \"CUSTOMERS\".\"REGION_ID\" = \"REGIONS\".\"REGION_ID\""
+        );
+    }
+
+    #[test]
+    fn test_join_without_by_or_schema_is_natural() {
+        let sql = transpile_for(
+            Box::new(PostgreSqlDialect::new()),
+            "orders %>% left_join(customers) %>% inner_join(regions)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"ORDERS\" NATURAL LEFT JOIN \"CUSTOMERS\" NATURAL INNER JOIN \"REGIONS\""
+        );
+
+        let err = transpile_for(
+            Box::new(PostgreSqlDialect::new()),
+            "orders %>% semi_join(customers)",
+        )
+        .unwrap_err();
+        assert!(
+            err.to_string().contains("semi_join"),
+            "unexpected error: {err}"
+        );
+    }
+
+    #[test]
+    fn test_group_by_ordinals_reference_select_positions() {
+        let code = "sales %>% group_by(region, year) %>% summarise(total = sum(amount))";
+        let sql = transpile_for(Box::new(PostgreSqlDialect::new()), code).unwrap();
+        assert!(
+            normalize_sql(&sql).ends_with("GROUP BY \"REGION\", \"YEAR\""),
+            "{sql}"
+        );
+
+        let options = TranspileOptions {
+            group_by_ordinals: true,
+            ..TranspileOptions::default()
+        };
+        let sql =
+            transpile_with(Box::new(PostgreSqlDialect::new()), options.clone(), code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", \"YEAR\", SUM(\"AMOUNT\") AS \"TOTAL\" FROM \"SALES\" GROUP BY 1, 2"
+        );
+
+        // 별칭을 참조하는 summarise의 내부 쿼리도 위치 참조를 사용
+        let sql = transpile_with(Box::new(PostgreSqlDialect::new()),
+            options.clone(),
+            "sales %>% group_by(region) %>% summarise(total = sum(amount), share = max(amount) / total)",
+        )
+        .unwrap();
+        assert!(normalize_sql(&sql).contains("GROUP BY 1)"), "{sql}");
+
+        // 그룹이 없으면 GROUP BY도 없음
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "sales %>% summarise(total = sum(amount))",
+        )
+        .unwrap();
+        assert!(!sql.contains("GROUP BY"), "{sql}");
+    }
+
+    #[test]
+    fn test_explain_and_analyze_prefix_the_query() {
+        let code = "users %>% filter(age > 18)";
+        let explain = TranspileOptions {
+            explain: true,
+            ..TranspileOptions::default()
+        };
+        let analyze = TranspileOptions {
+            explain: true,
+            analyze: true,
+            ..TranspileOptions::default()
+        };
+
+        let sql =
+            transpile_with(Box::new(PostgreSqlDialect::new()), explain.clone(), code).unwrap();
+        assert_eq!(
+            sql,
+            "EXPLAIN SELECT *\nFROM \"users\"\nWHERE (\"age\" > 18)"
+        );
+        let sql =
+            transpile_with(Box::new(PostgreSqlDialect::new()), analyze.clone(), code).unwrap();
+        assert!(sql.starts_with("EXPLAIN ANALYZE SELECT *"), "{sql}");
+
+        fn with_dialect(
+            dialect: Box<dyn SqlDialect>,
+            options: TranspileOptions,
+        ) -> Result<String, String> {
+            transpile_with(dialect, options, "users %>% filter(age > 18)")
+                .map_err(|e| e.to_string())
+        }
+        let sql = with_dialect(Box::new(DuckDbDialect::new()), analyze.clone()).unwrap();
+        assert!(sql.starts_with("EXPLAIN ANALYZE SELECT"), "{sql}");
+        let sql = with_dialect(Box::new(MySqlDialect::new()), explain.clone()).unwrap();
+        assert!(sql.starts_with("EXPLAIN SELECT"), "{sql}");
+
+        // SQLite는 EXPLAIN QUERY PLAN만 지원
+        let sql = with_dialect(Box::new(SqliteDialect::new()), explain.clone()).unwrap();
+        assert!(sql.starts_with("EXPLAIN QUERY PLAN SELECT"), "{sql}");
+        let err = with_dialect(Box::new(SqliteDialect::new()), analyze).unwrap_err();
+        assert!(err.contains("explain analyze"), "{err}");
+
+        // 오류는 요청한 작업을 그대로 표시
+        let err = with_dialect(Box::new(SqlServerDialect::new()), explain).unwrap_err();
+        assert!(err.contains("'explain'"), "{err}");
+    }
+
+    #[test]
+    fn test_implicit_alias_drops_as_keyword() {
+        let code = "sales %>% mutate(net = price - cost) %>% group_by(region) %>% summarise(total = sum(net))";
+        let sql = transpile_for(Box::new(PostgreSqlDialect::new()), code).unwrap();
+        assert!(sql.contains("SUM(\"net\") AS \"total\""), "{sql}");
+
+        let options = TranspileOptions {
+            implicit_alias: true,
+            ..TranspileOptions::default()
+        };
+        let sql =
+            transpile_with(Box::new(PostgreSqlDialect::new()), options.clone(), code).unwrap();
+        assert!(sql.contains("SUM(\"net\") \"total\""), "{sql}");
+        assert!(!sql.contains("AS \"total\""), "{sql}");
+
+        // 투영 별칭도 같은 스타일, 파생 테이블은 AS 유지
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "t %>% select(id, label = name) %>% mutate(a = x + 1, b = a * 2)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, (\"A\" * 2) \"B\" FROM (SELECT \"ID\", \"NAME\" \"LABEL\", (\"X\" + 1) \"A\" FROM \"T\") AS \"T\""
+        );
+    }
+
+    #[test]
+    fn test_string_literal_whitespace() {
+        let code = r#"data %>% filter(x == "  x ") %>% mutate(y = "a \t  b")"#;
+        let sql = transpile_for(Box::new(PostgreSqlDialect::new()), code).unwrap();
+        // 기본값: 따옴표만 벗기고 공백은 그대로
+        assert!(sql.contains("(\"x\" = '  x ')"), "{sql}");
+        assert!(sql.contains("'a \t  b' AS \"y\""), "{sql}");
+
+        let options = TranspileOptions {
+            collapse_whitespace_in_strings: true,
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(Box::new(PostgreSqlDialect::new()), options, code).unwrap();
+        assert!(sql.contains("(\"x\" = ' x ')"), "{sql}");
+        assert!(sql.contains("'a b' AS \"y\""), "{sql}");
+    }
+
+    #[test]
+    fn test_nulls_ordering_per_dialect() {
+        let options = TranspileOptions {
+            nulls_ordering: Some(NullsOrdering::Last),
+            ..TranspileOptions::default()
+        };
+        let code = "data %>% arrange(a, desc(b))";
+        let sql = transpile_with(Box::new(DuckDbDialect::new()), options.clone(), code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"DATA\" ORDER BY \"A\" ASC NULLS LAST, \"B\" DESC NULLS LAST"
+        );
+
+        // MySQL은 NULLS LAST가 없어 IS NULL 키를 앞에 둔다
+        let sql = transpile_with(Box::new(MySqlDialect::new()), options, code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM `DATA` ORDER BY `A` IS NULL, `A` ASC, `B` IS NULL, `B` DESC"
+        );
+
+        let options = TranspileOptions {
+            nulls_ordering: Some(NullsOrdering::First),
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(Box::new(MySqlDialect::new()), options.clone(), code).unwrap();
+        assert!(sql.contains("`a` IS NOT NULL, `a` ASC"), "{sql}");
+        let sql = transpile_with(Box::new(PostgreSqlDialect::new()), options, code).unwrap();
+        assert!(sql.contains("\"b\" DESC NULLS FIRST"), "{sql}");
+
+        // 기본값은 엔진의 NULL 위치를 따른다
+        let sql = transpile_for(Box::new(PostgreSqlDialect::new()), code).unwrap();
+        assert!(!sql.contains("NULLS"), "{sql}");
+    }
+
+    #[test]
+    fn test_optimize_merges_filters_and_selects() {
+        let code = "data %>% select(a, b) %>% select(a) %>% filter(a > 1) %>% filter(a < 5)";
+        let options = TranspileOptions {
+            optimize: true,
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(Box::new(PostgreSqlDialect::new()), options, code).unwrap();
+        assert_eq!(
+            sql,
+            "SELECT \"a\"\nFROM \"data\"\nWHERE ((\"a\" > 1) AND (\"a\" < 5))"
+        );
+    }
+
+    #[test]
+    fn test_trailing_newline_and_single_spacing() {
+        let code = "data %>% filter(x > 1)";
+        let sql = transpile_for(Box::new(PostgreSqlDialect::new()), code).unwrap();
+        assert_eq!(sql, "SELECT *\nFROM \"data\"\nWHERE (\"x\" > 1)");
+
+        let options = TranspileOptions {
+            trailing_newline: true,
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(Box::new(PostgreSqlDialect::new()), options, code).unwrap();
+        assert_eq!(sql, "SELECT *\nFROM \"data\"\nWHERE (\"x\" > 1)\n");
+
+        // 비어 있는 절이 공백을 남기지 않는다
+        for code in [
+            "data %>% filter(x > 1)",
+            "data %>% filter(x > 1) %>% filter(y < 2)",
+            "data %>% group_by(g) %>% filter(x > 1)",
+        ] {
+            let sql = transpile_for(Box::new(PostgreSqlDialect::new()), code).unwrap();
+            assert!(!sql.contains("  "), "{sql}");
+            assert!(sql.lines().all(|line| line == line.trim()), "{sql}");
+        }
+    }
+
+    #[test]
+    fn test_max_pipeline_depth_rejects_longer_pipelines() {
+        let code = "sales %>% filter(amount > 0) %>% select(region, amount) %>% arrange(amount)";
+        let options = |max_pipeline_depth| TranspileOptions {
+            max_pipeline_depth,
+            ..TranspileOptions::default()
+        };
+
+        // 0은 제한 없음
+        assert!(transpile_with(Box::new(PostgreSqlDialect::new()), options(0), code).is_ok());
+        assert!(transpile_with(Box::new(PostgreSqlDialect::new()), options(3), code).is_ok());
+
+        let err = transpile_with(Box::new(PostgreSqlDialect::new()), options(2), code).unwrap_err();
+        assert!(
+            err.to_string()
+                .contains("Pipeline too long: 3 steps (max: 2)"),
+            "{err}"
+        );
+
+        // 중첩된 파이프라인의 단계도 합산
+        let err = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options(3),
+            "a %>% filter(x > 0) %>% bind_rows(b %>% filter(x > 1) %>% select(x))",
+        )
+        .unwrap_err();
+        assert!(err.to_string().contains("4 steps"), "{err}");
+    }
+
+    #[test]
+    fn test_function_map_overrides_aggregate_name() {
+        let mut options = TranspileOptions::default();
+        options
+            .function_map
+            .insert("mean".to_string(), "AVERAGE".to_string());
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "sales %>% group_by(region) %>% summarise(avg_price = mean(price))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("AVERAGE(\"price\") AS \"avg_price\""),
+            "override should replace AVG: {sql}"
+        );
+        assert!(!sql.contains("AVG("));
+    }
+
+    #[test]
+    fn test_function_map_keeps_window_of_mutate_aggregates() {
+        // 이름만 바뀌고 OVER 절은 그대로 유지
+        let mut options = TranspileOptions::default();
+        options
+            .function_map
+            .insert("mean".to_string(), "AVERAGE".to_string());
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options.clone(),
+            "sales %>% group_by(region) %>% mutate(m = mean(price))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("AVERAGE(\"price\") OVER (PARTITION BY \"region\") AS \"m\""),
+            "{sql}"
+        );
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "sales %>% mutate(m = mean(price))",
+        )
+        .unwrap();
+        assert!(sql.contains("AVERAGE(\"price\") OVER () AS \"m\""), "{sql}");
+    }
+
+    #[test]
+    fn test_function_map_overrides_scalar_function_and_udf() {
+        let mut options = TranspileOptions::default();
+        options
+            .function_map
+            .insert("toupper".to_string(), "UCASE".to_string());
+        options.function_map.insert(
+            "normalize_name".to_string(),
+            "my_schema.normalize".to_string(),
+        );
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "users %>% mutate(a = toupper(name), b = normalize_name(name, 1))",
+        )
+        .unwrap();
+        assert!(sql.contains("UCASE(\"name\") AS \"a\""), "{sql}");
+        assert!(
+            sql.contains("my_schema.normalize(\"name\", 1) AS \"b\""),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_default_options_keep_select_star() {
+        let sql = transpile_for(
+            Box::new(PostgreSqlDialect::new()),
+            "users %>% filter(age > 18)",
+        )
+        .unwrap();
+        assert!(normalize_sql(&sql).starts_with("SELECT * FROM \"USERS\""));
+    }
+}
+
+// ===== Row/Column Binding Tests =====
+
+mod bind_tests {
+    use super::*;
+    use crate::options::{Schema, TranspileOptions};
+
+    #[test]
+    fn test_bind_rows_two_tables() {
+        let sql = transpile("a %>% bind_rows(b)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"A\" UNION ALL SELECT * FROM \"B\""
+        );
+    }
+
+    #[test]
+    fn test_bind_rows_with_pipelines_on_both_sides() {
+        let sql = transpile("a %>% filter(x > 1) %>% bind_rows(b %>% filter(x < 0))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"A\" WHERE (\"X\" > 1) UNION ALL SELECT * FROM \"B\" WHERE (\"X\" < 0)"
+        );
+    }
+
+    #[test]
+    fn test_bind_rows_followed_by_operations_wraps_union() {
+        let sql = transpile("a %>% bind_rows(b) %>% arrange(x)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM (SELECT * FROM \"A\" UNION ALL SELECT * FROM \"B\") AS \"A\" ORDER BY \"X\" ASC"
+        );
+    }
+
+    #[test]
+    fn test_bind_rows_aligns_mismatched_columns_with_schema() {
+        let options = TranspileOptions {
+            schema: Some(
+                Schema::new()
+                    .with_table("a", ["id", "x"])
+                    .with_table("b", ["x", "y"]),
+            ),
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "a %>% bind_rows(b)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"X\", NULL AS \"Y\" FROM \"A\" UNION ALL SELECT NULL AS \"ID\", \"X\", \"Y\" FROM \"B\""
+        );
+    }
+    #[test]
+    fn test_bind_cols_duckdb_positional_join() {
+        let sql = transpile_for(Box::new(DuckDbDialect::new()), "a %>% bind_cols(b)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * EXCLUDE (\"__ROW_ID\") \
+             FROM (SELECT *, ROW_NUMBER() OVER () AS \"__ROW_ID\" FROM \"A\") AS \"LHS\" \
+             INNER JOIN (SELECT *, ROW_NUMBER() OVER () AS \"__ROW_ID\" FROM \"B\") AS \"RHS\" \
+             USING (\"__ROW_ID\")"
+        );
+    }
+
+    #[test]
+    fn test_bind_cols_with_pipeline_inputs() {
+        let sql = transpile_for(
+            Box::new(DuckDbDialect::new()),
+            "a %>% select(x) %>% bind_cols(b %>% select(y))",
+        )
+        .unwrap();
+        let normalized = normalize_sql(&sql);
+        assert!(normalized.contains("FROM (SELECT \"X\" FROM \"A\") AS \"A\""));
+        assert!(normalized.contains("FROM (SELECT \"Y\" FROM \"B\") AS \"B\""));
+    }
+
+    #[test]
+    fn test_bind_cols_requires_star_exclude_support() {
+        let result = transpile("a %>% bind_cols(b)");
+        assert!(matches!(
+            result,
+            Err(crate::TranspileError::GenerationError(
+                GenerationError::UnsupportedOperation { .. }
+            ))
+        ));
+    }
+}
+
+// ===== Slice Tests =====
+
+mod slice_tests {
+    use super::*;
+
+    #[test]
+    fn test_slice_max_orders_descending_with_limit() {
+        let sql = transpile("t %>% slice_max(price, n = 3)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"PRICE\" DESC LIMIT 3"
+        );
+    }
+
+    #[test]
+    fn test_slice_max_desc_wrapper_inverts_order() {
+        let sql = transpile("t %>% slice_max(order_by = desc(price), n = 3)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"PRICE\" ASC LIMIT 3"
+        );
+
+        let sql = transpile("t %>% slice_min(order_by = desc(price), n = 3)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"PRICE\" DESC LIMIT 3"
+        );
+    }
+
+    #[test]
+    fn test_operations_after_slice_apply_to_limited_rows() {
+        let sql = transpile("t %>% slice_max(price, n = 3) %>% filter(qty > 1)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM (SELECT * FROM \"T\" ORDER BY \"PRICE\" DESC LIMIT 3) AS \"T\" WHERE (\"QTY\" > 1)"
+        );
+    }
+
+    #[test]
+    fn test_top_n_sign_selects_top_or_bottom_rows() {
+        let sql = transpile("t %>% top_n(5, price)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"PRICE\" DESC LIMIT 5"
+        );
+
+        let sql = transpile("t %>% top_n(-5, price)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"PRICE\" ASC LIMIT 5"
+        );
+
+        let sql = transpile("t %>% top_n(n = 5, wt = desc(price))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"PRICE\" ASC LIMIT 5"
+        );
+    }
+
+    #[test]
+    fn test_head_limits_in_current_order() {
+        for code in [
+            "t %>% arrange(x) %>% head(10)",
+            "t %>% arrange(x) %>% head(n = 10)",
+        ] {
+            let sql = transpile(code).unwrap();
+            assert_eq!(
+                normalize_sql(&sql),
+                "SELECT * FROM \"T\" ORDER BY \"X\" ASC LIMIT 10",
+                "{code}"
+            );
+        }
+
+        // head()는 그룹을 무시한다
+        let sql = transpile("t %>% group_by(g) %>% head(3)").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM \"T\" LIMIT 3");
+    }
+
+    #[test]
+    fn test_top_n_with_two_weight_keys() {
+        let sql = transpile("t %>% top_n(5, wt = c(sales, desc(returns)))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"SALES\" DESC, \"RETURNS\" ASC LIMIT 5"
+        );
+    }
+
+    #[test]
+    fn test_grouped_slice_max_is_rejected() {
+        let result = transpile("t %>% group_by(g) %>% slice_max(price, n = 1)");
+        assert!(matches!(
+            result,
+            Err(crate::TranspileError::GenerationError(
+                GenerationError::UnsupportedOperation { .. }
+            ))
+        ));
+    }
+
+    #[test]
+    fn test_slice_negative_index_drops_first_row() {
+        let sql = transpile("t %>% arrange(x) %>% slice(-1)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" ORDER BY \"X\" ASC OFFSET 1"
+        );
+
+        // OFFSET에 LIMIT이 필요한 dialect
+        let sql = transpile_for(Box::new(SqliteDialect::new()), "t %>% slice(-1)").unwrap();
+        assert!(sql.ends_with("LIMIT -1 OFFSET 1"), "{sql}");
+        let sql = transpile_for(Box::new(MySqlDialect::new()), "t %>% slice(-1)").unwrap();
+        assert!(
+            sql.ends_with("LIMIT 18446744073709551615 OFFSET 1"),
+            "{sql}"
+        );
+        let sql = transpile_for(Box::new(SqlServerDialect::new()), "t %>% slice(-1)").unwrap();
+        assert!(
+            sql.ends_with("ORDER BY (SELECT NULL)\nOFFSET 1 ROWS"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_slice_range_and_later_verbs() {
+        let sql = transpile("t %>% slice(2:4)").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM \"T\" LIMIT 3 OFFSET 1");
+
+        // 이후 동사는 잘린 행 위에서 동작
+        let sql = transpile("t %>% slice(-1) %>% filter(x > 0)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM (SELECT * FROM \"T\" OFFSET 1) AS \"T\" WHERE (\"X\" > 0)"
+        );
+
+        let err = transpile("t %>% group_by(g) %>% slice(-1)").unwrap_err();
+        assert!(err.to_string().contains("grouped slice"), "{err}");
+    }
+}
+
+// ===== Summarise Alias Reference Tests =====
+
+mod summarise_alias_tests {
+    use super::*;
+
+    #[test]
+    fn test_alias_reference_wraps_summary_in_subquery() {
+        let sql = transpile("t %>% summarise(total = sum(x), pct = sum(x) / total)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"TOTAL\", (\"__AGG1\" / \"TOTAL\") AS \"PCT\" \
+             FROM (SELECT SUM(\"X\") AS \"TOTAL\", SUM(\"X\") AS \"__AGG1\" FROM \"T\") AS \"T\""
+        );
+    }
+
+    #[test]
+    fn test_non_missing_counts_use_count_column() {
+        let sql =
+            transpile("t %>% summarise(rows = n(), a = length(x), b = sum(!is.na(x)))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT COUNT(*) AS \"ROWS\", COUNT(\"X\") AS \"A\", COUNT(\"X\") AS \"B\" FROM \"T\""
+        );
+
+        // 다른 부정 조건은 조건부 집계로 남는다
+        let sql = transpile("t %>% summarise(b = sum(!(x > 1)))").unwrap();
+        assert!(
+            sql.contains("COUNT(*) FILTER (WHERE (NOT (\"x\" > 1))) AS \"b\""),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_summarise_after_summarise_nests_first_summary() {
+        let sql =
+            transpile("t %>% group_by(a) %>% summarise(s = sum(x)) %>% summarise(m = max(s))")
+                .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT MAX(\"S\") AS \"M\" \
+             FROM (SELECT \"A\", SUM(\"X\") AS \"S\" FROM \"T\" GROUP BY \"A\") AS \"T\""
+        );
+
+        // drop_last로 남은 그룹이 두 번째 summarise의 GROUP BY가 된다
+        let sql = transpile(
+            "t %>% group_by(a, b) %>% summarise(s = sum(x)) %>% summarise(m = max(s), k = n())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"A\", MAX(\"S\") AS \"M\", COUNT(*) AS \"K\" \
+             FROM (SELECT \"A\", \"B\", SUM(\"X\") AS \"S\" FROM \"T\" GROUP BY \"A\", \"B\") AS \"T\" \
+             GROUP BY \"A\""
+        );
+
+        // 별칭 참조로 이미 중첩된 요약도 한 번 더 감싼다
+        let sql = transpile(
+            "t %>% group_by(g, h) %>% summarise(s = sum(x), r = s * 2) %>% summarise(m = max(r))",
+        )
+        .unwrap();
+        assert!(
+            normalize_sql(&sql).starts_with(
+                "SELECT \"G\", MAX(\"R\") AS \"M\" FROM (SELECT \"G\", \"H\", \"S\", (\"S\" * 2) AS \"R\" FROM (SELECT"
+            ),
+            "{sql}"
+        );
+        assert!(normalize_sql(&sql).ends_with("GROUP BY \"G\""), "{sql}");
+    }
+
+    #[test]
+    fn test_grouped_alias_reference_keeps_keys_and_order() {
+        let sql = transpile(
+            "t %>% group_by(g) %>% summarise(total = sum(x), pct = max(x) / total, n = n())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"G\", \"TOTAL\", (\"__AGG1\" / \"TOTAL\") AS \"PCT\", \"N\" \
+             FROM (SELECT \"G\", SUM(\"X\") AS \"TOTAL\", COUNT(*) AS \"N\", MAX(\"X\") AS \"__AGG1\" \
+             FROM \"T\" GROUP BY \"G\") AS \"T\""
+        );
+    }
+
+    #[test]
+    fn test_chained_alias_references_are_inlined() {
+        let sql =
+            transpile("t %>% summarise(total = sum(x), half = total / 2, quarter = half / 2)")
+                .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"TOTAL\", (\"TOTAL\" / 2) AS \"HALF\", ((\"TOTAL\" / 2) / 2) AS \"QUARTER\" \
+             FROM (SELECT SUM(\"X\") AS \"TOTAL\" FROM \"T\") AS \"T\""
+        );
+    }
+
+    #[test]
+    fn test_aggregate_over_earlier_alias_is_rejected() {
+        // 같은 summarise()의 요약 열은 다시 집계할 수 없음 (축약형과 일반 식 모두)
+        for code in [
+            "t %>% summarise(total = sum(x), avg = mean(total))",
+            "t %>% summarise(total = sum(x), avg = mean(total) + 1)",
+        ] {
+            let err = transpile(code).unwrap_err();
+            assert!(
+                err.to_string().contains("cannot aggregate 'total'"),
+                "{code}: {err}"
+            );
+        }
+    }
+
+    #[test]
+    fn test_split_summary_rejects_ungrouped_columns() {
+        // 바깥 쿼리에는 그룹 열과 요약 열만 남음
+        let err = transpile("t %>% group_by(g) %>% summarise(total = sum(x), pct = x / total)")
+            .unwrap_err();
+        assert!(err.to_string().contains("column 'x'"), "{err}");
+
+        let sql =
+            transpile("t %>% group_by(g) %>% summarise(total = sum(x), pct = g / total)").unwrap();
+        assert!(
+            normalize_sql(&sql)
+                .starts_with("SELECT \"G\", \"TOTAL\", (\"G\" / \"TOTAL\") AS \"PCT\""),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_unsplit_summary_rejects_ungrouped_columns() {
+        // 별칭 참조가 없는 요약에서도 집계 밖의 열은 그룹 열이어야 함
+        for code in [
+            "t %>% group_by(g) %>% summarise(total = sum(x), z = x + 1)",
+            "t %>% group_by(g) %>% summarise(total = sum(x), z = x)",
+            "t %>% summarise(total = sum(x), z = round(x))",
+        ] {
+            let err = transpile(code).unwrap_err();
+            assert!(err.to_string().contains("column 'x'"), "{code}: {err}");
+        }
+
+        let sql = transpile("t %>% group_by(g) %>% summarise(total = sum(x), key = g)").unwrap();
+        assert!(
+            normalize_sql(&sql)
+                .starts_with("SELECT \"G\", SUM(\"X\") AS \"TOTAL\", \"G\" AS \"KEY\""),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_independent_expressions_stay_flat() {
+        let sql = transpile("t %>% summarise(avg = mean(x), spread = max(x) - min(x))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT AVG(\"X\") AS \"AVG\", (MAX(\"X\") - MIN(\"X\")) AS \"SPREAD\" FROM \"T\""
+        );
+    }
+
+    #[test]
+    fn test_aggregate_over_arithmetic() {
+        let sql = transpile("t %>% group_by(g) %>% summarise(revenue = sum(price * qty))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"G\", SUM(\"PRICE\" * \"QTY\") AS \"REVENUE\" FROM \"T\" GROUP BY \"G\""
+        );
+
+        // 윈도우 집계도 같은 인자 형태를 사용
+        let sql = transpile("t %>% group_by(g) %>% mutate(share = price / sum(price - discount))")
+            .unwrap();
+        assert!(
+            normalize_sql(&sql).contains("SUM(\"PRICE\" - \"DISCOUNT\") OVER (PARTITION BY \"G\")"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_conditional_sum_uses_aggregate_filter() {
+        let code = "t %>% group_by(g) %>% summarise(n_big = sum(price > 100))";
+
+        // FILTER 절을 지원하는 방언
+        let sql = transpile(code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"G\", COUNT(*) FILTER (WHERE \"PRICE\" > 100) AS \"N_BIG\" \
+             FROM \"T\" GROUP BY \"G\""
+        );
+        let duckdb = transpile_for(Box::new(DuckDbDialect::new()), code).unwrap();
+        assert_eq!(normalize_sql(&duckdb), normalize_sql(&sql));
+    }
+
+    #[test]
+    fn test_conditional_aggregates_fall_back_to_case() {
+        let code = "t %>% summarise(n_big = sum(price > 100), share = mean(x > 1 & y < 2))";
+
+        for dialect in [
+            Box::new(MySqlDialect::new()) as Box<dyn SqlDialect>,
+            Box::new(SqliteDialect::new()),
+        ] {
+            let sql = transpile_for(dialect, code).unwrap();
+            let normalized = normalize_sql(&sql).replace('`', "\"");
+            assert_eq!(
+                normalized,
+                "SELECT SUM(CASE WHEN \"PRICE\" > 100 THEN 1 ELSE 0 END) AS \"N_BIG\", \
+                 AVG(CASE WHEN (\"X\" > 1) AND (\"Y\" < 2) THEN 1 ELSE 0 END) AS \"SHARE\" \
+                 FROM \"T\""
+            );
+        }
+
+        // mean()은 FILTER 방언에서도 CASE 형태를 사용
+        let sql = transpile("t %>% summarise(share = mean(x > 1))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT AVG(CASE WHEN \"X\" > 1 THEN 1 ELSE 0 END) AS \"SHARE\" FROM \"T\""
+        );
+    }
+
+    #[test]
+    fn test_summarise_groups_drop_ungroups_later_mutate() {
+        let sql = transpile(
+            "t %>% group_by(g, h) %>% summarise(s = sum(x), .groups = \"drop\") %>% mutate(r = row_number(), share = s / sum(s))",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, ROW_NUMBER() OVER () AS \"R\", (\"S\" / SUM(\"S\") OVER ()) AS \"SHARE\" \
+             FROM (SELECT \"G\", \"H\", SUM(\"X\") AS \"S\" FROM \"T\" GROUP BY \"G\", \"H\") AS \"T\""
+        );
+
+        // keep은 모든 그룹을, 기본값(drop_last)은 마지막 그룹만 제외하고 유지
+        let sql = transpile(
+            "t %>% group_by(g, h) %>% summarise(s = sum(x), .groups = \"keep\") %>% mutate(r = row_number())",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (PARTITION BY \"g\", \"h\")"),
+            "{sql}"
+        );
+        let sql = transpile(
+            "t %>% group_by(g, h) %>% summarise(s = sum(x)) %>% mutate(r = row_number())",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (PARTITION BY \"g\")"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_summarise_by_groups_only_that_summary() {
+        let sql = transpile("t %>% summarise(n = n(), .by = region)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", COUNT(*) AS \"N\" FROM \"T\" GROUP BY \"REGION\""
+        );
+
+        // .by의 결과는 그룹이 해제된 상태
+        let sql = transpile(
+            "t %>% summarise(s = sum(x), .by = c(region, year)) %>% mutate(r = row_number())",
+        )
+        .unwrap();
+        assert!(sql.contains("ROW_NUMBER() OVER () AS \"r\""), "{sql}");
+        assert!(sql.contains("GROUP BY \"region\", \"year\""), "{sql}");
+
+        // 이미 그룹화된 데이터에는 .by를 쓸 수 없음
+        assert!(transpile("t %>% group_by(g) %>% summarise(n = n(), .by = region)").is_err());
+    }
+}
+
+// ===== Lint Tests =====
+
+mod lint_tests {
+    use super::*;
+    use crate::Transpiler;
+
+    const NON_PORTABLE: &str = r#"orders %>%
+  full_join(customers, by = "id") %>%
+  filter(name %like% "A%")"#;
+
+    #[test]
+    fn test_lint_flags_mysql_but_not_duckdb() {
+        let warnings = Transpiler::new(Box::new(MySqlDialect::new()))
+            .lint(NON_PORTABLE)
+            .unwrap();
+        assert_eq!(warnings.len(), 2);
+
+        assert_eq!(warnings[0].operation, "join");
+        assert!(warnings[0].message.contains("FULL JOIN"));
+        assert_eq!(warnings[0].location.line, 2);
+
+        assert_eq!(warnings[1].operation, "filter");
+        assert!(warnings[1].message.contains("case-insensitively"));
+        assert_eq!(warnings[1].location.line, 3);
+
+        let warnings = Transpiler::new(Box::new(DuckDbDialect::new()))
+            .lint(NON_PORTABLE)
+            .unwrap();
+        assert!(warnings.is_empty(), "unexpected warnings: {warnings:?}");
+    }
+
+    #[test]
+    fn test_lint_checks_set_operations_and_nested_sources() {
+        let warnings = Transpiler::new(Box::new(MySqlDialect::new()))
+            .lint("a %>% intersect(b) %>% bind_rows(c %>% filter(x %like% \"y\"))")
+            .unwrap();
+        let operations: Vec<_> = warnings.iter().map(|w| w.operation.as_str()).collect();
+        assert_eq!(operations, vec!["intersect", "filter"]);
+    }
+
+    #[test]
+    fn test_lint_returns_transpile_errors() {
+        let result = Transpiler::new(Box::new(PostgreSqlDialect::new())).lint("a %>% bind_cols(b)");
+        assert!(result.is_err());
+    }
+}
+
+// ===== Select Helper Tests =====
+
+mod select_helper_tests {
+    use super::*;
+    use crate::options::{Schema, TranspileOptions};
+
+    fn users_schema() -> Schema {
+        Schema::new().with_table("users", ["name", "id", "age", "email"])
+    }
+
+    #[test]
+    fn test_everything_moves_listed_columns_first() {
+        let sql = transpile_with_schema(Some(users_schema()), "users %>% select(id, everything())")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"NAME\", \"AGE\", \"EMAIL\" FROM \"USERS\""
+        );
+    }
+
+    #[test]
+    fn test_name_match_without_matches_is_rejected() {
+        // 아무 열도 고르지 않으면 SELECT *가 되지 않도록 오류
+        let err = transpile_with_schema(
+            Some(users_schema()),
+            "users %>% select(starts_with(\"zzz\"))",
+        )
+        .unwrap_err();
+        assert!(err.to_string().contains("matches no columns"), "{err}");
+
+        let sql = transpile_with_schema(
+            Some(users_schema()),
+            "users %>% select(id, starts_with(\"zzz\"))",
+        )
+        .unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT \"ID\" FROM \"USERS\"");
+    }
+
+    #[test]
+    fn test_everything_keeps_first_position_of_repeated_columns() {
+        let sql = transpile_with_schema(Some(users_schema()), "users %>% select(everything(), id)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"NAME\", \"ID\", \"AGE\", \"EMAIL\" FROM \"USERS\""
+        );
+    }
+
+    #[test]
+    fn test_everything_includes_mutated_columns() {
+        let sql = transpile_with_schema(
+            Some(users_schema()),
+            "users %>% mutate(age2 = age * 2) %>% select(age2, everything())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT (\"AGE\" * 2) AS \"AGE2\", \"NAME\", \"ID\", \"AGE\", \"EMAIL\" FROM \"USERS\""
+        );
+    }
+
+    #[test]
+    fn test_rename_then_overwrite_keeps_schema_columns_in_place() {
+        // 스키마가 있으면 rename 뒤에도 * 를 펼쳐 열 위치를 유지
+        let options = TranspileOptions {
+            schema: Some(users_schema()),
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(
+            Box::new(DuckDbDialect::new()),
+            options,
+            "users %>% rename(nm = name) %>% mutate(age = age * 2)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"NAME\" AS \"NM\", \"ID\", (\"AGE\" * 2) AS \"AGE\", \"EMAIL\" FROM \"USERS\""
+        );
+    }
+
+    #[test]
+    fn test_mutate_overwrite_keeps_schema_column_in_place() {
+        let sql = transpile_with_schema(
+            Some(users_schema()),
+            "users %>% mutate(age = age + 1, n = 1)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"NAME\", \"ID\", (\"AGE\" + 1) AS \"AGE\", \"EMAIL\", 1 AS \"N\" FROM \"USERS\""
+        );
+
+        // 스키마가 없으면 * 뒤에 같은 이름의 열이 추가됨
+        let sql = transpile_with_schema(None, "users %>% mutate(age = age + 1)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, (\"AGE\" + 1) AS \"AGE\" FROM \"USERS\""
+        );
+    }
+
+    #[test]
+    fn test_everything_without_schema_errors() {
+        let err = transpile_with_schema(None, "users %>% select(id, everything())").unwrap_err();
+        assert!(
+            err.to_string().contains("Schema required"),
+            "unexpected error: {err}"
+        );
+        assert!(
+            err.to_string().contains("everything()"),
+            "unexpected error: {err}"
+        );
+        assert!(err.to_string().contains("users"), "unexpected error: {err}");
+    }
+
+    fn typed_schema() -> Schema {
+        Schema::new().with_typed_table(
+            "sales",
+            [
+                ("id", "INTEGER"),
+                ("region", "VARCHAR(20)"),
+                ("amount", "decimal(10, 2)"),
+                ("active", "BOOLEAN"),
+                ("note", "TEXT"),
+            ],
+        )
+    }
+
+    #[test]
+    fn test_where_selects_columns_by_type() {
+        let sql =
+            transpile_with_schema(Some(typed_schema()), "sales %>% select(where(is.numeric))")
+                .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"AMOUNT\" FROM \"SALES\""
+        );
+
+        let sql = transpile_with_schema(
+            Some(typed_schema()),
+            "sales %>% select(id, where(is.character))",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"REGION\", \"NOTE\" FROM \"SALES\""
+        );
+    }
+
+    #[test]
+    fn test_where_without_types_errors() {
+        let untyped = Schema::new().with_table("sales", ["id", "amount"]);
+        let err = transpile_with_schema(Some(untyped), "sales %>% select(where(is.numeric))")
+            .unwrap_err();
+        assert!(
+            err.to_string().contains("Column type required"),
+            "unexpected error: {err}"
+        );
+        assert!(
+            err.to_string().contains("where(is.numeric)"),
+            "unexpected error: {err}"
+        );
+
+        let err = transpile_with_schema(Some(typed_schema()), "sales %>% select(where(is.blob))")
+            .unwrap_err();
+        assert!(
+            err.to_string().contains("is.blob"),
+            "unexpected error: {err}"
+        );
+    }
+
+    #[test]
+    fn test_where_without_matching_columns_errors() {
+        // 논리형 열이 없으면 SELECT *가 아니라 오류
+        let schema = Schema::new().with_typed_table("sales", [("id", "INTEGER"), ("note", "TEXT")]);
+        let err =
+            transpile_with_schema(Some(schema), "sales %>% select(where(is.logical))").unwrap_err();
+        assert!(
+            err.to_string()
+                .contains("matches no columns (where(is.logical) selects none)"),
+            "unexpected error: {err}"
+        );
+    }
+
+    fn wide_schema() -> Schema {
+        Schema::new().with_table(
+            "wide",
+            ["id", "x1", "x2", "x3", "x10", "y01", "y02", "total"],
+        )
+    }
+
+    #[test]
+    fn test_last_col_resolves_final_column() {
+        let sql =
+            transpile_with_schema(Some(wide_schema()), "wide %>% select(id, last_col())").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"TOTAL\" FROM \"WIDE\""
+        );
+
+        let sql =
+            transpile_with_schema(Some(wide_schema()), "wide %>% select(last_col(offset = 1))")
+                .unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT \"Y02\" FROM \"WIDE\"");
+
+        let err = transpile_with_schema(None, "wide %>% select(last_col())").unwrap_err();
+        assert!(
+            err.to_string().contains("Schema required for 'last_col()'"),
+            "unexpected error: {err}"
+        );
+    }
+
+    #[test]
+    fn test_num_range_expands_numbered_columns() {
+        let sql = transpile_with_schema(
+            Some(wide_schema()),
+            r#"wide %>% select(id, num_range("x", 1:3))"#,
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"X1\", \"X2\", \"X3\" FROM \"WIDE\""
+        );
+
+        // Zero padding via width; numbers without a matching column are skipped.
+        let sql = transpile_with_schema(
+            Some(wide_schema()),
+            r#"wide %>% select(num_range("y", 1:3, width = 2))"#,
+        )
+        .unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT \"Y01\", \"Y02\" FROM \"WIDE\"");
+
+        // 맞는 열이 없으면 SELECT *로 바뀌지 않고 오류
+        let err = transpile_with_schema(
+            Some(wide_schema()),
+            r#"wide %>% select(num_range("z", 1:3))"#,
+        )
+        .unwrap_err();
+        assert!(
+            err.to_string()
+                .contains("matches no columns (num_range() selects none)"),
+            "unexpected error: {err}"
+        );
+
+        let err =
+            transpile_with_schema(None, r#"wide %>% select(num_range("x", 1:3))"#).unwrap_err();
+        assert!(
+            err.to_string()
+                .contains("Schema required for 'num_range()'"),
+            "unexpected error: {err}"
+        );
+    }
+
+    fn events_schema() -> Schema {
+        Schema::new().with_table(
+            "events",
+            ["meta_source", "id", "value", "Meta_Time", "note"],
+        )
+    }
+
+    #[test]
+    fn test_relocate_moves_starts_with_group_after_anchor() {
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% relocate(starts_with(\"meta_\"), .after = id)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"META_SOURCE\", \"META_TIME\", \"VALUE\", \"NOTE\" FROM \"EVENTS\""
+        );
+    }
+
+    #[test]
+    fn test_relocate_defaults_to_front_and_supports_before() {
+        let sql = transpile_with_schema(Some(events_schema()), "events %>% relocate(note, value)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"NOTE\", \"VALUE\", \"META_SOURCE\", \"ID\", \"META_TIME\" FROM \"EVENTS\""
+        );
+
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% relocate(ends_with(\"e\"), .before = id)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"META_SOURCE\", \"VALUE\", \"META_TIME\", \"NOTE\", \"ID\" FROM \"EVENTS\""
+        );
+    }
+
+    #[test]
+    fn test_relocate_after_last_col_moves_to_end() {
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% relocate(id, .after = last_col())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"META_SOURCE\", \"VALUE\", \"META_TIME\", \"NOTE\", \"ID\" FROM \"EVENTS\""
+        );
+
+        // 이미 마지막인 열을 옮기면 순서가 그대로
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% relocate(value, note, .after = last_col())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"META_SOURCE\", \"ID\", \"META_TIME\", \"VALUE\", \"NOTE\" FROM \"EVENTS\""
+        );
+
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% relocate(note, .before = last_col(1))",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"META_SOURCE\", \"ID\", \"VALUE\", \"NOTE\", \"META_TIME\" FROM \"EVENTS\""
+        );
+    }
+
+    #[test]
+    fn test_starts_with_respects_ignore_case_in_select() {
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% select(id, starts_with(\"meta_\", ignore.case = FALSE))",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"META_SOURCE\" FROM \"EVENTS\""
+        );
+    }
+
+    #[test]
+    fn test_helpers_resolve_against_earlier_select() {
+        // 앞선 select()로 빠진 열은 다시 나타나지 않음
+        let sql = transpile_with_schema(
+            Some(users_schema()),
+            "users %>% select(id, name) %>% select(name, everything())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"NAME\", \"ID\" FROM \"USERS\""
+        );
+
+        let sql = transpile_with_schema(
+            Some(wide_schema()),
+            "wide %>% select(id, x1, x2) %>% select(last_col())",
+        )
+        .unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT \"X2\" FROM \"WIDE\"");
+
+        let sql = transpile_with_schema(
+            Some(wide_schema()),
+            r#"wide %>% select(id, x3) %>% select(num_range("x", 1:3))"#,
+        )
+        .unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT \"X3\" FROM \"WIDE\"");
+
+        let sql = transpile_with_schema(
+            Some(typed_schema()),
+            "sales %>% select(region, amount) %>% select(where(is.numeric))",
+        )
+        .unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT \"AMOUNT\" FROM \"SALES\"");
+    }
+
+    #[test]
+    fn test_last_col_follows_earlier_rename() {
+        let sql = transpile_with_schema(
+            Some(wide_schema()),
+            "wide %>% select(id, total) %>% rename(sum = total) %>% select(last_col())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"TOTAL\" AS \"SUM\" FROM \"WIDE\""
+        );
+    }
+
+    #[test]
+    fn test_relocate_after_select_keeps_selected_columns() {
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% select(note, id) %>% relocate(id)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ID\", \"NOTE\" FROM \"EVENTS\""
+        );
+
+        let sql = transpile_with_schema(
+            Some(events_schema()),
+            "events %>% select(id, value, note) %>% relocate(id, .after = last_col())",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"VALUE\", \"NOTE\", \"ID\" FROM \"EVENTS\""
+        );
+    }
+
+    #[test]
+    fn test_relocate_unknown_column_errors() {
+        let err = transpile_with_schema(Some(events_schema()), "events %>% relocate(missing)")
+            .unwrap_err();
+        assert!(
+            err.to_string().contains("missing"),
+            "unexpected error: {err}"
+        );
+    }
+}
+
+// ===== Strict Mode Tests =====
+
+mod strict_mode_tests {
+    use super::*;
+    use crate::options::TranspileOptions;
+
+    /// PostgreSQL with a late-bound hook that passes unknown functions through.
+    #[derive(Debug, Clone)]
+    struct PassThroughDialect(PostgreSqlDialect);
+
+    impl SqlDialect for PassThroughDialect {
+        fn quote_identifier(&self, name: &str) -> String {
+            self.0.quote_identifier(name)
+        }
+
+        fn quote_string(&self, value: &str) -> String {
+            self.0.quote_string(value)
+        }
+
+        fn dialect_name(&self) -> &'static str {
+            "passthrough"
+        }
+
+        fn limit_clause(&self, limit: usize) -> String {
+            self.0.limit_clause(limit)
+        }
+
+        fn string_concat(&self, left: &str, right: &str) -> String {
+            self.0.string_concat(left, right)
+        }
+
+        fn aggregate_function(&self, function: &str) -> String {
+            self.0.aggregate_function(function)
+        }
+
+        fn is_case_sensitive(&self) -> bool {
+            self.0.is_case_sensitive()
+        }
+
+        fn translate_unknown_function(&self, function: &str, args: &[String]) -> Option<String> {
+            Some(format!("{function}({})", args.join(", ")))
+        }
+
+        fn clone_box(&self) -> Box<dyn SqlDialect> {
+            Box::new(self.clone())
+        }
+    }
+
+    fn strict(strict_mode: bool) -> TranspileOptions {
+        TranspileOptions {
+            strict_mode,
+            ..TranspileOptions::default()
+        }
+    }
+
+    fn transpile_passthrough(strict_mode: bool, code: &str) -> Result<String, String> {
+        let dialect = Box::new(PassThroughDialect(PostgreSqlDialect::new()));
+        transpile_with(dialect, strict(strict_mode), code).map_err(|e| e.to_string())
+    }
+
+    #[test]
+    fn test_unknown_function_passes_through_when_lenient() {
+        let sql = transpile_passthrough(false, "users %>% mutate(score = my_udf(age))").unwrap();
+        assert!(sql.contains("my_udf(\"age\") AS \"score\""), "{sql}");
+    }
+
+    #[test]
+    fn test_unknown_function_rejected_in_strict_mode() {
+        let err = transpile_passthrough(true, "users %>% mutate(score = my_udf(age))").unwrap_err();
+        assert!(
+            err.contains("Unsupported function"),
+            "unexpected error: {err}"
+        );
+        assert!(err.contains("my_udf"), "unexpected error: {err}");
+
+        // 알려진 함수와 function_map 항목은 그대로 허용
+        let sql = transpile_passthrough(true, "users %>% mutate(name = toupper(name))").unwrap();
+        assert!(sql.contains("UPPER(\"name\")"), "{sql}");
+        let mut options = TranspileOptions {
+            strict_mode: true,
+            ..TranspileOptions::default()
+        };
+        options
+            .function_map
+            .insert("my_udf".to_string(), "app.my_udf".to_string());
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "users %>% mutate(score = my_udf(age))",
+        )
+        .unwrap();
+        assert!(sql.contains("app.my_udf(\"age\")"), "{sql}");
+    }
+
+    #[test]
+    fn test_builtin_dialects_reject_unknown_functions_in_both_modes() {
+        // 내장 방언은 원래 알 수 없는 함수를 거부하므로 결과가 같음
+        for strict_mode in [false, true] {
+            let dialect = Box::new(PostgreSqlDialect::new());
+            let err = transpile_with(
+                dialect,
+                strict(strict_mode),
+                "users %>% mutate(score = my_udf(age))",
+            )
+            .unwrap_err();
+            assert!(err.to_string().contains("Unsupported function"), "{err}");
+        }
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            strict(true),
+            "users %>% mutate(name = toupper(name), n = n())",
+        )
+        .unwrap();
+        assert!(sql.contains("UPPER(\"name\")"), "{sql}");
+    }
+
+    #[test]
+    fn test_best_effort_operations_rejected_in_strict_mode() {
+        for code in [
+            "a %>% bind_cols(b)",
+            "sales %>% group_by(region) %>% summarise(total = sum(amount)) %>% filter(total > 10)",
+            "data %>% mutate(a = x + 1, b = a * 2)",
+        ] {
+            // bind_cols needs `* EXCLUDE`, so use DuckDB
+            let lenient = transpile_with(Box::new(DuckDbDialect::new()), strict(false), code);
+            assert!(lenient.is_ok(), "{code} should render when lenient");
+            let err =
+                transpile_with(Box::new(DuckDbDialect::new()), strict(true), code).unwrap_err();
+            assert!(
+                err.to_string().contains("strict mode"),
+                "unexpected error for {code}: {err}"
+            );
+        }
+    }
+}
+
+// ===== Staged CTE Tests =====
+
+mod staged_cte_tests {
+    use super::*;
+    use crate::options::TranspileOptions;
+
+    fn transpile_staged(code: &str) -> String {
+        let options = TranspileOptions {
+            staged_cte: true,
+            ..TranspileOptions::default()
+        };
+        transpile_with(Box::new(PostgreSqlDialect::new()), options, code).unwrap()
+    }
+
+    #[test]
+    fn test_select_filter_arrange_chain() {
+        let sql = transpile_staged(
+            "users %>% select(name, age) %>% filter(age > 18) %>% arrange(desc(age))",
+        );
+        assert_eq!(
+            sql,
+            "WITH \"step1\" AS (\nSELECT \"name\", \"age\"\nFROM \"users\"\n),\n\
+             \"step2\" AS (\nSELECT *\nFROM \"step1\"\nWHERE (\"age\" > 18)\n),\n\
+             \"step3\" AS (\nSELECT *\nFROM \"step2\"\n)\n\
+             SELECT *\nFROM \"step3\"\nORDER BY \"age\" DESC"
+        );
+    }
+
+    #[test]
+    fn test_group_by_carries_into_following_stages() {
+        let sql = transpile_staged(
+            "sales %>% group_by(region) %>% filter(amount > 0) %>% summarise(total = sum(amount))",
+        );
+        assert_eq!(
+            normalize_sql(&sql),
+            "WITH \"STEP1\" AS ( SELECT * FROM \"SALES\" WHERE (\"AMOUNT\" > 0) ), \
+             \"STEP2\" AS ( SELECT \"REGION\", SUM(\"AMOUNT\") AS \"TOTAL\" FROM \"STEP1\" GROUP BY \"REGION\" ) \
+             SELECT * FROM \"STEP2\""
+        );
+    }
+
+    #[test]
+    fn test_added_grouping_carries_into_following_stages() {
+        let sql = transpile_staged(
+            "sales %>% group_by(region) %>% filter(amount > 0) %>% group_by(year, .add = TRUE) %>% summarise(total = sum(amount))",
+        );
+        assert!(
+            sql.contains("FROM \"step1\"\nGROUP BY \"region\", \"year\"\n)"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_single_verb_is_its_own_stage() {
+        let sql = transpile_staged("users %>% filter(age > 18)");
+        assert_eq!(
+            sql,
+            "WITH \"step1\" AS (\nSELECT *\nFROM \"users\"\nWHERE (\"age\" > 18)\n)\n\
+             SELECT *\nFROM \"step1\""
+        );
+    }
+
+    #[test]
+    fn test_order_is_carried_into_later_stages() {
+        // 이전 단계의 정렬은 윈도우 함수와 최종 SELECT에 다시 적용됨
+        let sql = transpile_staged("events %>% arrange(ts) %>% mutate(r = row_number())");
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (ORDER BY \"ts\" ASC) AS \"r\"\nFROM \"step1\"\n)"),
+            "{sql}"
+        );
+        assert!(
+            sql.ends_with("FROM \"step2\"\nORDER BY \"ts\" ASC"),
+            "{sql}"
+        );
+
+        let sql =
+            transpile_staged("events %>% group_by(g) %>% arrange(ts) %>% mutate(r = row_number())");
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (PARTITION BY \"g\" ORDER BY \"ts\" ASC)"),
+            "{sql}"
+        );
+
+        let sql = transpile_staged("events %>% arrange(x) %>% filter(y > 1)");
+        assert!(sql.ends_with("FROM \"step2\"\nORDER BY \"x\" ASC"), "{sql}");
+
+        // LIMIT은 같은 단계의 ORDER BY와 함께 유지됨
+        let sql = transpile_staged("events %>% arrange(x) %>% head(5)");
+        assert_eq!(
+            sql,
+            "WITH \"step1\" AS (\nSELECT *\nFROM \"events\"\n),\n\
+             \"step2\" AS (\nSELECT *\nFROM \"step1\"\nORDER BY \"x\" ASC\nLIMIT 5\n)\n\
+             SELECT *\nFROM \"step2\"\nORDER BY \"x\" ASC"
+        );
+    }
+
+    #[test]
+    fn test_order_ends_when_its_keys_are_dropped() {
+        let sql = transpile_staged("events %>% arrange(x) %>% select(y) %>% filter(y > 1)");
+        assert!(!sql.contains("ORDER BY"), "{sql}");
+    }
+
+    #[test]
+    fn test_unlimited_ctes_have_no_order_by_on_sql_server() {
+        let options = TranspileOptions {
+            staged_cte: true,
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(
+            Box::new(SqlServerDialect::new()),
+            options,
+            "events %>% arrange(x) %>% filter(y > 1) %>% mutate(z = y + 1)",
+        )
+        .unwrap();
+        assert_eq!(sql.matches("ORDER BY").count(), 1, "{sql}");
+        assert!(sql.ends_with("FROM [step3]\nORDER BY [x] ASC"), "{sql}");
+    }
+
+    #[test]
+    fn test_limited_last_stage_keeps_its_order() {
+        // 행 제한이 있는 단계는 같은 행을 고르도록 CTE 안에도 정렬을 유지
+        let sql = transpile_staged("users %>% filter(age > 18) %>% slice_max(age, n = 3)");
+        assert!(
+            sql.ends_with(
+                "FROM \"step1\"\nORDER BY \"age\" DESC\nLIMIT 3\n)\n\
+                 SELECT *\nFROM \"step2\"\nORDER BY \"age\" DESC"
+            ),
+            "{sql}"
+        );
+    }
+}
+
+// ===== Comment Preservation Tests =====
+
+mod preserve_comments_tests {
+    use super::*;
+    use crate::options::TranspileOptions;
+
+    fn transpile_preserving(preserve_comments: bool, code: &str) -> String {
+        let options = TranspileOptions {
+            preserve_comments,
+            ..TranspileOptions::default()
+        };
+        transpile_with(Box::new(PostgreSqlDialect::new()), options, code).unwrap()
+    }
+
+    const CODE: &str = "users %>%\n  # adults only\n  filter(age >= 18) %>%\n  # newest first\n  arrange(desc(created_at)) %>%\n  select(name) # just names";
+
+    #[test]
+    fn test_comments_are_emitted_above_their_clause() {
+        assert_eq!(
+            transpile_preserving(true, CODE),
+            "-- just names\nSELECT \"name\"\nFROM \"users\"\n-- adults only\nWHERE (\"age\" >= 18)\n\
+             -- newest first\nORDER BY \"created_at\" DESC"
+        );
+    }
+
+    #[test]
+    fn test_comments_are_dropped_by_default() {
+        assert_eq!(
+            transpile_preserving(false, CODE),
+            "SELECT \"name\"\nFROM \"users\"\nWHERE (\"age\" >= 18)\nORDER BY \"created_at\" DESC"
+        );
+    }
+}
+
+// ===== fill() Tests =====
+
+mod fill_tests {
+    use super::*;
+
+    #[test]
+    fn test_fill_down_in_arranged_pipeline() {
+        let sql = transpile_for(
+            Box::new(DuckDbDialect::new()),
+            "readings %>% arrange(ts) %>% fill(price)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * REPLACE (LAST_VALUE(\"PRICE\" IGNORE NULLS) OVER (ORDER BY \"TS\" ASC \
+             ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS \"PRICE\") \
+             FROM \"READINGS\" ORDER BY \"TS\" ASC"
+        );
+    }
+
+    #[test]
+    fn test_fill_up_partitions_by_group_and_replaces_selected_column() {
+        let sql = transpile_for(Box::new(DuckDbDialect::new()), "readings %>% group_by(sensor) %>% arrange(ts) %>% select(sensor, ts, price) %>% fill(price, .direction = \"up\")")
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"SENSOR\", \"TS\", FIRST_VALUE(\"PRICE\" IGNORE NULLS) OVER (PARTITION BY \"SENSOR\" \
+             ORDER BY \"TS\" ASC ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) AS \"PRICE\" \
+             FROM \"READINGS\" ORDER BY \"TS\" ASC"
+        );
+    }
+
+    #[test]
+    fn test_fill_requires_order_and_ignore_nulls_support() {
+        let err =
+            transpile_for(Box::new(DuckDbDialect::new()), "readings %>% fill(price)").unwrap_err();
+        assert!(
+            err.to_string().contains("arrange()"),
+            "unexpected error: {err}"
+        );
+
+        let err = transpile("readings %>% arrange(ts) %>% fill(price)")
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("fill"), "unexpected error: {err}");
+    }
+}
+
+// ===== distinct() Tests =====
+
+mod distinct_tests {
+    use super::*;
+    use crate::Transpiler;
+
+    #[test]
+    fn test_distinct_after_select_uses_selected_columns() {
+        let sql = transpile("t %>% select(a, b) %>% distinct()").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT DISTINCT \"A\", \"B\" FROM \"T\""
+        );
+
+        // 필터와 정렬은 같은 쿼리에 적용
+        let sql = transpile("t %>% select(a, b) %>% distinct() %>% filter(a > 1) %>% arrange(b)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT DISTINCT \"A\", \"B\" FROM \"T\" WHERE (\"A\" > 1) ORDER BY \"B\" ASC"
+        );
+
+        let sql = transpile("t %>% distinct(a, b)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT DISTINCT \"A\", \"B\" FROM \"T\""
+        );
+    }
+
+    #[test]
+    fn test_projection_change_after_distinct_reads_unique_rows() {
+        // distinct() 뒤의 select()가 중복 제거 범위를 좁히면 안 됨
+        let sql = transpile("t %>% select(a, b) %>% distinct() %>% select(a)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"A\" FROM (SELECT DISTINCT \"A\", \"B\" FROM \"T\") AS \"T\""
+        );
+    }
+
+    #[test]
+    fn test_arrange_after_distinct_requires_projected_column() {
+        // 정렬 키가 DISTINCT 컬럼(또는 별칭)에 있으면 허용
+        let sql =
+            transpile("t %>% mutate(y = x * 2) %>% distinct(a, y) %>% arrange(desc(y))").unwrap();
+        assert!(sql.ends_with("ORDER BY \"y\" DESC"), "{sql}");
+        let sql = transpile("t %>% distinct() %>% arrange(x)").unwrap();
+        assert!(sql.ends_with("ORDER BY \"x\" ASC"), "{sql}");
+
+        let code = "t %>% distinct(a, b) %>% arrange(x)";
+        for dialect in [
+            Box::new(PostgreSqlDialect::new()) as Box<dyn SqlDialect>,
+            Box::new(MySqlDialect::new()),
+            Box::new(DuckDbDialect::new()),
+        ] {
+            let err = transpile_for(dialect, code).unwrap_err();
+            assert!(
+                err.to_string()
+                    .contains("arrange() column 'x' is not part of the distinct() columns"),
+                "{err}"
+            );
+        }
+
+        // SQLite는 선택되지 않은 컬럼으로도 정렬 가능
+        let sql = transpile_for(Box::new(SqliteDialect::new()), code).unwrap();
+        assert!(sql.ends_with("ORDER BY \"x\" ASC"), "{sql}");
+    }
+
+    #[test]
+    fn test_distinct_drops_earlier_arrange_on_dropped_columns() {
+        // DISTINCT가 빼는 열로 정렬하면 유효하지 않으므로 정렬을 버림
+        let sql = transpile("t %>% arrange(b) %>% distinct(a)").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT DISTINCT \"A\" FROM \"T\"");
+        let sql = transpile("t %>% arrange(b) %>% select(a) %>% distinct()").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT DISTINCT \"A\" FROM \"T\"");
+
+        // 정렬 키가 남아 있으면 그대로
+        let sql = transpile("t %>% arrange(desc(a)) %>% distinct(a, b)").unwrap();
+        assert!(sql.ends_with("ORDER BY \"a\" DESC"), "{sql}");
+
+        // SQLite는 선택되지 않은 컬럼으로도 정렬 가능
+        let sql = transpile_for(
+            Box::new(SqliteDialect::new()),
+            "t %>% arrange(b) %>% distinct(a)",
+        )
+        .unwrap();
+        assert!(sql.ends_with("ORDER BY \"b\" ASC"), "{sql}");
+    }
+
+    #[test]
+    fn test_distinct_keep_all_numbers_rows_on_mysql() {
+        let schema = crate::Schema::new().with_table("events", ["key", "ts", "value"]);
+        let transpiler = Transpiler::with_options(
+            Box::new(MySqlDialect::new()),
+            crate::TranspileOptions {
+                schema: Some(schema),
+                ..crate::TranspileOptions::default()
+            },
+        );
+        let sql = transpiler
+            .transpile("events %>% distinct(key, .keep_all = TRUE)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT `KEY`, `TS`, `VALUE` FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY `KEY`) \
+             AS `__DISTINCT_ROW` FROM `EVENTS`) AS `EVENTS` WHERE `__DISTINCT_ROW` = 1"
+        );
+
+        // 앞선 arrange()가 키마다 남길 첫 행을 정한다
+        let sql = transpiler
+            .transpile("events %>% arrange(desc(ts)) %>% distinct(key, .keep_all = TRUE)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT `KEY`, `TS`, `VALUE` FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY `KEY` \
+             ORDER BY `TS` DESC) AS `__DISTINCT_ROW` FROM `EVENTS`) AS `EVENTS` \
+             WHERE `__DISTINCT_ROW` = 1 ORDER BY `TS` DESC"
+        );
+
+        // 스키마 없이는 보조 열을 뺀 컬럼 목록을 만들 수 없다
+        let err = transpile_for(
+            Box::new(MySqlDialect::new()),
+            "events %>% distinct(key, .keep_all = TRUE)",
+        )
+        .unwrap_err();
+        assert!(
+            err.to_string().contains("distinct(.keep_all = TRUE)"),
+            "{err}"
+        );
+    }
+
+    #[test]
+    fn test_distinct_keep_all_uses_distinct_on_for_latest_per_key() {
+        let transpiler = Transpiler::new(Box::new(DuckDbDialect::new()));
+        let sql = transpiler
+            .transpile("events %>% filter(value > 0) %>% distinct(key, .keep_all = TRUE)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT DISTINCT ON (\"KEY\") * FROM \"EVENTS\" WHERE (\"VALUE\" > 0)"
+        );
+
+        // 키별 최신 행: 정렬은 키가 먼저, arrange()가 distinct() 앞이든 뒤든 같음
+        let expected =
+            "SELECT DISTINCT ON (\"KEY\") * FROM \"EVENTS\" ORDER BY \"KEY\", \"TS\" DESC";
+        for code in [
+            "events %>% arrange(desc(ts)) %>% distinct(key, .keep_all = TRUE)",
+            "events %>% distinct(key, .keep_all = TRUE) %>% arrange(desc(ts))",
+        ] {
+            assert_eq!(
+                normalize_sql(&transpiler.transpile(code).unwrap()),
+                expected
+            );
+        }
+        let sql =
+            transpile("events %>% distinct(key, .keep_all = TRUE) %>% arrange(desc(ts))").unwrap();
+        assert_eq!(normalize_sql(&sql), expected);
+
+        // 이후 filter는 중복 제거된 행에 적용
+        let sql = transpiler
+            .transpile("events %>% distinct(key, .keep_all = TRUE) %>% filter(value > 0)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM (SELECT DISTINCT ON (\"KEY\") * FROM \"EVENTS\") AS \"EVENTS\" \
+             WHERE (\"VALUE\" > 0)"
+        );
+    }
+}
+
+// ===== filter_at() / filter_all() Tests =====
+
+mod scoped_filter_tests {
+    use super::*;
+    use crate::options::Schema;
+
+    #[test]
+    fn test_filter_at_all_vars_joins_columns_with_and() {
+        let sql =
+            transpile_with_schema(None, "t %>% filter_at(vars(a, b), all_vars(. > 0))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" WHERE ((\"A\" > 0) AND (\"B\" > 0))"
+        );
+
+        let sql = transpile_with_schema(
+            None,
+            "t %>% filter_at(vars(a, b), any_vars(is.na(.))) %>% filter(id > 1)",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("WHERE ((\"a\" IS NULL) OR (\"b\" IS NULL)) AND (\"id\" > 1)"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_filter_all_expands_schema_columns() {
+        let schema = Schema::new().with_table("t", ["x", "y"]);
+        let sql =
+            transpile_with_schema(Some(schema), "t %>% filter_all(any_vars(. == 1))").unwrap();
+        assert!(sql.contains("WHERE ((\"x\" = 1) OR (\"y\" = 1))"), "{sql}");
+
+        // 스키마 없이는 열 목록을 알 수 없음
+        let err = transpile_with_schema(None, "t %>% filter_all(any_vars(. == 1))").unwrap_err();
+        assert!(err.to_string().contains("Schema required"), "{err}");
+        // 오류 메시지는 사용자가 쓴 동사를 가리킴
+        assert!(err.to_string().contains("filter_all()"), "{err}");
+        assert!(!err.to_string().contains("if_any"), "{err}");
+    }
+
+    #[test]
+    fn test_filter_all_uses_columns_left_by_select() {
+        let schema = Schema::new().with_table("t", ["x", "y", "z"]);
+        let sql = transpile_with_schema(
+            Some(schema),
+            "t %>% select(x, z) %>% filter_all(any_vars(. == 1))",
+        )
+        .unwrap();
+        assert!(sql.contains("WHERE ((\"x\" = 1) OR (\"z\" = 1))"), "{sql}");
+    }
+}
+
+// ===== count() Tests =====
+
+mod count_tests {
+    use super::*;
+
+    #[test]
+    fn test_count_sort_orders_by_count_descending() {
+        let sql = transpile("sales %>% count(region, sort = TRUE)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", COUNT(*) AS \"N\" FROM \"SALES\" GROUP BY \"REGION\" ORDER BY \"N\" DESC"
+        );
+
+        let sql = transpile("sales %>% count(region)").unwrap();
+        assert!(!sql.contains("ORDER BY"), "{sql}");
+    }
+
+    #[test]
+    fn test_weighted_count_sums_weight() {
+        let sql = transpile("sales %>% count(region, wt = sales)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", SUM(\"SALES\") AS \"N\" FROM \"SALES\" GROUP BY \"REGION\""
+        );
+
+        // 식 가중치와 name/sort 조합
+        let sql =
+            transpile("sales %>% count(region, wt = price * qty, name = \"revenue\", sort = TRUE)")
+                .unwrap();
+        assert!(
+            sql.contains("SUM(\"price\" * \"qty\") AS \"revenue\""),
+            "{sql}"
+        );
+        assert!(sql.ends_with("ORDER BY \"revenue\" DESC"), "{sql}");
+    }
+
+    #[test]
+    fn test_explicit_arrange_overrides_count_sort() {
+        let sql = transpile("sales %>% count(region, sort = TRUE) %>% arrange(n)").unwrap();
+        assert!(sql.ends_with("ORDER BY \"n\" ASC"), "{sql}");
+        assert!(!sql.contains("DESC"), "{sql}");
+
+        let sql = transpile("sales %>% count(region, sort = TRUE) %>% arrange(region)").unwrap();
+        assert!(sql.ends_with("ORDER BY \"region\" ASC"), "{sql}");
+    }
+
+    #[test]
+    fn test_arrange_by_count_alias() {
+        let sql = transpile("sales %>% count(region) %>% arrange(desc(n))").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"REGION\", COUNT(*) AS \"N\" FROM \"SALES\" GROUP BY \"REGION\" ORDER BY \"N\" DESC"
+        );
+
+        // 이후 mutate의 윈도우와 결과 행도 같은 정렬을 따름
+        let sql =
+            transpile("sales %>% count(region) %>% arrange(desc(n)) %>% mutate(r = row_number())")
+                .unwrap();
+        assert!(
+            sql.contains("ROW_NUMBER() OVER (ORDER BY \"n\" DESC) AS \"r\""),
+            "{sql}"
+        );
+        assert!(
+            sql.ends_with(") AS \"sales\"\nORDER BY \"n\" DESC"),
+            "{sql}"
+        );
+    }
+
+    #[test]
+    fn test_arrange_by_default_alias_after_named_count_is_rejected() {
+        // name = "cnt"로 바꾼 뒤에는 `n` 열이 없음
+        let err = transpile("sales %>% count(region, name = \"cnt\") %>% arrange(desc(n))")
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("Invalid column reference: 'n'"), "{err}");
+
+        let sql =
+            transpile("sales %>% count(region, name = \"cnt\") %>% arrange(desc(cnt))").unwrap();
+        assert!(sql.ends_with("ORDER BY \"cnt\" DESC"), "{sql}");
+        let sql = transpile("sales %>% count(region) %>% arrange(region)").unwrap();
+        assert!(sql.ends_with("ORDER BY \"region\" ASC"), "{sql}");
+    }
+
+    #[test]
+    fn test_count_adds_columns_to_current_groups() {
+        let sql =
+            transpile("sales %>% group_by(year) %>% count(region, name = \"orders\")").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"YEAR\", \"REGION\", COUNT(*) AS \"ORDERS\" FROM \"SALES\" GROUP BY \"YEAR\", \"REGION\""
+        );
+    }
+}
+
+// ===== tribble() Tests =====
+
+mod tribble_tests {
+    use super::*;
+
+    #[test]
+    fn test_two_column_tribble_is_an_inline_source() {
+        let sql = transpile_for(
+            Box::new(DuckDbDialect::new()),
+            "tribble(~k, ~v, \"a\", 1, \"b\", 2) %>% filter(v > 1)",
+        )
+        .unwrap();
+        assert_eq!(
+            sql,
+            "SELECT *\nFROM (SELECT 'a' AS \"k\", 1 AS \"v\"\nUNION ALL\nSELECT 'b', 2) AS \"data\"\nWHERE (\"v\" > 1)"
+        );
+    }
+
+    #[test]
+    fn test_tribble_alone_renders_the_rows() {
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            "tribble(~k, ~flag, \"a\", TRUE, \"b\", NA)",
+        )
+        .unwrap();
+        assert_eq!(
+            sql,
+            "SELECT 'a' AS `k`, TRUE AS `flag`\nUNION ALL\nSELECT 'b', NULL"
+        );
+    }
+}
+
+// ===== rename_with() Tests =====
+
+mod rename_with_tests {
+    use super::*;
+    use crate::options::Schema;
+    use crate::Transpiler;
+
+    #[test]
+    fn test_rename_with_uppercases_known_columns() {
+        // 스키마로 열 목록을 알면 everything()을 펼친다
+        let options = TranspileOptions {
+            schema: Some(Schema::new().with_table("users", ["id", "name"])),
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(
+            Box::new(DuckDbDialect::new()),
+            options.clone(),
+            "users %>% rename_with(toupper)",
+        )
+        .unwrap();
+        assert_eq!(
+            sql,
+            "SELECT \"id\" AS \"ID\", \"name\" AS \"NAME\"\nFROM \"users\""
+        );
+
+        // * EXCLUDE가 없는 방언에서도 같은 투영으로 펼친다
+        for dialect in [
+            Box::new(PostgreSqlDialect::new()) as Box<dyn SqlDialect>,
+            Box::new(MySqlDialect::new()),
+            Box::new(SqliteDialect::new()),
+        ] {
+            let sql = transpile_with(
+                dialect,
+                options.clone(),
+                "users %>% rename_with(tolower, name) %>% rename_with(toupper, id)",
+            )
+            .unwrap();
+            assert_eq!(
+                normalize_sql(&sql.replace('`', "\"")),
+                normalize_sql("SELECT \"id\" AS \"ID\", \"name\" FROM \"users\""),
+                "{sql}"
+            );
+        }
+
+        // 명시적으로 선택된 열은 제자리에서 이름만 바뀐다
+        let sql =
+            transpile("users %>% select(id, name, age) %>% rename_with(toupper, c(id, name))")
+                .unwrap();
+        assert_eq!(
+            sql,
+            "SELECT \"id\" AS \"ID\", \"name\" AS \"NAME\", \"age\"\nFROM \"users\""
+        );
+    }
+
+    #[test]
+    fn test_rename_with_after_select_renames_remaining_columns() {
+        let options = TranspileOptions {
+            schema: Some(Schema::new().with_table("users", ["id", "name", "price"])),
+            ..TranspileOptions::default()
+        };
+        let sql = transpile_with(
+            Box::new(PostgreSqlDialect::new()),
+            options,
+            "users %>% select(id, name) %>% rename_with(toupper)",
+        )
+        .unwrap();
+        assert_eq!(
+            sql,
+            "SELECT \"id\" AS \"ID\", \"name\" AS \"NAME\"\nFROM \"users\""
+        );
+    }
+
+    #[test]
+    fn test_rename_with_formula_and_errors() {
+        let transpiler = Transpiler::new(Box::new(PostgreSqlDialect::new()));
+        let sql = transpiler
+            .transpile("t %>% select(a, b) %>% rename_with(~ paste0(.x, \"_old\"), b)")
+            .unwrap();
+        assert!(sql.starts_with("SELECT \"a\", \"b\" AS \"b_old\""), "{sql}");
+
+        let err = transpiler
+            .transpile("t %>% rename_with(~ substr(.x, 1, 2), a)")
+            .unwrap_err();
+        assert!(err.to_string().contains("substr()"), "{err}");
+
+        // 스키마가 없으면 선택 도우미를 풀 수 없다
+        let err = transpiler
+            .transpile("t %>% rename_with(toupper)")
+            .unwrap_err();
+        assert!(err.to_string().contains("rename_with()"), "{err}");
+    }
+}
+
+// ===== slice_sample() Tests =====
+
+mod slice_sample_tests {
+    use super::*;
+
+    fn transpile_normalized(dialect: Box<dyn SqlDialect>, code: &str) -> String {
+        normalize_sql(&transpile_for(dialect, code).unwrap())
+    }
+
+    #[test]
+    fn test_slice_sample_n() {
+        let code = "t %>% filter(x > 1) %>% slice_sample(n = 5)";
+        assert_eq!(
+            transpile_normalized(Box::new(PostgreSqlDialect::new()), code),
+            "SELECT * FROM \"T\" WHERE (\"X\" > 1) ORDER BY RANDOM() LIMIT 5"
+        );
+        assert_eq!(
+            transpile_normalized(Box::new(MySqlDialect::new()), code),
+            "SELECT * FROM `T` WHERE (`X` > 1) ORDER BY RAND() LIMIT 5"
+        );
+        // DuckDB는 지금까지의 결과에 USING SAMPLE을 적용
+        assert_eq!(
+            transpile_normalized(Box::new(DuckDbDialect::new()), code),
+            "SELECT * FROM (SELECT * FROM \"T\" WHERE (\"X\" > 1)) AS \"T\" USING SAMPLE 5 ROWS"
+        );
+    }
+
+    #[test]
+    fn test_slice_sample_prop() {
+        let code = "t %>% slice_sample(prop = 0.1)";
+        assert_eq!(
+            transpile_normalized(Box::new(PostgreSqlDialect::new()), code),
+            "SELECT * FROM \"T\" WHERE RANDOM() < 0.1"
+        );
+        assert_eq!(
+            transpile_normalized(Box::new(DuckDbDialect::new()), code),
+            "SELECT * FROM \"T\" USING SAMPLE 10% (BERNOULLI)"
+        );
+
+        // 근사 비율 표본은 strict 모드에서 거부
+        let options = crate::TranspileOptions {
+            strict_mode: true,
+            ..crate::TranspileOptions::default()
+        };
+        let err = transpile_with(Box::new(PostgreSqlDialect::new()), options, code).unwrap_err();
+        assert!(err.to_string().contains("slice_sample"), "{err}");
+    }
+
+    #[test]
+    fn test_slice_sample_weighted_and_grouped() {
+        assert_eq!(
+            transpile_normalized(
+                Box::new(DuckDbDialect::new()),
+                "t %>% slice_sample(n = 3, weight_by = w)"
+            ),
+            "SELECT * FROM \"T\" ORDER BY POWER(RANDOM(), 1.0 / \"W\") DESC LIMIT 3"
+        );
+        assert!(transpile("t %>% group_by(g) %>% slice_sample(n = 1)").is_err());
+    }
+
+    #[test]
+    fn test_seed_makes_sample_repeatable() {
+        fn seeded(
+            dialect: Box<dyn SqlDialect>,
+            code: &str,
+        ) -> Result<String, crate::TranspileError> {
+            let options = crate::TranspileOptions {
+                seed: Some(42),
+                ..crate::TranspileOptions::default()
+            };
+            transpile_with(dialect, options, code)
+        }
+
+        let sql = seeded(Box::new(DuckDbDialect::new()), "t %>% slice_sample(n = 5)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" USING SAMPLE 5 ROWS (RESERVOIR, 42)"
+        );
+        let sql = seeded(
+            Box::new(DuckDbDialect::new()),
+            "t %>% slice_sample(prop = 0.1)",
+        )
+        .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM \"T\" USING SAMPLE 10% (BERNOULLI, 42)"
+        );
+
+        // MySQL은 시드를 받은 RAND()로 정렬
+        let sql = seeded(Box::new(MySqlDialect::new()), "t %>% slice_sample(n = 5)").unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM `T` ORDER BY RAND(42) LIMIT 5"
+        );
+
+        // 쿼리 안에서 시드를 줄 수 없는 방언은 거부
+        let err = seeded(
+            Box::new(PostgreSqlDialect::new()),
+            "t %>% slice_sample(n = 5)",
+        )
+        .unwrap_err();
+        assert!(err.to_string().contains("seeded slice_sample"), "{err}");
+    }
+}
+
+// ===== SQL Server Limit Tests =====
+
+mod sqlserver_limit_tests {
+    use super::*;
+
+    fn transpile_sqlserver(code: &str) -> String {
+        transpile_for(Box::new(SqlServerDialect::new()), code).unwrap()
+    }
+
+    #[test]
+    fn test_head_renders_offset_fetch_after_order_by() {
+        let sql = transpile_sqlserver("t %>% arrange(desc(x)) %>% head(10)");
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM [T] ORDER BY [X] DESC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"
+        );
+    }
+
+    #[test]
+    fn test_head_without_arrange_synthesizes_order_by() {
+        // T-SQL은 ORDER BY 없는 OFFSET/FETCH를 거부하므로 임의 순서를 요청
+        let sql = transpile_sqlserver("t %>% filter(x > 1) %>% head(10)");
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM [T] WHERE ([X] > 1) \
+             ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"
+        );
+
+        // LIMIT이 없으면 ORDER BY도 추가하지 않음
+        let sql = transpile_sqlserver("t %>% filter(x > 1)");
+        assert!(!sql.contains("ORDER BY"), "{sql}");
+    }
+
+    #[test]
+    fn test_booleans_render_as_bit_values() {
+        // T-SQL에는 TRUE/FALSE 키워드가 없음
+        let sql = transpile_sqlserver("t %>% filter(FALSE)");
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM [T] WHERE 1 = 0");
+
+        let sql = transpile_sqlserver("t %>% mutate(b = TRUE, c = FALSE)");
+        assert_eq!(normalize_sql(&sql), "SELECT *, 1 AS [B], 0 AS [C] FROM [T]");
+
+        let sql = transpile_sqlserver("t %>% filter(flag == TRUE)");
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM [T] WHERE ([FLAG] = 1)");
+    }
+
+    #[test]
+    fn test_bare_logical_columns_become_predicates() {
+        // WHERE [is_active]는 T-SQL에서 거부되므로 BIT 값을 1과 비교
+        let sql = transpile_sqlserver("t %>% filter(is_active)");
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM [T] WHERE ([IS_ACTIVE] = 1)"
+        );
+
+        // 부정과 논리 결합 안의 피연산자도 변환, 비교식은 그대로
+        let sql = transpile_sqlserver("t %>% filter(!is_active & x > 1 | is.na(y))");
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM [T] WHERE (((NOT ([IS_ACTIVE] = 1)) AND ([X] > 1)) OR ([Y] IS NULL))"
+        );
+    }
+
+    #[test]
+    fn test_distinct_rows_are_paged_from_a_subquery() {
+        // SELECT DISTINCT의 ORDER BY는 선택된 열만 허용하므로 (SELECT NULL)은 바깥 쿼리에 둠
+        let sql = transpile_sqlserver("t %>% distinct(a) %>% head(5)");
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT * FROM (SELECT DISTINCT [A] FROM [T]) AS [T] \
+             ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY"
+        );
+        let sql = transpile_sqlserver("t %>% distinct() %>% slice(3:5)");
+        assert!(
+            sql.contains("FROM (SELECT DISTINCT *\nFROM [t]) AS [t]"),
+            "{sql}"
+        );
+
+        // 정렬 키가 있으면 같은 쿼리에서 페이지 처리
+        let sql = transpile_sqlserver("t %>% distinct(a) %>% arrange(a) %>% head(5)");
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT DISTINCT [A] FROM [T] ORDER BY [A] ASC OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY"
+        );
+    }
+
+    #[test]
+    fn test_explain_is_rejected() {
+        // 실행 계획은 SET SHOWPLAN_XML로 요청하므로 쿼리 접두사가 없음
+        let options = crate::TranspileOptions {
+            explain: true,
+            ..crate::TranspileOptions::default()
+        };
+        let err = transpile_with(Box::new(SqlServerDialect::new()), options, "t %>% head(10)")
+            .unwrap_err();
+        assert!(
+            matches!(
+                err,
+                crate::TranspileError::GenerationError(GenerationError::UnsupportedOperation {
+                    ref dialect,
+                    ..
+                }) if dialect == "sqlserver"
+            ),
+            "{err}"
+        );
+    }
+
+    #[test]
+    fn test_nulls_ordering_uses_case_key() {
+        for (nulls, key) in [
+            (
+                crate::NullsOrdering::Last,
+                "CASE WHEN [a] IS NULL THEN 1 ELSE 0 END, [a] ASC",
+            ),
+            (
+                crate::NullsOrdering::First,
+                "CASE WHEN [a] IS NULL THEN 0 ELSE 1 END, [a] ASC",
+            ),
+        ] {
+            let options = crate::TranspileOptions {
+                nulls_ordering: Some(nulls),
+                ..crate::TranspileOptions::default()
+            };
+            let sql = transpile_with(
+                Box::new(SqlServerDialect::new()),
+                options,
+                "t %>% arrange(a)",
+            )
+            .unwrap();
+            assert!(sql.ends_with(&format!("ORDER BY {key}")), "{sql}");
+        }
+    }
+
+    #[test]
+    fn test_other_dialects_keep_limit() {
+        let sql = transpile("t %>% head(10)").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM \"T\" LIMIT 10");
+    }
+}
+
+// ===== Debug Info Tests =====
+
+mod debug_info_tests {
+    use super::*;
+    use crate::Transpiler;
+
+    #[test]
+    fn test_debug_info_for_grouped_query() {
+        let transpiler = Transpiler::new(Box::new(PostgreSqlDialect::new()));
+        let code = "sales %>% filter(amount > 0) %>% group_by(region, year) %>% \
+                    summarise(total = sum(amount)) %>% arrange(desc(total))";
+        let (sql, info) = transpiler.transpile_debug(code).unwrap();
+
+        assert_eq!(sql, transpiler.transpile(code).unwrap());
+        assert_eq!(info.pipeline, transpiler.parse_dplyr(code).unwrap());
+        assert_eq!(info.source_table, "sales");
+        // summarise()는 마지막 그룹 키를 해제
+        assert_eq!(info.group_columns, ["region"]);
+        assert_eq!(
+            info.clauses,
+            ClauseFragments {
+                select: "\"region\", \"year\", SUM(\"amount\") AS \"total\"".to_string(),
+                from: "\"sales\"".to_string(),
+                joins: vec![],
+                where_conditions: vec!["(\"amount\" > 0)".to_string()],
+                group_by: "\"region\", \"year\"".to_string(),
+                order_by: "\"total\" DESC".to_string(),
+                limit: None,
+                set_operation: None,
+            }
+        );
+    }
+}
+
+// ===== Grouping Sets Tests =====
+
+mod grouping_sets_tests {
+    use super::*;
+
+    #[test]
+    fn test_rollup_grouping() {
+        let code = "sales %>% group_by(rollup(region, year)) %>% summarise(total = sum(amount))";
+        for dialect in [
+            Box::new(PostgreSqlDialect::new()) as Box<dyn SqlDialect>,
+            Box::new(DuckDbDialect::new()),
+        ] {
+            let sql = transpile_for(dialect, code).unwrap();
+            assert_eq!(
+                normalize_sql(&sql),
+                "SELECT \"REGION\", \"YEAR\", SUM(\"AMOUNT\") AS \"TOTAL\" \
+                 FROM \"SALES\" GROUP BY ROLLUP (\"REGION\", \"YEAR\")"
+            );
+        }
+
+        // MySQL은 WITH ROLLUP 수식어만 지원
+        let sql = transpile_for(Box::new(MySqlDialect::new()), code).unwrap();
+        assert!(
+            sql.ends_with("GROUP BY `region`, `year` WITH ROLLUP"),
+            "{sql}"
+        );
+        assert!(transpile_for(Box::new(SqliteDialect::new()), code).is_err());
+    }
+
+    #[test]
+    fn test_cube_and_grouping_sets() {
+        let sql = transpile_for(
+            Box::new(PostgreSqlDialect::new()),
+            "t %>% group_by(cube(a, b)) %>% summarise(n = n())",
+        )
+        .unwrap();
+        assert!(sql.ends_with("GROUP BY CUBE (\"a\", \"b\")"), "{sql}");
+
+        let sql = transpile_for(
+            Box::new(PostgreSqlDialect::new()),
+            "t %>% group_by(groupingsets(c(a, b), a, c())) %>% summarise(n = n())",
+        )
+        .unwrap();
+        assert!(
+            sql.ends_with("GROUP BY GROUPING SETS ((\"a\", \"b\"), (\"a\"), ())"),
+            "{sql}"
+        );
+
+        // summarise() 뒤에 남는 그룹은 일반 그룹
+        let sql = transpile_for(
+            Box::new(PostgreSqlDialect::new()),
+            "t %>% group_by(rollup(a, b)) %>% summarise(n = n()) %>% mutate(share = n / sum(n))",
+        )
+        .unwrap();
+        assert!(sql.contains("GROUP BY ROLLUP (\"a\", \"b\")"), "{sql}");
+        assert!(
+            sql.contains("SUM(\"n\") OVER (PARTITION BY \"a\")"),
+            "{sql}"
+        );
+    }
+}
+
+// ===== Date Function Tests =====
+
+mod date_function_tests {
+    use super::*;
+    use crate::options::TranspileOptions;
+
+    fn transpile_in_zone(
+        dialect: Box<dyn SqlDialect>,
+        code: &str,
+    ) -> Result<String, crate::TranspileError> {
+        transpile_with(
+            dialect,
+            TranspileOptions {
+                time_zone: Some("UTC".to_string()),
+                ..Default::default()
+            },
+            code,
+        )
+    }
+
+    #[test]
+    fn test_date_functions_without_time_zone() {
+        let sql = transpile(r#"data %>% mutate(y = year(ts), d = floor_date(ts, "day"))"#).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT *, EXTRACT(YEAR FROM \"TS\") AS \"Y\", DATE_TRUNC('DAY', \"TS\") AS \"D\" FROM \"DATA\""
+        );
+
+        let sql = transpile_for(
+            Box::new(SqliteDialect::new()),
+            "data %>% mutate(m = month(ts))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("CAST(STRFTIME('%m', \"ts\") AS INTEGER) AS \"m\""),
+            "{sql}"
+        );
+
+        // 지원하지 않는 단위는 오류
+        let err = transpile(r#"data %>% mutate(d = floor_date(ts, "fortnight"))"#).unwrap_err();
+        assert!(err.to_string().contains("floor_date()"), "{err}");
+    }
+
+    #[test]
+    fn test_time_zone_qualifies_extraction() {
+        let sql = transpile_in_zone(
+            Box::new(DuckDbDialect::new()),
+            r#"data %>% mutate(d = floor_date(ts, "day"))"#,
+        )
+        .unwrap();
+        assert!(
+            sql.contains("DATE_TRUNC('day', \"ts\" AT TIME ZONE 'UTC') AS \"d\""),
+            "{sql}"
+        );
+
+        let sql = transpile_in_zone(
+            Box::new(PostgreSqlDialect::new()),
+            "data %>% filter(hour(ts) >= 9)",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("(EXTRACT(HOUR FROM \"ts\" AT TIME ZONE 'UTC') >= 9)"),
+            "{sql}"
+        );
+
+        let sql = transpile_in_zone(
+            Box::new(MySqlDialect::new()),
+            "data %>% mutate(y = year(ts))",
+        )
+        .unwrap();
+        assert!(
+            sql.contains("EXTRACT(YEAR FROM CONVERT_TZ(`ts`, @@session.time_zone, 'UTC'))"),
+            "{sql}"
+        );
+
+        // SQLite는 시간대 변환이 없다
+        let err = transpile_in_zone(
+            Box::new(SqliteDialect::new()),
+            "data %>% mutate(y = year(ts))",
+        )
+        .unwrap_err();
+        assert!(err.to_string().contains("time_zone"), "{err}");
+    }
+}
+
+// ===== Trailing Pipe Tests =====
+
+mod trailing_pipe_tests {
+    use super::*;
+    use crate::options::TranspileOptions;
+    use crate::Transpiler;
+
+    #[test]
+    fn test_allow_trailing_pipe_option() {
+        let code = "my_table %>% select(a) %>%";
+
+        let err = transpile(code).unwrap_err();
+        assert!(matches!(err, crate::TranspileError::ParseError(_)), "{err}");
+
+        let transpiler = Transpiler::with_options(
+            Box::new(PostgreSqlDialect::new()),
+            TranspileOptions {
+                allow_trailing_pipe: true,
+                ..Default::default()
+            },
+        );
+        let sql = transpiler.transpile(code).unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT \"A\" FROM \"MY_TABLE\"");
+
+        // 동사 없이 테이블 뒤에 파이프만 남은 경우
+        let sql = transpiler.transpile("my_table %>%\n").unwrap();
+        assert_eq!(normalize_sql(&sql), "SELECT * FROM \"MY_TABLE\"");
+    }
+}
+
+// ===== Qualified Column Tests =====
+
+mod qualified_column_tests {
+    use super::*;
+    use crate::Transpiler;
+
+    #[test]
+    fn test_qualified_columns_in_filter_and_select() {
+        let transpiler = Transpiler::new(Box::new(PostgreSqlDialect::new()));
+        let sql = transpiler
+            .transpile("orders %>% filter(orders.price > 100) %>% select(orders.id, orders.price)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"ORDERS\".\"ID\", \"ORDERS\".\"PRICE\" FROM \"ORDERS\" \
+             WHERE (\"ORDERS\".\"PRICE\" > 100)"
+        );
+
+        // 조인한 테이블도 한정자로 쓸 수 있다
+        let sql = transpile_for(
+            Box::new(MySqlDialect::new()),
+            r#"orders %>% left_join(users, by = "uid") %>% select(users.name, orders.id)"#,
+        )
+        .unwrap();
+        assert!(
+            sql.contains("SELECT `users`.`name`, `orders`.`id`"),
+            "{sql}"
+        );
+    }
+}
+
+// ===== Nested Pipeline Source Tests =====
+
+mod nested_source_tests {
+    use super::*;
+    use crate::Transpiler;
+
+    #[test]
+    fn test_filtered_subquery_feeds_summarise() {
+        let transpiler = Transpiler::new(Box::new(PostgreSqlDialect::new()));
+        let sql = transpiler
+            .transpile("(raw %>% filter(x > 0)) %>% summarise(n = n(), total = sum(x))")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT COUNT(*) AS \"N\", SUM(\"X\") AS \"TOTAL\" \
+             FROM (SELECT * FROM \"RAW\" WHERE (\"X\" > 0)) AS \"RAW\""
+        );
+
+        let sql = transpiler
+            .transpile(
+                "(raw %>% filter(x > 0) %>% select(g, x)) %>% group_by(g) %>% summarise(s = sum(x))",
+            )
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"G\", SUM(\"X\") AS \"S\" \
+             FROM (SELECT \"G\", \"X\" FROM \"RAW\" WHERE (\"X\" > 0)) AS \"RAW\" \
+             GROUP BY \"G\""
+        );
+    }
+}
+
+// ===== pull() Tests =====
+
+mod pull_tests {
+    use super::*;
+    use crate::Transpiler;
+
+    #[test]
+    fn test_named_pull_projects_name_and_value() {
+        let transpiler = Transpiler::new(Box::new(PostgreSqlDialect::new()));
+        let sql = transpiler
+            .transpile("t %>% filter(x > 0) %>% pull(value_col, name = key_col)")
+            .unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT \"KEY_COL\", \"VALUE_COL\" FROM \"T\" WHERE (\"X\" > 0)"
+        );
+    }
+}
+
+// ===== n_distinct() Tests =====
+
+mod n_distinct_tests {
+    use super::*;
+
+    #[test]
+    fn test_two_column_n_distinct_counts_combinations() {
+        let code = "t %>% summarise(k = n_distinct(a, b), u = n_distinct(a))";
+
+        let sql = transpile_for(Box::new(DuckDbDialect::new()), code).unwrap();
+        assert_eq!(
+            normalize_sql(&sql),
+            "SELECT COUNT(DISTINCT (\"A\", \"B\")) AS \"K\", COUNT(DISTINCT \"A\") AS \"U\" FROM \"T\""
+        );
+
+        // MySQL은 COUNT(DISTINCT a, b)로 여러 열을 직접 받음
+        let sql = transpile_for(Box::new(MySqlDialect::new()), code).unwrap();
+        assert!(sql.contains("COUNT(DISTINCT `a`, `b`) AS `k`"), "{sql}");
+
+        // 행 값이 없는 방언은 구분자로 이어 붙인 키를 셈
+        let sql = transpile_for(Box::new(SqliteDialect::new()), code).unwrap();
+        assert!(
+            sql.contains("COUNT(DISTINCT (\"a\" || '|' || \"b\")) AS \"k\""),
+            "{sql}"
+        );
+    }
+}
+
+// ===== Column Comment Tests =====
+
+mod column_comment_tests {
+    use super::*;
+    use crate::{Schema, TranspileOptions, Transpiler};
+
+    fn transpiler(emit_column_comments: bool) -> Transpiler {
+        let schema = Schema::new()
+            .with_table("orders", ["id", "customer", "amount"])
+            .with_column_description("orders", "customer", "Customer name")
+            .with_column_description("orders", "amount", "Order total\nin USD");
+        Transpiler::with_options(
+            Box::new(PostgreSqlDialect::new()),
+            TranspileOptions {
+                schema: Some(schema),
+                emit_column_comments,
+                ..Default::default()
+            },
+        )
+    }
+
+    #[test]
+    fn test_described_columns_get_trailing_comments() {
+        let sql = transpiler(true)
+            .transpile("orders %>% select(id, customer, total = amount) %>% filter(amount > 0)")
+            .unwrap();
+        // 설명은 한 줄로, 이름이 바뀐 컬럼도 원래 컬럼의 설명을 사용
+        assert_eq!(
+            sql,
+            "SELECT \"id\",\n  \"customer\", -- Customer name\n  \"amount\" AS \"total\" -- Order total in USD\n\
+             FROM \"orders\"\nWHERE (\"amount\" > 0)"
+        );
+
+        // 옵션이 꺼져 있으면 기존 형태 그대로
+        let sql = transpiler(false)
+            .transpile("orders %>% select(id, customer)")
+            .unwrap();
+        assert_eq!(sql, "SELECT \"id\", \"customer\"\nFROM \"orders\"");
+    }
 }
@@ -0,0 +1,53 @@
+// Inline data sources (tribble()).
+
+use super::assemble::QueryParts;
+use super::{GenerationError, GenerationResult, LiteralValue, SqlGenerator};
+
+impl SqlGenerator {
+    /// Processes `tribble(~a, ~b, ...)` as the source of the pipeline.
+    ///
+    /// The rows become `SELECT v1 AS "a", v2 AS "b" UNION ALL SELECT ...`,
+    /// which every dialect accepts (unlike `VALUES` with a column alias list)
+    /// and which later operations read as a derived table.
+    pub(super) fn process_tribble_operation(
+        &self,
+        columns: &[String],
+        rows: &[Vec<LiteralValue>],
+        query_parts: &mut QueryParts,
+    ) -> GenerationResult<()> {
+        if !query_parts.is_bare_table() {
+            return Err(GenerationError::InvalidAst {
+                reason: "tribble() must be the source of the pipeline".to_string(),
+            });
+        }
+        if rows.is_empty() || rows.iter().any(|row| row.len() != columns.len()) {
+            return Err(GenerationError::InvalidAst {
+                reason: format!(
+                    "tribble() rows must have one value for each of its {} columns",
+                    columns.len()
+                ),
+            });
+        }
+
+        let mut selects = Vec::with_capacity(rows.len());
+        for (index, row) in rows.iter().enumerate() {
+            let values = row
+                .iter()
+                .zip(columns)
+                .map(|(value, column)| {
+                    let sql = self.generate_literal(value)?;
+                    // Only the first branch names the columns.
+                    Ok(if index == 0 {
+                        self.column_alias(&sql, column)
+                    } else {
+                        sql
+                    })
+                })
+                .collect::<GenerationResult<Vec<_>>>()?;
+            selects.push(format!("SELECT {}", values.join(", ")));
+        }
+
+        *query_parts = QueryParts::from_subquery(selects.join("\nUNION ALL\n"));
+        Ok(())
+    }
+}